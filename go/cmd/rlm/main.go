@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/jbeck018/recursive-llm-ts/go/rlm"
 )
@@ -21,6 +24,11 @@ type structuredRequest struct {
 	Schema            *rlm.JSONSchema `json:"schema"`
 	ParallelExecution bool            `json:"parallelExecution"`
 	MaxRetries        int             `json:"maxRetries"`
+	// SchemaFromExampleJSON is a fallback for callers that don't want to
+	// hand-build a Schema: when Schema is nil, it's fed to
+	// rlm.InferSchemaFromJSON to derive one from a representative example
+	// payload instead.
+	SchemaFromExampleJSON json.RawMessage `json:"schemaFromExampleJson,omitempty"`
 }
 
 type responsePayload struct {
@@ -31,6 +39,11 @@ type responsePayload struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "schema-from-openapi" {
+		runSchemaFromOpenAPI(os.Args[2:])
+		return
+	}
+
 	input, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Failed to read stdin:", err)
@@ -52,17 +65,41 @@ func main() {
 	engine := rlm.New(req.Model, config)
 	defer engine.Shutdown()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if config.TimeoutMs > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+	defer signal.Stop(sigCh)
+
 	var resp responsePayload
 
 	// Handle structured completion if requested
 	if req.Structured != nil {
+		schema := req.Structured.Schema
+		if schema == nil && len(req.Structured.SchemaFromExampleJSON) > 0 {
+			schema, err = rlm.InferSchemaFromJSON(req.Structured.SchemaFromExampleJSON)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to infer schema from example JSON:", err)
+				os.Exit(1)
+			}
+		}
+
 		structuredConfig := &rlm.StructuredConfig{
-			Schema:            req.Structured.Schema,
+			Schema:            schema,
 			ParallelExecution: req.Structured.ParallelExecution,
 			MaxRetries:        req.Structured.MaxRetries,
 		}
 
-		result, stats, err := engine.StructuredCompletion(req.Query, req.Context, structuredConfig)
+		result, stats, err := engine.StructuredCompletionContext(ctx, req.Query, req.Context, structuredConfig)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
@@ -75,7 +112,7 @@ func main() {
 		}
 	} else {
 		// Regular completion
-		result, stats, err := engine.Completion(req.Query, req.Context)
+		result, stats, err := engine.CompletionContext(ctx, req.Query, req.Context)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
@@ -104,3 +141,35 @@ func main() {
 
 	fmt.Println(string(payload))
 }
+
+// runSchemaFromOpenAPI implements the "schema-from-openapi <spec.json>
+// <componentName>" subcommand: it loads a JSON-encoded OpenAPI 3 document and
+// prints the JSONSchema lowered from the named component, so a caller can
+// pipe it straight into a StructuredConfig.Schema field instead of
+// hand-authoring one.
+func runSchemaFromOpenAPI(args []string) {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: rlm schema-from-openapi <spec.json> <componentName>")
+		os.Exit(1)
+	}
+
+	spec, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read OpenAPI spec:", err)
+		os.Exit(1)
+	}
+
+	schema, err := rlm.SchemaFromOpenAPI(spec, args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	payload, err := json.Marshal(schema)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to encode schema JSON:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(string(payload))
+}
@@ -0,0 +1,274 @@
+// Package grammar converts a JSON Schema into a GBNF grammar, the format
+// used by llama.cpp and LocalAI to constrain token sampling so that a model
+// can only emit output matching a schema.
+//
+// It intentionally works against a generic JSON-decoded representation
+// rather than importing the rlm package's JSONSchema type, so that rlm can
+// depend on grammar (to build the "grammar" extra param for a ChatRequest)
+// without creating an import cycle.
+package grammar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schema is a minimal JSON Schema decoding target covering the keywords the
+// grammar builder understands.
+type schema struct {
+	Type       string             `json:"type"`
+	Properties map[string]*schema `json:"properties,omitempty"`
+	Items      *schema            `json:"items,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	MinItems   *int               `json:"minItems,omitempty"`
+	MaxItems   *int               `json:"maxItems,omitempty"`
+	Pattern    string             `json:"pattern,omitempty"`
+	OneOf      []*schema          `json:"oneOf,omitempty"`
+}
+
+// builtins are the shared low-level rules every generated grammar depends on.
+const builtins = `ws ::= ([ \t\n] ws)?
+string ::= "\"" (
+    [^"\\\x7F\x00-\x1F] |
+    "\\" (["\\bfnrt] | "u" [0-9a-fA-F]{4})
+  )* "\""
+number ::= "-"? ("0" | [1-9] [0-9]*) ("." [0-9]+)? ([eE] [+-]? [0-9]+)?
+boolean ::= "true" | "false"
+null ::= "null"
+`
+
+// builder accumulates named GBNF rules while walking a schema tree. Schemas
+// are memoized by pointer identity (seen), not by value, so that a tree with
+// shared or self-referential *schema nodes terminates instead of recursing
+// forever.
+type builder struct {
+	rules   map[string]string
+	seen    map[*schema]string
+	counter int
+}
+
+// FromJSON converts a JSON-encoded JSON Schema document into a GBNF grammar
+// string suitable for the "grammar" field understood by llama.cpp-compatible
+// completion backends.
+func FromJSON(schemaJSON []byte) (string, error) {
+	var s schema
+	if err := json.Unmarshal(schemaJSON, &s); err != nil {
+		return "", fmt.Errorf("grammar: invalid schema JSON: %w", err)
+	}
+
+	b := &builder{rules: map[string]string{}, seen: map[*schema]string{}}
+	rootRule := b.rule(&s, "root")
+	return b.render(rootRule), nil
+}
+
+// rule returns the name of the GBNF rule for s, generating it (and any rules
+// it depends on) on first visit and reusing the cached name on subsequent
+// visits to the same *schema node.
+func (b *builder) rule(s *schema, preferredName string) string {
+	if name, ok := b.seen[s]; ok {
+		return name
+	}
+
+	name := b.uniqueName(preferredName)
+	b.seen[s] = name
+	b.rules[name] = name // placeholder so a self-reference resolves to this rule, not infinite recursion
+
+	b.rules[name] = b.production(s, name)
+	return name
+}
+
+func (b *builder) uniqueName(preferred string) string {
+	if _, exists := b.rules[preferred]; !exists {
+		return preferred
+	}
+	b.counter++
+	return fmt.Sprintf("%s_%d", preferred, b.counter)
+}
+
+func (b *builder) production(s *schema, ruleName string) string {
+	if s == nil {
+		return "object | array | string | number | boolean | null"
+	}
+
+	if len(s.Enum) > 0 {
+		return enumProduction(s.Enum, s.Nullable)
+	}
+
+	if len(s.OneOf) > 0 {
+		return b.oneOfProduction(s, ruleName)
+	}
+
+	var body string
+	switch s.Type {
+	case "object":
+		body = b.objectProduction(s, ruleName)
+	case "array":
+		body = b.arrayProduction(s, ruleName)
+	case "string":
+		body = b.stringProduction(s)
+	case "number", "integer":
+		body = "number"
+	case "boolean":
+		body = "boolean"
+	case "null":
+		body = "null"
+	default:
+		body = "object | array | string | number | boolean | null"
+	}
+
+	if s.Nullable && s.Type != "" {
+		body = body + " | null"
+	}
+	return body
+}
+
+func (b *builder) objectProduction(s *schema, ruleName string) string {
+	if len(s.Properties) == 0 {
+		return `"{" ws "}"`
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		fieldSchema := s.Properties[name]
+		fieldRule := b.rule(fieldSchema, ruleName+"_"+sanitize(name))
+		pair := fmt.Sprintf("%q ws \":\" ws %s", name, fieldRule)
+		if !required[name] {
+			pair = "(" + pair + ")?"
+		}
+		pairs = append(pairs, pair)
+	}
+
+	return `"{" ws ` + strings.Join(pairs, ` ws "," ws `) + ` ws "}"`
+}
+
+// arrayProduction builds the "[" ... "]" rule for an array schema. When
+// MinItems/MaxItems are set it uses GBNF's bounded-repetition operator
+// (the same "{m,n}" syntax the builtins use for \uXXXX escapes) on a
+// "separator + item" subrule, rather than the unbounded "*" this package
+// emits for arrays with no declared bounds.
+func (b *builder) arrayProduction(s *schema, ruleName string) string {
+	itemRule := b.rule(s.Items, ruleName+"_item")
+
+	min := 0
+	if s.MinItems != nil && *s.MinItems > 0 {
+		min = *s.MinItems
+	}
+	if min == 0 && s.MaxItems == nil {
+		return fmt.Sprintf(`"[" ws (%s (ws "," ws %s)*)? ws "]"`, itemRule, itemRule)
+	}
+
+	sep := fmt.Sprintf(`(ws "," ws %s)`, itemRule)
+	repMin := 0
+	if min > 0 {
+		repMin = min - 1
+	}
+
+	var rest string
+	if s.MaxItems != nil {
+		repMax := *s.MaxItems - 1
+		if repMax < repMin {
+			repMax = repMin
+		}
+		rest = fmt.Sprintf("%s{%d,%d}", sep, repMin, repMax)
+	} else {
+		rest = fmt.Sprintf("%s{%d,}", sep, repMin)
+	}
+
+	if min == 0 {
+		return fmt.Sprintf(`"[" ws (%s %s)? ws "]"`, itemRule, rest)
+	}
+	return fmt.Sprintf(`"[" ws %s %s ws "]"`, itemRule, rest)
+}
+
+// stringProduction returns the GBNF body for a string schema, compiling
+// Pattern to a GBNF regex-alternative when present. A pattern that the
+// regex-to-GBNF translator can't express degrades to the unconstrained
+// "string" rule rather than failing the whole grammar.
+func (b *builder) stringProduction(s *schema) string {
+	if s.Pattern == "" {
+		return "string"
+	}
+	compiled, err := regexToGBNF(s.Pattern)
+	if err != nil {
+		return "string"
+	}
+	return `"\"" ` + compiled + ` "\""`
+}
+
+// oneOfProduction builds a top-level alternation between each oneOf branch's
+// own rule. Unlike anyOf (not modeled here, since the grammar builder only
+// needs to constrain sampling, not disambiguate after the fact), GBNF
+// alternation doesn't distinguish "exactly one" from "at least one" - that
+// distinction is enforced by parseAndValidateJSON after generation.
+func (b *builder) oneOfProduction(s *schema, ruleName string) string {
+	alts := make([]string, 0, len(s.OneOf))
+	for i, branch := range s.OneOf {
+		alts = append(alts, b.rule(branch, fmt.Sprintf("%s_oneof_%d", ruleName, i)))
+	}
+	if s.Nullable {
+		alts = append(alts, "null")
+	}
+	return strings.Join(alts, " | ")
+}
+
+func enumProduction(values []string, nullable bool) string {
+	alts := make([]string, 0, len(values)+1)
+	for _, v := range values {
+		encoded, _ := json.Marshal(v)
+		alts = append(alts, string(encoded))
+	}
+	if nullable {
+		alts = append(alts, `"null"`)
+	}
+	return strings.Join(alts, " | ")
+}
+
+func sanitize(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// render emits the full grammar text: the root rule first, then every other
+// generated rule, then the shared builtins.
+func (b *builder) render(rootRule string) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "root ::= %s\n", b.rules[rootRule])
+
+	names := make([]string, 0, len(b.rules))
+	for name := range b.rules {
+		if name == rootRule {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&out, "%s ::= %s\n", name, b.rules[name])
+	}
+
+	out.WriteString(builtins)
+	return out.String()
+}
@@ -0,0 +1,271 @@
+package grammar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// regexToGBNF compiles a (restricted) regular expression into a GBNF
+// expression matching the same strings, for JSONSchema.Pattern support.
+// GBNF's own syntax is already regex-flavored (character classes, "*"/"+"/
+// "?" and "{m,n}" repetition, grouping and "|" alternation all mean what
+// they do in a regex), so the translation is mostly re-quoting literal runs
+// as GBNF string tokens and passing character classes through close to
+// verbatim. Anchors ("^", "$") are accepted and ignored, since a GBNF rule
+// already matches the whole token it's substituted into.
+//
+// Unsupported constructs (backreferences, lookaround, named groups) return
+// an error; callers should degrade to an unconstrained "string" rule rather
+// than fail the whole grammar over one field's pattern.
+func regexToGBNF(pattern string) (string, error) {
+	p := &regexParser{input: []rune(pattern)}
+	expr, err := p.parseAlternation()
+	if err != nil {
+		return "", err
+	}
+	if p.pos != len(p.input) {
+		return "", fmt.Errorf("grammar: unexpected %q at position %d in pattern %q", p.input[p.pos], p.pos, pattern)
+	}
+	return expr, nil
+}
+
+type regexParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *regexParser) peek() (rune, bool) {
+	if p.pos >= len(p.input) {
+		return 0, false
+	}
+	return p.input[p.pos], true
+}
+
+func (p *regexParser) next() (rune, bool) {
+	r, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return r, ok
+}
+
+// parseAlternation parses a "|"-separated list of sequences.
+func (p *regexParser) parseAlternation() (string, error) {
+	parts := []string{}
+	for {
+		seq, err := p.parseSequence()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, seq)
+
+		r, ok := p.peek()
+		if !ok || r != '|' {
+			break
+		}
+		p.pos++ // consume "|"
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, " | ") + ")", nil
+}
+
+// parseSequence parses a run of quantified atoms until "|", ")", or input end.
+func (p *regexParser) parseSequence() (string, error) {
+	var tokens []string
+	for {
+		r, ok := p.peek()
+		if !ok || r == '|' || r == ')' {
+			break
+		}
+		tok, err := p.parseQuantified()
+		if err != nil {
+			return "", err
+		}
+		if tok != "" {
+			tokens = append(tokens, tok)
+		}
+	}
+	if len(tokens) == 0 {
+		return `""`, nil
+	}
+	return strings.Join(tokens, " "), nil
+}
+
+// parseQuantified parses one atom followed by an optional "*", "+", "?" or
+// "{m,n}" repetition operator.
+func (p *regexParser) parseQuantified() (string, error) {
+	atom, err := p.parseAtom()
+	if err != nil {
+		return "", err
+	}
+
+	r, ok := p.peek()
+	if !ok {
+		return atom, nil
+	}
+
+	switch r {
+	case '*', '+', '?':
+		p.pos++
+		return wrapRepeat(atom) + string(r), nil
+	case '{':
+		spec, err := p.parseRepeatSpec()
+		if err != nil {
+			return "", err
+		}
+		return wrapRepeat(atom) + spec, nil
+	default:
+		return atom, nil
+	}
+}
+
+// wrapRepeat parenthesizes atom before a repetition operator is appended,
+// unless it's already a single GBNF token (a quoted literal, a bracketed
+// class, or an already-parenthesized group) that doesn't need grouping.
+// parseAtom only ever returns atoms in one of these forms, so this never
+// sees a multi-token sequence that would need wrapping for a different
+// reason.
+func wrapRepeat(atom string) string {
+	if strings.HasPrefix(atom, `"`) && strings.HasSuffix(atom, `"`) && strings.Count(atom, `"`) == 2 {
+		return atom
+	}
+	if strings.HasPrefix(atom, "[") && strings.HasSuffix(atom, "]") {
+		return atom
+	}
+	if strings.HasPrefix(atom, "(") && strings.HasSuffix(atom, ")") {
+		return atom
+	}
+	return "(" + atom + ")"
+}
+
+// parseRepeatSpec parses a "{m}", "{m,}" or "{m,n}" repetition bound,
+// including the braces, and returns it verbatim as GBNF understands the
+// same syntax (the builtins' "\uXXXX" rule already relies on it).
+func (p *regexParser) parseRepeatSpec() (string, error) {
+	start := p.pos
+	p.pos++ // consume "{"
+	var b strings.Builder
+	b.WriteByte('{')
+	for {
+		r, ok := p.next()
+		if !ok {
+			return "", fmt.Errorf("grammar: unterminated repetition at position %d", start)
+		}
+		b.WriteRune(r)
+		if r == '}' {
+			break
+		}
+		if !(r >= '0' && r <= '9') && r != ',' {
+			return "", fmt.Errorf("grammar: invalid repetition spec at position %d", start)
+		}
+	}
+	return b.String(), nil
+}
+
+// parseAtom parses a single regex atom: a group, a character class, a
+// shorthand class ("\d", "\w", "\s"), an anchor (consumed and ignored), a
+// dot, or a single literal character.
+func (p *regexParser) parseAtom() (string, error) {
+	r, ok := p.next()
+	if !ok {
+		return "", fmt.Errorf("grammar: unexpected end of pattern")
+	}
+
+	switch r {
+	case '(':
+		if len(p.input) > p.pos+1 && p.input[p.pos] == '?' {
+			return "", fmt.Errorf("grammar: unsupported group syntax at position %d (named groups/lookaround aren't supported)", p.pos-1)
+		}
+		inner, err := p.parseAlternation()
+		if err != nil {
+			return "", err
+		}
+		closing, ok := p.next()
+		if !ok || closing != ')' {
+			return "", fmt.Errorf("grammar: unterminated group")
+		}
+		return "(" + inner + ")", nil
+	case '[':
+		return p.parseCharClass()
+	case '.':
+		return `[^\x0A]`, nil
+	case '^', '$':
+		return "", nil // anchors are no-ops: a GBNF rule already matches the whole token
+	case '\\':
+		esc, ok := p.next()
+		if !ok {
+			return "", fmt.Errorf("grammar: dangling escape at end of pattern")
+		}
+		return shorthandClass(esc)
+	default:
+		return quoteLiteral(r), nil
+	}
+}
+
+// parseCharClass parses a "[...]" character class and re-emits it as a GBNF
+// class, which uses the same "[", "^" negation, ranges, and "]" syntax as
+// regex classes.
+func (p *regexParser) parseCharClass() (string, error) {
+	var b strings.Builder
+	b.WriteByte('[')
+
+	if r, ok := p.peek(); ok && r == '^' {
+		b.WriteByte('^')
+		p.pos++
+	}
+
+	for {
+		r, ok := p.next()
+		if !ok {
+			return "", fmt.Errorf("grammar: unterminated character class")
+		}
+		if r == ']' {
+			b.WriteByte(']')
+			break
+		}
+		if r == '\\' {
+			esc, ok := p.next()
+			if !ok {
+				return "", fmt.Errorf("grammar: dangling escape in character class")
+			}
+			b.WriteByte('\\')
+			b.WriteRune(esc)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+// shorthandClass expands a "\d", "\D", "\w", "\W", "\s", "\S" escape, or
+// passes through an escaped literal (e.g. "\." or "\\") as a quoted token.
+func shorthandClass(esc rune) (string, error) {
+	switch esc {
+	case 'd':
+		return "[0-9]", nil
+	case 'D':
+		return "[^0-9]", nil
+	case 'w':
+		return "[A-Za-z0-9_]", nil
+	case 'W':
+		return "[^A-Za-z0-9_]", nil
+	case 's':
+		return `[ \t\n\r]`, nil
+	case 'S':
+		return `[^ \t\n\r]`, nil
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return "", fmt.Errorf("grammar: backreference \\%c is not supported", esc)
+	default:
+		return quoteLiteral(esc), nil
+	}
+}
+
+// quoteLiteral renders a single rune as a GBNF string token - double-quoted,
+// like grammar.go's own %q usage, not QuoteRune's single-quoted Go rune
+// literal syntax.
+func quoteLiteral(r rune) string {
+	return strconv.Quote(string(r))
+}
@@ -0,0 +1,53 @@
+package grammar
+
+import "testing"
+
+func TestRegexToGBNF(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{"literal", "abc", `"a" "b" "c"`},
+		{"digit shorthand", `\d+`, "[0-9]+"},
+		{"word shorthand star", `\w*`, "[A-Za-z0-9_]*"},
+		{"whitespace shorthand", `\s?`, `[ \t\n\r]?`},
+		{"char class", "[a-z]+", "[a-z]+"},
+		{"negated char class", "[^0-9]", "[^0-9]"},
+		{"alternation", "foo|bar", `("f" "o" "o" | "b" "a" "r")`},
+		{"group with quantifier", "(ab)+", `("a" "b")+`},
+		{"bounded repetition", `\d{3}`, "[0-9]{3}"},
+		{"anchors are no-ops", "^abc$", `"a" "b" "c"`},
+		{"dot", ".", `[^\x0A]`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := regexToGBNF(tc.pattern)
+			if err != nil {
+				t.Fatalf("regexToGBNF(%q) error = %v", tc.pattern, err)
+			}
+			if got != tc.want {
+				t.Errorf("regexToGBNF(%q) = %q, want %q", tc.pattern, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegexToGBNFUnsupported(t *testing.T) {
+	tests := []string{
+		"(?=foo)",  // lookahead
+		"(?:foo)",  // non-capturing group
+		"a{",       // unterminated repetition
+		"[a-z",     // unterminated character class
+		"(foo",     // unterminated group
+	}
+
+	for _, pattern := range tests {
+		t.Run(pattern, func(t *testing.T) {
+			if _, err := regexToGBNF(pattern); err == nil {
+				t.Errorf("regexToGBNF(%q) expected an error", pattern)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package grammar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromJSONObject(t *testing.T) {
+	schemaJSON := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"}
+		},
+		"required": ["name"]
+	}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.HasPrefix(g, "root ::=") {
+		t.Errorf("FromJSON() does not start with a root rule:\n%s", g)
+	}
+	if !strings.Contains(g, `"name"`) {
+		t.Errorf("FromJSON() missing required field literal:\n%s", g)
+	}
+	if !strings.Contains(g, "string ::=") {
+		t.Errorf("FromJSON() missing string builtin:\n%s", g)
+	}
+}
+
+func TestFromJSONEnum(t *testing.T) {
+	schemaJSON := []byte(`{"type": "string", "enum": ["red", "green", "blue"]}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	for _, want := range []string{`"red"`, `"green"`, `"blue"`} {
+		if !strings.Contains(g, want) {
+			t.Errorf("FromJSON() missing enum literal %s:\n%s", want, g)
+		}
+	}
+}
+
+func TestFromJSONArray(t *testing.T) {
+	schemaJSON := []byte(`{"type": "array", "items": {"type": "number"}}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.Contains(g, "number ::=") {
+		t.Errorf("FromJSON() missing number builtin:\n%s", g)
+	}
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	if _, err := FromJSON([]byte(`not json`)); err == nil {
+		t.Error("FromJSON() with invalid JSON should return an error")
+	}
+}
+
+func TestFromJSONArrayMinMaxItems(t *testing.T) {
+	schemaJSON := []byte(`{"type": "array", "items": {"type": "string"}, "minItems": 2, "maxItems": 3}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.Contains(g, "{1,2}") {
+		t.Errorf("FromJSON() expected a {1,2} bounded repetition for 2-3 items:\n%s", g)
+	}
+}
+
+func TestFromJSONArrayMinItemsOnly(t *testing.T) {
+	schemaJSON := []byte(`{"type": "array", "items": {"type": "string"}, "minItems": 1}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.Contains(g, "{0,}") {
+		t.Errorf("FromJSON() expected an unbounded {0,} repetition:\n%s", g)
+	}
+}
+
+func TestFromJSONStringPattern(t *testing.T) {
+	schemaJSON := []byte(`{"type": "string", "pattern": "^[0-9]{3}-[0-9]{4}$"}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.Contains(g, "[0-9]{3}") || !strings.Contains(g, "[0-9]{4}") {
+		t.Errorf("FromJSON() did not compile the pattern into the grammar:\n%s", g)
+	}
+}
+
+func TestFromJSONStringInvalidPatternDegradesToUnconstrained(t *testing.T) {
+	schemaJSON := []byte(`{"type": "string", "pattern": "(?=foo)"}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.HasPrefix(g, "root ::= string\n") {
+		t.Errorf("FromJSON() should fall back to the unconstrained string rule for an unsupported pattern:\n%s", g)
+	}
+}
+
+func TestFromJSONOneOf(t *testing.T) {
+	schemaJSON := []byte(`{
+		"oneOf": [
+			{"type": "string"},
+			{"type": "number"}
+		]
+	}`)
+
+	g, err := FromJSON(schemaJSON)
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+	if !strings.Contains(g, "string") || !strings.Contains(g, "number") {
+		t.Errorf("FromJSON() oneOf grammar missing a branch:\n%s", g)
+	}
+}
@@ -0,0 +1,333 @@
+// Package openapi indexes an OpenAPI document by operation so RLM can be
+// pointed at an existing API contract and get both a structured-output
+// JSONSchema and a grounded system prompt for a specific operation,
+// instead of requiring either to be hand-authored.
+//
+// Schema resolution ($ref, allOf/oneOf/anyOf composition, discriminators)
+// is delegated to rlm.SchemaFromOpenAPI rather than reimplemented here -
+// this package's job is locating the right schema node for an operation's
+// response, not lowering it.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jbeck018/recursive-llm-ts/go/rlm"
+)
+
+// httpMethods are the operation keys a path item may hold, in the order
+// OpenAPI 3 documents them.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is a parsed OpenAPI 3.x document, indexed by operationId. Documents
+// written against Swagger 2.0 are upgraded to this shape by LoadSpec before
+// indexing.
+type Spec struct {
+	raw        map[string]interface{}
+	operations map[string]*operation
+}
+
+// operation holds the fields SchemaForOperation and
+// BuildSystemPromptForOperation need from one spec operation.
+type operation struct {
+	Summary     string
+	Description string
+	Parameters  []parameter
+	// Responses maps an HTTP status code to its raw (un-lowered)
+	// application/json schema node, as found in the spec.
+	Responses map[int]map[string]interface{}
+}
+
+type parameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+}
+
+// LoadSpec reads an OpenAPI document from path and parses it. A document
+// that declares "swagger": "2.0" is upgraded to an OpenAPI 3 shape first via
+// upgradeSwagger2, so callers can point LoadSpec at either generation of
+// spec. Only JSON-encoded documents are supported, matching
+// rlm.SchemaFromOpenAPI.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: failed to read spec %q: %w", path, err)
+	}
+
+	var probe struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("openapi: invalid JSON spec: %w", err)
+	}
+	if strings.HasPrefix(probe.Swagger, "2.") {
+		data, err = upgradeSwagger2(data)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: failed to upgrade Swagger 2.0 spec: %w", err)
+		}
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("openapi: invalid OpenAPI document: %w", err)
+	}
+
+	operations, err := indexOperations(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Spec{raw: raw, operations: operations}, nil
+}
+
+// indexOperations walks every path item's HTTP methods and collects each
+// operation that declares an operationId - the only handle
+// SchemaForOperation/BuildSystemPromptForOperation have to look one up by.
+// Operations without one are skipped rather than erroring, since a spec
+// commonly mixes addressable and non-addressable operations.
+func indexOperations(raw map[string]interface{}) (map[string]*operation, error) {
+	operations := map[string]*operation{}
+
+	paths, _ := raw["paths"].(map[string]interface{})
+	for _, pathItemRaw := range paths {
+		pathItem, ok := pathItemRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, method := range httpMethods {
+			opRaw, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			opID, _ := opRaw["operationId"].(string)
+			if opID == "" {
+				continue
+			}
+			operations[opID] = parseOperation(opRaw)
+		}
+	}
+
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("openapi: no operations with an operationId found in spec")
+	}
+	return operations, nil
+}
+
+func parseOperation(opRaw map[string]interface{}) *operation {
+	op := &operation{
+		Summary:     stringField(opRaw, "summary"),
+		Description: stringField(opRaw, "description"),
+		Responses:   map[int]map[string]interface{}{},
+	}
+
+	if paramsRaw, ok := opRaw["parameters"].([]interface{}); ok {
+		for _, pRaw := range paramsRaw {
+			p, ok := pRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			required, _ := p["required"].(bool)
+			op.Parameters = append(op.Parameters, parameter{
+				Name:        stringField(p, "name"),
+				In:          stringField(p, "in"),
+				Description: stringField(p, "description"),
+				Required:    required,
+			})
+		}
+	}
+
+	responsesRaw, _ := opRaw["responses"].(map[string]interface{})
+	for statusStr, respRaw := range responsesRaw {
+		status, err := strconv.Atoi(statusStr)
+		if err != nil {
+			// "default" and similar non-numeric response keys aren't
+			// addressable by SchemaForOperation's int status parameter.
+			continue
+		}
+		resp, ok := respRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if schema := responseJSONSchema(resp); schema != nil {
+			op.Responses[status] = schema
+		}
+	}
+
+	return op
+}
+
+// responseJSONSchema returns resp's application/json schema node, falling
+// back to the schema of another declared content type if application/json
+// isn't declared. When several other content types carry a schema, which
+// one is returned is not defined (content is a map).
+func responseJSONSchema(resp map[string]interface{}) map[string]interface{} {
+	content, ok := resp["content"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if mediaType, ok := content["application/json"].(map[string]interface{}); ok {
+		if schema, ok := mediaType["schema"].(map[string]interface{}); ok {
+			return schema
+		}
+	}
+	for _, mediaTypeRaw := range content {
+		mediaType, ok := mediaTypeRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if schema, ok := mediaType["schema"].(map[string]interface{}); ok {
+			return schema
+		}
+	}
+	return nil
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// SchemaForOperation returns the JSONSchema for operation opID's status
+// response body, resolved against the spec's components/schemas (including
+// $ref, allOf/oneOf/anyOf composition, and discriminators) via
+// rlm.SchemaFromOpenAPI.
+func (s *Spec) SchemaForOperation(opID string, status int) (*rlm.JSONSchema, error) {
+	op, ok := s.operations[opID]
+	if !ok {
+		return nil, fmt.Errorf("openapi: operation %q not found", opID)
+	}
+	schema, ok := op.Responses[status]
+	if !ok {
+		return nil, fmt.Errorf("openapi: operation %q has no JSON response schema for status %d", opID, status)
+	}
+
+	doc, syntheticName, err := s.withSyntheticComponent(schema)
+	if err != nil {
+		return nil, err
+	}
+	return rlm.SchemaFromOpenAPI(doc, syntheticName)
+}
+
+// withSyntheticComponent re-encodes the spec with schema injected into
+// components/schemas under a name not already in use, so
+// rlm.SchemaFromOpenAPI's $ref/allOf/oneOf resolution - which only looks at
+// components/schemas (and $defs) - can resolve an inline response schema
+// the same way it resolves a named one, without this package reimplementing
+// that resolution.
+func (s *Spec) withSyntheticComponent(schema map[string]interface{}) ([]byte, string, error) {
+	components, _ := s.raw["components"].(map[string]interface{})
+	if components == nil {
+		components = map[string]interface{}{}
+	}
+	schemas, _ := components["schemas"].(map[string]interface{})
+	if schemas == nil {
+		schemas = map[string]interface{}{}
+	}
+
+	name := "__response"
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s%d", name, i)
+		}
+		if _, exists := schemas[candidate]; !exists {
+			name = candidate
+			break
+		}
+	}
+
+	mergedSchemas := make(map[string]interface{}, len(schemas)+1)
+	for k, v := range schemas {
+		mergedSchemas[k] = v
+	}
+	mergedSchemas[name] = schema
+
+	mergedComponents := make(map[string]interface{}, len(components)+1)
+	for k, v := range components {
+		mergedComponents[k] = v
+	}
+	mergedComponents["schemas"] = mergedSchemas
+
+	docCopy := make(map[string]interface{}, len(s.raw)+1)
+	for k, v := range s.raw {
+		docCopy[k] = v
+	}
+	docCopy["components"] = mergedComponents
+
+	out, err := json.Marshal(docCopy)
+	if err != nil {
+		return nil, "", fmt.Errorf("openapi: failed to re-encode spec: %w", err)
+	}
+	return out, name, nil
+}
+
+// BuildSystemPromptForOperation extends rlm.BuildSystemPrompt with opID's
+// summary, parameter descriptions, and (when present) one of its response
+// examples, so a caller can point RLM at an OpenAPI operation and get a
+// grounded instruction prompt without hand-authoring one. An unknown opID
+// degrades to the base prompt plus a note, rather than an error, matching
+// rlm.BuildSystemPrompt's own no-error signature.
+//
+// This function has no document to report a size for - the context a caller
+// feeds alongside this prompt at runtime is assembled elsewhere - so it
+// passes a contextSize of 0 rather than the query's length, which would
+// misreport the base prompt's "Size: N characters" line.
+func BuildSystemPromptForOperation(spec *Spec, opID string, query string) string {
+	base := rlm.BuildSystemPrompt(0, 0, query, false)
+
+	op, ok := spec.operations[opID]
+	if !ok {
+		return fmt.Sprintf("%s\n\nOperation: %s (not found in spec)", base, opID)
+	}
+
+	var b strings.Builder
+	b.WriteString(base)
+	fmt.Fprintf(&b, "\n\nOperation: %s", opID)
+	if op.Summary != "" {
+		fmt.Fprintf(&b, "\nSummary: %s", op.Summary)
+	}
+	if op.Description != "" {
+		fmt.Fprintf(&b, "\nDescription: %s", op.Description)
+	}
+	if len(op.Parameters) > 0 {
+		b.WriteString("\nParameters:\n")
+		for _, p := range op.Parameters {
+			required := ""
+			if p.Required {
+				required = ", required"
+			}
+			fmt.Fprintf(&b, "- %s (%s%s): %s\n", p.Name, p.In, required, p.Description)
+		}
+	}
+	if example := op.firstResponseExample(); example != "" {
+		b.WriteString("\nExample response:\n")
+		b.WriteString(example)
+	}
+
+	return b.String()
+}
+
+// firstResponseExample returns the JSON-encoded "example" value of
+// whichever response schema declares one, or "" if none do. Map iteration
+// order is unspecified, but operations in practice declare at most one
+// example across their responses.
+func (op *operation) firstResponseExample() string {
+	for _, schema := range op.Responses {
+		example, ok := schema["example"]
+		if !ok {
+			continue
+		}
+		encoded, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			continue
+		}
+		return string(encoded)
+	}
+	return ""
+}
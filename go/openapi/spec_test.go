@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSpec(t *testing.T, doc map[string]interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode test spec: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write test spec: %v", err)
+	}
+	return path
+}
+
+func sampleOpenAPI3Doc() map[string]interface{} {
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"paths": map[string]interface{}{
+			"/pets/{id}": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "getPet",
+					"summary":     "Get a pet by ID",
+					"description": "Returns a single pet.",
+					"parameters": []interface{}{
+						map[string]interface{}{"name": "id", "in": "path", "description": "Pet ID", "required": true},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "OK",
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{"$ref": "#/components/schemas/Pet"},
+								},
+							},
+						},
+						"404": map[string]interface{}{"description": "Not found"},
+					},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Pet": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{"type": "string", "example": "Rex"},
+					},
+					"required": []interface{}{"name"},
+				},
+			},
+		},
+	}
+}
+
+func TestLoadSpec_IndexesOperationByID(t *testing.T) {
+	path := writeSpec(t, sampleOpenAPI3Doc())
+
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+	if _, ok := spec.operations["getPet"]; !ok {
+		t.Fatalf("operations = %v, want getPet indexed", spec.operations)
+	}
+}
+
+func TestSchemaForOperation_ResolvesRefResponse(t *testing.T) {
+	path := writeSpec(t, sampleOpenAPI3Doc())
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+
+	schema, err := spec.SchemaForOperation("getPet", 200)
+	if err != nil {
+		t.Fatalf("SchemaForOperation() error = %v", err)
+	}
+	if schema.Type != "object" {
+		t.Errorf("Type = %q, want object", schema.Type)
+	}
+	if schema.Properties["name"] == nil || schema.Properties["name"].Example != "Rex" {
+		t.Errorf("Properties[name] = %+v, want example Rex", schema.Properties["name"])
+	}
+}
+
+func TestSchemaForOperation_UnknownOperation(t *testing.T) {
+	path := writeSpec(t, sampleOpenAPI3Doc())
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+
+	if _, err := spec.SchemaForOperation("missingOp", 200); err == nil {
+		t.Error("expected an error for an unknown operationId")
+	}
+}
+
+func TestSchemaForOperation_UnknownStatus(t *testing.T) {
+	path := writeSpec(t, sampleOpenAPI3Doc())
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+
+	if _, err := spec.SchemaForOperation("getPet", 500); err == nil {
+		t.Error("expected an error for a status with no JSON response schema")
+	}
+}
+
+func TestBuildSystemPromptForOperation_IncludesOperationDetails(t *testing.T) {
+	path := writeSpec(t, sampleOpenAPI3Doc())
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+
+	prompt := BuildSystemPromptForOperation(spec, "getPet", "what is the pet's name?")
+	if !contains(prompt, "getPet") {
+		t.Error("prompt missing operation id")
+	}
+	if !contains(prompt, "Get a pet by ID") {
+		t.Error("prompt missing operation summary")
+	}
+	if !contains(prompt, "Pet ID") {
+		t.Error("prompt missing parameter description")
+	}
+}
+
+func TestBuildSystemPromptForOperation_UnknownOperationDegradesGracefully(t *testing.T) {
+	path := writeSpec(t, sampleOpenAPI3Doc())
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+
+	prompt := BuildSystemPromptForOperation(spec, "missingOp", "query")
+	if !contains(prompt, "not found") {
+		t.Errorf("prompt = %q, want a not-found note for an unknown operation", prompt)
+	}
+}
+
+func TestLoadSpec_UpgradesSwagger2(t *testing.T) {
+	doc := map[string]interface{}{
+		"swagger": "2.0",
+		"host":    "api.example.com",
+		"schemes": []interface{}{"https"},
+		"paths": map[string]interface{}{
+			"/pets": map[string]interface{}{
+				"post": map[string]interface{}{
+					"operationId": "createPet",
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"name":     "body",
+							"in":       "body",
+							"required": true,
+							"schema":   map[string]interface{}{"$ref": "#/definitions/Pet"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "Created",
+							"schema":      map[string]interface{}{"$ref": "#/definitions/Pet"},
+						},
+					},
+				},
+			},
+		},
+		"definitions": map[string]interface{}{
+			"Pet": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}
+	path := writeSpec(t, doc)
+
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("LoadSpec() error = %v", err)
+	}
+
+	schema, err := spec.SchemaForOperation("createPet", 200)
+	if err != nil {
+		t.Fatalf("SchemaForOperation() error = %v", err)
+	}
+	if schema.Type != "object" || schema.Properties["name"] == nil {
+		t.Errorf("schema = %+v, want the Pet definition resolved via #/components/schemas/Pet", schema)
+	}
+}
+
+func contains(haystack, needle string) bool {
+	return len(haystack) >= len(needle) && (func() bool {
+		for i := 0; i+len(needle) <= len(haystack); i++ {
+			if haystack[i:i+len(needle)] == needle {
+				return true
+			}
+		}
+		return false
+	})()
+}
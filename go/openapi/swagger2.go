@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// upgradeSwagger2 converts a Swagger 2.0 document to an OpenAPI 3.0 shape
+// sufficient for indexOperations/SchemaForOperation: "definitions" becomes
+// "components.schemas", each operation's body parameter moves into
+// "requestBody", each response's "schema" moves under
+// "content.application/json.schema", and every "$ref" is rewritten from
+// "#/definitions/X" to "#/components/schemas/X".
+//
+// This is not a complete Swagger 2.0 -> OpenAPI 3 converter - Swagger-only
+// details like "collectionFormat", "consumes"/"produces" beyond
+// application/json, and security scheme translation are not handled - but
+// it covers the shapes this package's operation/schema lookups need.
+func upgradeSwagger2(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	rewriteDefinitionRefs(doc)
+
+	out := map[string]interface{}{
+		"openapi": "3.0.3",
+	}
+	if info, ok := doc["info"]; ok {
+		out["info"] = info
+	}
+	if servers := swagger2Servers(doc); servers != nil {
+		out["servers"] = servers
+	}
+
+	components := map[string]interface{}{}
+	if defs, ok := doc["definitions"].(map[string]interface{}); ok {
+		components["schemas"] = defs
+	}
+	out["components"] = components
+
+	paths := map[string]interface{}{}
+	if rawPaths, ok := doc["paths"].(map[string]interface{}); ok {
+		for p, itemRaw := range rawPaths {
+			item, ok := itemRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			newItem := map[string]interface{}{}
+			for _, method := range httpMethods {
+				opRaw, ok := item[method].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				newItem[method] = upgradeSwagger2Operation(opRaw)
+			}
+			paths[p] = newItem
+		}
+	}
+	out["paths"] = paths
+
+	return json.Marshal(out)
+}
+
+// swagger2Servers builds an OpenAPI 3 "servers" entry from Swagger 2.0's
+// top-level "host"/"basePath"/"schemes", or returns nil if the document
+// declares no host.
+func swagger2Servers(doc map[string]interface{}) []interface{} {
+	host, ok := doc["host"].(string)
+	if !ok || host == "" {
+		return nil
+	}
+
+	scheme := "https"
+	if schemes, ok := doc["schemes"].([]interface{}); ok && len(schemes) > 0 {
+		if first, ok := schemes[0].(string); ok {
+			scheme = first
+		}
+	}
+	basePath, _ := doc["basePath"].(string)
+
+	return []interface{}{
+		map[string]interface{}{"url": fmt.Sprintf("%s://%s%s", scheme, host, basePath)},
+	}
+}
+
+// upgradeSwagger2Operation converts one Swagger 2.0 operation object into
+// its OpenAPI 3 shape: a "body"-located parameter becomes "requestBody",
+// and every response's "schema" moves to
+// "content.application/json.schema".
+func upgradeSwagger2Operation(op map[string]interface{}) map[string]interface{} {
+	newOp := map[string]interface{}{}
+	for _, key := range []string{"operationId", "summary", "description", "tags"} {
+		if v, ok := op[key]; ok {
+			newOp[key] = v
+		}
+	}
+
+	var newParams []interface{}
+	if paramsRaw, ok := op["parameters"].([]interface{}); ok {
+		for _, pRaw := range paramsRaw {
+			p, ok := pRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if p["in"] == "body" {
+				newOp["requestBody"] = map[string]interface{}{
+					"description": p["description"],
+					"required":    p["required"],
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": p["schema"]},
+					},
+				}
+				continue
+			}
+			newParams = append(newParams, p)
+		}
+	}
+	if len(newParams) > 0 {
+		newOp["parameters"] = newParams
+	}
+
+	newResponses := map[string]interface{}{}
+	if responsesRaw, ok := op["responses"].(map[string]interface{}); ok {
+		for status, respRaw := range responsesRaw {
+			resp, ok := respRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			newResp := map[string]interface{}{}
+			if desc, ok := resp["description"]; ok {
+				newResp["description"] = desc
+			}
+			if schema, ok := resp["schema"]; ok {
+				newResp["content"] = map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schema},
+				}
+			}
+			newResponses[status] = newResp
+		}
+	}
+	newOp["responses"] = newResponses
+
+	return newOp
+}
+
+// rewriteDefinitionRefs rewrites every "$ref": "#/definitions/X" found
+// anywhere in node to "#/components/schemas/X", in place, so schemas copied
+// verbatim from Swagger 2.0's "definitions" section resolve correctly once
+// reparented under OpenAPI 3's "components/schemas".
+func rewriteDefinitionRefs(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			v["$ref"] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+		}
+		for _, child := range v {
+			rewriteDefinitionRefs(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			rewriteDefinitionRefs(child)
+		}
+	}
+}
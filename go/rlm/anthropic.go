@@ -0,0 +1,164 @@
+package rlm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropicBackend talks to Anthropic's Messages API, which takes the system
+// prompt as a top-level field rather than a message with role "system".
+type anthropicBackend struct {
+	apiBase string
+	apiKey  string
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Chat retries per request.Retry (see withRetry) around a single request/
+// response round trip, honoring any Retry-After header Anthropic's rate
+// limiter sends back.
+func (b *anthropicBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	return withRetry(ctx, request, func(ctx context.Context) (ChatResponse, time.Duration, error) {
+		return b.chatOnce(ctx, request)
+	})
+}
+
+func (b *anthropicBackend) chatOnce(ctx context.Context, request ChatRequest) (ChatResponse, time.Duration, error) {
+	system, messages := splitSystemPrompt(request.Messages)
+
+	anthropicMessages := make([]anthropicMessage, len(messages))
+	for i, m := range messages {
+		anthropicMessages[i] = anthropicMessage{Role: m.Role, Content: m.Content}
+	}
+
+	maxTokens := 4096
+	if v, ok := request.ExtraParams["max_tokens"]; ok {
+		if n, ok := toInt(v); ok {
+			maxTokens = n
+		}
+	}
+
+	payload := anthropicRequest{
+		Model:     stripProviderPrefix(request.Model),
+		System:    system,
+		Messages:  anthropicMessages,
+		MaxTokens: maxTokens,
+	}
+
+	body, err := mergeExtraParams(payload, request.ExtraParams)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	endpoint := b.endpoint()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", "2023-06-01")
+	if b.apiKey != "" {
+		req.Header.Set("x-api-key", b.apiKey)
+	}
+	injectTraceContext(ctx, req.Header)
+
+	client := defaultHTTPClient
+	if request.Timeout > 0 {
+		client = &http.Client{Timeout: time.Duration(request.Timeout) * time.Second, Transport: defaultHTTPClient.Transport}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		apiErr := NewAPIError(resp.StatusCode, strings.TrimSpace(string(responseBody)))
+		apiErr.RetryAfter = retryAfter
+		return ChatResponse{}, retryAfter, apiErr
+	}
+
+	var parsed anthropicResponse
+	if err := json.Unmarshal(responseBody, &parsed); err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	if parsed.Error != nil && parsed.Error.Message != "" {
+		return ChatResponse{}, 0, errors.New(parsed.Error.Message)
+	}
+
+	if len(parsed.Content) == 0 {
+		return ChatResponse{}, 0, errors.New("no content returned by LLM")
+	}
+
+	content := parsed.Content[0].Text
+	if parsed.Usage != nil {
+		usage := TokenUsage{
+			PromptTokens:     parsed.Usage.InputTokens,
+			CompletionTokens: parsed.Usage.OutputTokens,
+			TotalTokens:      parsed.Usage.InputTokens + parsed.Usage.OutputTokens,
+		}
+		return ChatResponse{Content: content, Usage: usage, FinishReason: parsed.StopReason}, 0, nil
+	}
+
+	return ChatResponse{Content: content, Usage: estimateUsage(request.Messages, content), FinishReason: parsed.StopReason}, 0, nil
+}
+
+// SupportsStructuredOutput implements LLMProvider: Anthropic has no
+// dedicated structured-output field, but forcing a synthetic tool call (see
+// ProviderModeAnthropicToolUse) guarantees the model's output matches the
+// tool's input_schema, same as OpenAI/Gemini's native surfaces. There's no
+// separate bare-JSON-only mode distinct from tool-use.
+func (b *anthropicBackend) SupportsStructuredOutput() StructuredCapability {
+	return StructuredCapability{Schema: true, ProviderMode: ProviderModeAnthropicToolUse}
+}
+
+func (b *anthropicBackend) endpoint() string {
+	base := strings.TrimSpace(b.apiBase)
+	if base == "" {
+		base = "https://api.anthropic.com/v1"
+	}
+	if strings.Contains(base, "/messages") {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/messages"
+}
@@ -0,0 +1,237 @@
+package rlm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// ChatResponse is a provider-agnostic chat completion result.
+type ChatResponse struct {
+	Content string
+	Usage   TokenUsage
+	// ToolCalls holds any provider-native function/tool calls the model
+	// made in place of (or alongside) Content. Only populated by backends
+	// that implement the OpenAI-style tools/tool_choice wire format when
+	// the request set ChatRequest.Tools - see ToolCallCompletion.
+	ToolCalls []ToolCall
+	// FinishReason is the provider's own reason the completion stopped (e.g.
+	// OpenAI's "stop"/"length"/"tool_calls", or a local model runner's
+	// equivalent). Empty when a backend's wire format doesn't surface one.
+	FinishReason string
+}
+
+// TokenUsage reports how many tokens a single chat completion consumed. When
+// a provider's response omits a usage block, Estimated is set to true and
+// the counts are a rough approximation (estimateTokens), not a precise count.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Estimated        bool
+}
+
+// estimateTokens approximates a token count from text length when a
+// provider's response doesn't include a usage block. This is a rough
+// heuristic (~4 chars/token for English text), not a real tokenizer.
+func estimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+// estimateUsage builds a TokenUsage from the request/response text when a
+// provider didn't report one.
+func estimateUsage(messages []Message, completion string) TokenUsage {
+	var promptChars int
+	for _, m := range messages {
+		promptChars += len(m.Content)
+	}
+	prompt := (promptChars + 3) / 4
+	comp := estimateTokens(completion)
+	return TokenUsage{PromptTokens: prompt, CompletionTokens: comp, TotalTokens: prompt + comp, Estimated: true}
+}
+
+// Backend dispatches a ChatRequest to a specific LLM provider. Each
+// implementation owns its own wire format (endpoint shape, auth header,
+// request/response JSON) and its own role-mapping conventions — for example
+// Anthropic expects a separate top-level "system" field rather than a
+// "system" message, and Gemini calls the assistant role "model" instead of
+// "assistant".
+type Backend interface {
+	Chat(ctx context.Context, request ChatRequest) (ChatResponse, error)
+}
+
+// ConstrainedDecoder is an optional capability a Backend can implement to
+// constrain token sampling directly to a schema's shape (e.g. llama.cpp's
+// GBNF grammars), instead of relying on prompt instructions plus
+// parse-validate-retry. structuredGrammarExtra type-asserts a Backend
+// against this interface and, when present, skips the retry loop entirely
+// since a capable backend can't emit output that fails validation.
+type ConstrainedDecoder interface {
+	BuildGrammar(schema *JSONSchema) (string, error)
+}
+
+// Embedder is an optional capability a Backend can implement to return text
+// embeddings directly, the same way ConstrainedDecoder lets a backend expose
+// grammar-constrained decoding beyond Backend.Chat.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// Tokenizer is an optional capability a Backend can implement to report its
+// own token count for a string, for callers that want a precise count
+// instead of estimateTokens's chars/4 heuristic.
+type Tokenizer interface {
+	TokenizeString(ctx context.Context, text string) (int, error)
+}
+
+// knownProviders are the prefixes recognized in a "provider/model" model
+// string, e.g. "anthropic/claude-3-5-sonnet-latest" or "ollama/llama3".
+var knownProviders = map[string]bool{
+	"anthropic": true,
+	"gemini":    true,
+	"ollama":    true,
+	"grpc":      true,
+}
+
+// ProviderFactory builds a Backend for a registered provider name, given the
+// apiBase/apiKey a Config supplied. See RegisterProvider.
+type ProviderFactory func(apiBase, apiKey string) Backend
+
+var (
+	customProvidersMu sync.RWMutex
+	customProviders   = map[string]ProviderFactory{}
+)
+
+// RegisterProvider adds name to both knownProviders (so "name/model" strings
+// route to it) and resolveBackend's dispatch table (so config.Provider ==
+// name does too), without needing a case in this package's switch statement.
+// This lets a caller plug in a backend for a provider recursive-llm-ts
+// doesn't ship support for (e.g. a self-hosted model gateway) the same way
+// they'd register a custom FormatChecker or a ConstrainedDecoder.
+func RegisterProvider(name string, factory ProviderFactory) {
+	customProvidersMu.Lock()
+	defer customProvidersMu.Unlock()
+	knownProviders[name] = true
+	customProviders[name] = factory
+}
+
+// resolveBackend picks the Backend a Config should use: an explicit
+// config.Backend wins, then config.Provider, then a "provider/model" prefix
+// on model, falling back to OpenAI-compatible for anything else.
+func resolveBackend(model string, config Config) Backend {
+	if config.Backend != nil {
+		return config.Backend
+	}
+
+	provider := config.Provider
+	if provider == "" {
+		provider = providerFromModel(model)
+	}
+
+	switch provider {
+	case "anthropic":
+		return &anthropicBackend{apiBase: config.APIBase, apiKey: config.APIKey}
+	case "gemini":
+		return &geminiBackend{apiBase: config.APIBase, apiKey: config.APIKey}
+	case "ollama":
+		return &ollamaBackend{apiBase: config.APIBase}
+	case "grpc":
+		backend, err := NewGRPCBackend(config.APIBase, stripProviderPrefix(model))
+		if err != nil {
+			return failingGRPCBackend{err: err}
+		}
+		return backend
+	default:
+		customProvidersMu.RLock()
+		factory, ok := customProviders[provider]
+		customProvidersMu.RUnlock()
+		if ok {
+			return factory(config.APIBase, config.APIKey)
+		}
+		return openAIBackend{}
+	}
+}
+
+// providerFromModel extracts the provider prefix from a "provider/model"
+// string, returning "" if model has no recognized prefix.
+func providerFromModel(model string) string {
+	prefix, _, ok := strings.Cut(model, "/")
+	if !ok || !knownProviders[prefix] {
+		return ""
+	}
+	return prefix
+}
+
+// mergeExtraParams marshals payload, overlays extra on top, and re-marshals
+// the result - giving a typed request struct (Anthropic's, Gemini's) the
+// same "arbitrary extra fields win" passthrough openAIBackend gets for free
+// from building its payload as a map[string]interface{} directly.
+func mergeExtraParams(payload interface{}, extra map[string]interface{}) ([]byte, error) {
+	base, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(extra) == 0 {
+		return base, nil
+	}
+
+	var merged map[string]interface{}
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// stripProviderPrefix removes a recognized "provider/" prefix from model so
+// the bare model name is what's sent to that provider's API.
+func stripProviderPrefix(model string) string {
+	prefix, rest, ok := strings.Cut(model, "/")
+	if !ok || !knownProviders[prefix] {
+		return model
+	}
+	return rest
+}
+
+// openAIBackend talks to an OpenAI-compatible /chat/completions endpoint. It
+// is the default backend and the one every other provider is compared
+// against in doc comments below.
+type openAIBackend struct{}
+
+func (openAIBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	content, usage, toolCalls, finishReason, err := callOpenAICompatible(ctx, request)
+	if err != nil {
+		return ChatResponse{}, err
+	}
+	return ChatResponse{Content: content, Usage: usage, ToolCalls: toolCalls, FinishReason: finishReason}, nil
+}
+
+// SupportsStructuredOutput implements LLMProvider: OpenAI-compatible APIs
+// offer both response_format.json_object (bare valid JSON) and
+// response_format.json_schema with strict mode (guaranteed schema-conformant
+// output).
+func (openAIBackend) SupportsStructuredOutput() StructuredCapability {
+	return StructuredCapability{JSON: true, Schema: true, ProviderMode: ProviderModeOpenAI}
+}
+
+// splitSystemPrompt pulls the leading "system" message out of messages,
+// returning its content and the remaining messages in order. Providers whose
+// API takes the system prompt out-of-band (Anthropic, Gemini) use this to
+// translate from the shared OpenAI-style Message slice.
+func splitSystemPrompt(messages []Message) (system string, rest []Message) {
+	rest = make([]Message, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" && system == "" {
+			system = m.Content
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return system, rest
+}
@@ -0,0 +1,203 @@
+package rlm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderFromModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"anthropic/claude-3-5-sonnet-latest", "anthropic"},
+		{"gemini/gemini-1.5-pro", "gemini"},
+		{"ollama/llama3", "ollama"},
+		{"gpt-4o", ""},
+		{"not-a-provider/some-model", ""},
+	}
+
+	for _, tt := range tests {
+		if got := providerFromModel(tt.model); got != tt.want {
+			t.Errorf("providerFromModel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestStripProviderPrefix(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"anthropic/claude-3-5-sonnet-latest", "claude-3-5-sonnet-latest"},
+		{"gemini/gemini-1.5-pro", "gemini-1.5-pro"},
+		{"gpt-4o", "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		if got := stripProviderPrefix(tt.model); got != tt.want {
+			t.Errorf("stripProviderPrefix(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestResolveBackend(t *testing.T) {
+	if _, ok := resolveBackend("gpt-4o", Config{}).(openAIBackend); !ok {
+		t.Error("resolveBackend() with no prefix should default to openAIBackend")
+	}
+	if _, ok := resolveBackend("anthropic/claude-3-opus", Config{}).(*anthropicBackend); !ok {
+		t.Error("resolveBackend() with anthropic/ prefix should return *anthropicBackend")
+	}
+	if _, ok := resolveBackend("gemini/gemini-1.5-pro", Config{}).(*geminiBackend); !ok {
+		t.Error("resolveBackend() with gemini/ prefix should return *geminiBackend")
+	}
+	if _, ok := resolveBackend("llama3", Config{Provider: "ollama"}).(*ollamaBackend); !ok {
+		t.Error("resolveBackend() with Provider=ollama should return *ollamaBackend")
+	}
+
+	custom := openAIBackend{}
+	if got := resolveBackend("anthropic/claude-3-opus", Config{Backend: custom}); got != Backend(custom) {
+		t.Error("resolveBackend() should prefer an explicit config.Backend over prefix inference")
+	}
+}
+
+func TestBackends_SupportStructuredOutputCapabilityTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  LLMProvider
+		wantJSON bool
+		wantMode ProviderMode
+	}{
+		{"openAIBackend", openAIBackend{}, true, ProviderModeOpenAI},
+		{"geminiBackend", &geminiBackend{}, true, ProviderModeGemini},
+		{"anthropicBackend", &anthropicBackend{}, false, ProviderModeAnthropicToolUse},
+	}
+
+	for _, tt := range tests {
+		capability := tt.backend.SupportsStructuredOutput()
+		if !capability.Schema {
+			t.Errorf("%s: Schema = false, want true", tt.name)
+		}
+		if capability.JSON != tt.wantJSON {
+			t.Errorf("%s: JSON = %v, want %v", tt.name, capability.JSON, tt.wantJSON)
+		}
+		if capability.ProviderMode != tt.wantMode {
+			t.Errorf("%s: ProviderMode = %q, want %q", tt.name, capability.ProviderMode, tt.wantMode)
+		}
+	}
+}
+
+func TestEstimateUsage(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "12345678"}}
+	usage := estimateUsage(messages, "1234")
+	if !usage.Estimated {
+		t.Error("estimateUsage() should set Estimated = true")
+	}
+	if usage.PromptTokens != 2 || usage.CompletionTokens != 1 {
+		t.Errorf("estimateUsage() = %+v, want PromptTokens=2 CompletionTokens=1", usage)
+	}
+	if usage.TotalTokens != usage.PromptTokens+usage.CompletionTokens {
+		t.Errorf("estimateUsage() TotalTokens = %d, want sum of prompt+completion", usage.TotalTokens)
+	}
+}
+
+type stubCustomBackend struct{ apiBase string }
+
+func (stubCustomBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	return ChatResponse{}, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("stub", func(apiBase, apiKey string) Backend {
+		return stubCustomBackend{apiBase: apiBase}
+	})
+
+	backend := resolveBackend("stub/some-model", Config{APIBase: "http://localhost:9999"})
+	stub, ok := backend.(stubCustomBackend)
+	if !ok {
+		t.Fatalf("resolveBackend() with a registered provider prefix returned %T, want stubCustomBackend", backend)
+	}
+	if stub.apiBase != "http://localhost:9999" {
+		t.Errorf("stubCustomBackend.apiBase = %q, want the config's APIBase", stub.apiBase)
+	}
+
+	backend = resolveBackend("some-model", Config{Provider: "stub"})
+	if _, ok := backend.(stubCustomBackend); !ok {
+		t.Errorf("resolveBackend() with Provider=%q should dispatch to the registered factory", "stub")
+	}
+}
+
+func TestResolveBackend_GRPCProvider(t *testing.T) {
+	backend := resolveBackend("local-model", Config{Provider: "grpc", APIBase: "localhost:0"})
+	if _, ok := backend.(*grpcBackend); !ok {
+		t.Errorf("resolveBackend() with Provider=grpc returned %T, want *grpcBackend", backend)
+	}
+}
+
+func TestJSONCodec_RoundTripsGRPCPredictMessages(t *testing.T) {
+	codec := jsonCodec{}
+	req := grpcPredictRequest{
+		Model:    "local-model",
+		Messages: []grpcChatMessage{{Role: "user", Content: "hi"}},
+		Grammar:  "root ::= \"yes\" | \"no\"",
+	}
+
+	encoded, err := codec.Marshal(&req)
+	if err != nil {
+		t.Fatalf("jsonCodec.Marshal() error = %v", err)
+	}
+
+	var decoded grpcPredictRequest
+	if err := codec.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("jsonCodec.Unmarshal() error = %v", err)
+	}
+	if decoded.Model != req.Model || decoded.Grammar != req.Grammar || len(decoded.Messages) != 1 {
+		t.Errorf("jsonCodec round-trip = %+v, want %+v", decoded, req)
+	}
+	if decoded.Messages[0] != req.Messages[0] {
+		t.Errorf("jsonCodec round-trip messages[0] = %+v, want %+v", decoded.Messages[0], req.Messages[0])
+	}
+}
+
+func TestFailingGRPCBackend_ChatReturnsDeferredError(t *testing.T) {
+	backend := failingGRPCBackend{err: fmt.Errorf("dial failed")}
+	_, err := backend.Chat(context.Background(), ChatRequest{})
+	if err == nil || err.Error() != "dial failed" {
+		t.Errorf("failingGRPCBackend.Chat() error = %v, want %q", err, "dial failed")
+	}
+}
+
+func TestOpenAIBackend_ChatPropagatesFinishReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"length"}]}`))
+	}))
+	defer server.Close()
+
+	response, err := openAIBackend{}.Chat(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+	})
+	if err != nil {
+		t.Fatalf("openAIBackend.Chat() error = %v", err)
+	}
+	if response.FinishReason != "length" {
+		t.Errorf("openAIBackend.Chat() FinishReason = %q, want %q", response.FinishReason, "length")
+	}
+}
+
+func TestSplitSystemPrompt(t *testing.T) {
+	system, rest := splitSystemPrompt([]Message{
+		{Role: "system", Content: "be terse"},
+		{Role: "user", Content: "hi"},
+	})
+	if system != "be terse" {
+		t.Errorf("splitSystemPrompt() system = %q, want %q", system, "be terse")
+	}
+	if len(rest) != 1 || rest[0].Role != "user" {
+		t.Errorf("splitSystemPrompt() rest = %v, want a single user message", rest)
+	}
+}
@@ -0,0 +1,110 @@
+package rlm
+
+import (
+	"strings"
+	"testing"
+)
+
+func paymentOrRefundSchema() *JSONSchema {
+	return &JSONSchema{
+		OneOf: []*JSONSchema{
+			{
+				Type:       "object",
+				Properties: map[string]*JSONSchema{"kind": {Type: "string", Enum: []string{"payment"}}, "amount": {Type: "number"}},
+				Required:   []string{"kind", "amount"},
+			},
+			{
+				Type:       "object",
+				Properties: map[string]*JSONSchema{"kind": {Type: "string", Enum: []string{"refund"}}, "reason": {Type: "string"}},
+				Required:   []string{"kind", "reason"},
+			},
+		},
+	}
+}
+
+func TestParseAndValidateJSON_OneOfPicksMatchingBranch(t *testing.T) {
+	schema := paymentOrRefundSchema()
+
+	parsed, err := parseAndValidateJSON(`{"kind": "refund", "reason": "duplicate charge"}`, schema)
+	if err != nil {
+		t.Fatalf("expected refund branch to validate: %v", err)
+	}
+	if parsed["reason"] != "duplicate charge" {
+		t.Errorf("expected reason to round-trip, got %v", parsed["reason"])
+	}
+}
+
+func TestParseAndValidateJSON_OneOfNoBranchMatches(t *testing.T) {
+	schema := paymentOrRefundSchema()
+
+	_, err := parseAndValidateJSON(`{"kind": "transfer"}`, schema)
+	if err == nil {
+		t.Fatal("expected an error when no oneOf branch matches")
+	}
+	if !strings.Contains(err.Error(), "no oneOf branch matched") {
+		t.Errorf("expected a 'no oneOf branch matched' error, got: %v", err)
+	}
+}
+
+func TestDetectDiscriminator_SharedEnumField(t *testing.T) {
+	schema := paymentOrRefundSchema()
+
+	field, ok := detectDiscriminator(schema.OneOf)
+	if !ok || field != "kind" {
+		t.Errorf("detectDiscriminator() = (%q, %v), want (\"kind\", true)", field, ok)
+	}
+}
+
+func TestDetectDiscriminator_NoSharedTag(t *testing.T) {
+	branches := []*JSONSchema{
+		{Type: "object", Properties: map[string]*JSONSchema{"amount": {Type: "number"}}},
+		{Type: "object", Properties: map[string]*JSONSchema{"reason": {Type: "string"}}},
+	}
+
+	if _, ok := detectDiscriminator(branches); ok {
+		t.Error("expected no discriminator when branches share no tagged property")
+	}
+}
+
+func TestDecomposeSchema_OneOfFieldIsTwoStep(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"event": paymentOrRefundSchema()},
+		Required:   []string{"event"},
+	}
+
+	subTasks := decomposeSchema(schema)
+	if len(subTasks) != 2 {
+		t.Fatalf("expected 2 subtasks (discriminator + full field), got %d", len(subTasks))
+	}
+
+	var discriminatorTask, fieldTask *SubTask
+	for i := range subTasks {
+		if strings.HasSuffix(subTasks[i].ID, "_discriminator") {
+			discriminatorTask = &subTasks[i]
+		} else {
+			fieldTask = &subTasks[i]
+		}
+	}
+
+	if discriminatorTask == nil || fieldTask == nil {
+		t.Fatalf("expected one discriminator task and one field task, got %+v", subTasks)
+	}
+	if len(discriminatorTask.Dependencies) != 0 {
+		t.Errorf("discriminator task should have no dependencies, got %v", discriminatorTask.Dependencies)
+	}
+	if len(fieldTask.Dependencies) != 1 || fieldTask.Dependencies[0] != discriminatorTask.ID {
+		t.Errorf("field task should depend on the discriminator task, got %v", fieldTask.Dependencies)
+	}
+}
+
+func TestBuildOneOfDescription_ListsTagValues(t *testing.T) {
+	description := buildOneOfDescription("event", paymentOrRefundSchema().OneOf)
+
+	if !strings.Contains(description, `kind="payment"`) {
+		t.Errorf("expected description to tag the payment branch, got: %s", description)
+	}
+	if !strings.Contains(description, `kind="refund"`) {
+		t.Errorf("expected description to tag the refund branch, got: %s", description)
+	}
+}
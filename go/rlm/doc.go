@@ -42,6 +42,11 @@
 //	// "summarize this" becomes a detailed, optimized query
 //	answer, stats, err := engine.Completion("summarize this", longDocument)
 //
+// Set MetaAgentConfig.Cache (e.g. rlm.NewMemoryOptimizationCache(1000)) to skip
+// the optimization LLM call for repeated or near-identical queries in batch
+// pipelines; SimilarityThreshold controls how close two contexts must be to
+// count as the same query.
+//
 // For structured queries, the meta-agent references schema fields explicitly:
 //
 //	result, stats, err := engine.StructuredCompletion(
@@ -119,6 +124,64 @@
 //	    config,
 //	)
 //
+// Building that schema by hand gets tedious for real structs, so
+// SchemaFor[T] derives it from a Go type via reflection, honoring `json`
+// tags plus `validate` and `jsonschema` struct tags for constraints.
+// StructuredCompletionInto pairs it with StructuredCompletion and
+// unmarshals the result straight into T:
+//
+//	type Person struct {
+//	    Name string `json:"name"`
+//	    Age  int    `json:"age" validate:"min=0,max=130"`
+//	}
+//
+//	person, stats, err := rlm.StructuredCompletionInto[Person](engine,
+//	    "Extract person info", "John is 30 years old",
+//	    &rlm.StructuredConfig{Schema: rlm.SchemaFor[Person]()},
+//	)
+//
+// # Grammar-Constrained Decoding
+//
+// StructuredCompletion always sends a GBNF grammar derived from the schema
+// (see the go/grammar package) as a "grammar" extra param, for backends that
+// honor it. Set StructuredConfig.GrammarField to send it under a different
+// key, for a backend that expects its grammar constraint elsewhere. A Backend
+// can additionally implement ConstrainedDecoder to build
+// its own grammar from the schema directly - ollamaBackend does this, reusing
+// grammar.FromJSON. When a backend satisfies ConstrainedDecoder, the
+// parse-validate-retry loop collapses to a single call, since a grammar-
+// constrained backend can't emit output that fails schema validation:
+//
+//	type myGrammarBackend struct{ /* ... */ }
+//
+//	func (b *myGrammarBackend) Chat(ctx context.Context, req rlm.ChatRequest) (rlm.ChatResponse, error) {
+//	    // ...
+//	}
+//
+//	func (b *myGrammarBackend) BuildGrammar(schema *rlm.JSONSchema) (string, error) {
+//	    return grammar.FromJSON(schemaJSON)
+//	}
+//
+// # Streaming
+//
+// CompletionStream streams the FINAL() answer to the caller as it's
+// produced, instead of returning it only once the whole response has been
+// parsed:
+//
+//	chunks, err := engine.CompletionStream("What is 2+2?", "")
+//	for chunk := range chunks {
+//	    if chunk.Err != nil {
+//	        log.Fatal(chunk.Err)
+//	    }
+//	    fmt.Print(chunk.Content)
+//	}
+//
+// Non-final iterations (REPL execution, tool calls) aren't streamed, since
+// there's nothing for a caller to act on until they produce a result.
+// Recursive calls made via recursive_llm_stream() in the REPL environment
+// forward their chunks to the same channel, tagged with their recursion
+// Depth, so a UI can show nested sub-calls as they think.
+//
 // # Recursive Calls
 //
 // The LLM can make recursive calls to itself using the recursive_llm() function
@@ -0,0 +1,186 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Action is how a field-level schema violation is enforced, keyed by field
+// name in StructuredConfig.EnforcementActions.
+type Action string
+
+const (
+	// ActionDeny retries the whole completion with feedback scoped to just
+	// the denied fields. It's the default for any field not present in
+	// StructuredConfig.EnforcementActions, preserving the package's
+	// original all-or-nothing retry behavior.
+	ActionDeny Action = "deny"
+	// ActionWarn accepts the value as-is and emits a
+	// "structured.enforcement_warn" observer event instead of retrying.
+	ActionWarn Action = "warn"
+	// ActionDryrun accepts the value as-is and records the violation in
+	// RLMStats.EnforcementViolations, without emitting a live event.
+	ActionDryrun Action = "dryrun"
+	// ActionRepair re-extracts just the violating field via a small
+	// sub-query, using the document's other extracted values as context,
+	// instead of retrying the whole completion.
+	ActionRepair Action = "repair"
+)
+
+// EnforcementViolation records one field-level violation applyEnforcement
+// resolved with a Dryrun or Repair action, for callers auditing which
+// fields degraded instead of failing the whole extraction.
+type EnforcementViolation struct {
+	Field   string `json:"field"`
+	Action  Action `json:"action"`
+	Message string `json:"message"`
+}
+
+// enforcementAction returns config.EnforcementActions[field], defaulting to
+// ActionDeny when the field isn't explicitly configured.
+func enforcementAction(config *StructuredConfig, field string) Action {
+	if action, ok := config.EnforcementActions[field]; ok {
+		return action
+	}
+	return ActionDeny
+}
+
+// topLevelField extracts the first segment of a JSON-pointer
+// ValidationError.Path (e.g. "/address/zip" -> "address"), since
+// EnforcementActions is keyed by top-level field name, not by full pointer
+// path.
+func topLevelField(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return ""
+	}
+	if idx := strings.Index(path, "/"); idx >= 0 {
+		path = path[:idx]
+	}
+	return jsonPointerUnescape(path)
+}
+
+// extractCandidateObject returns the first JSON object parseAndValidateJSON
+// would try against the schema, without validating it. applyEnforcement
+// needs the raw decoded document even when it fails validation, so it can
+// resolve per-field actions against whatever the model actually returned.
+func extractCandidateObject(raw string) (map[string]interface{}, bool) {
+	stripped := stripJSONFences(raw)
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(stripped), &parsed); err == nil {
+		return parsed, true
+	}
+
+	for _, candidate := range extractBalancedJSON(stripped) {
+		var candidateMap map[string]interface{}
+		if err := json.Unmarshal([]byte(candidate), &candidateMap); err == nil {
+			return candidateMap, true
+		}
+	}
+	return nil, false
+}
+
+// applyEnforcement resolves one structured-completion attempt's schema
+// violations per StructuredConfig.EnforcementActions instead of retrying
+// the whole document on any violation: Warn lets the value through after
+// logging an observer event, Dryrun does the same but records the
+// violation in stats, Repair re-extracts just that field with a sub-query,
+// and Deny (the default) is the only action that still forces a retry -
+// scoped to feedback about just the denied fields. done reports whether
+// result is final; when done is false, the caller should retry using
+// retryFeedback.
+func (r *RLM) applyEnforcement(ctx stdcontext.Context, raw string, context string, config *StructuredConfig, stats *RLMStats) (result map[string]interface{}, retryFeedback string, done bool, err error) {
+	candidate, ok := extractCandidateObject(raw)
+	if !ok {
+		err = fmt.Errorf("no JSON object found in response: %s", truncateForError(raw))
+		return nil, buildValidationFeedback(err, config.Schema, raw), false, err
+	}
+
+	validationErr := validatorFor(config).Validate(candidate, config.Schema)
+	violations, isValidationResult := validationErr.(*ValidationResult)
+	if validationErr == nil || !isValidationResult || violations.Valid() {
+		return candidate, "", true, nil
+	}
+
+	var denied []ValidationError
+	for _, e := range violations.Errors {
+		field := topLevelField(e.Path)
+		switch enforcementAction(config, field) {
+		case ActionWarn:
+			r.observer.Event("structured.enforcement_warn", map[string]string{"field": field, "message": e.Message})
+		case ActionDryrun:
+			stats.EnforcementViolations = append(stats.EnforcementViolations, EnforcementViolation{Field: field, Action: ActionDryrun, Message: e.Message})
+		case ActionRepair:
+			repaired, repairErr := r.repairField(ctx, field, context, config, candidate)
+			if repairErr != nil {
+				r.observer.Error("structured", "repair failed for field %s: %v", field, repairErr)
+				denied = append(denied, e)
+				continue
+			}
+			candidate[field] = repaired
+			stats.EnforcementViolations = append(stats.EnforcementViolations, EnforcementViolation{Field: field, Action: ActionRepair, Message: e.Message})
+		default: // ActionDeny
+			denied = append(denied, e)
+		}
+	}
+
+	if len(denied) == 0 {
+		return candidate, "", true, nil
+	}
+
+	deniedResult := &ValidationResult{Errors: denied}
+	return nil, buildValidationFeedback(deniedResult, config.Schema, raw), false, deniedResult
+}
+
+// repairField regenerates a single field via a small sub-query, passing the
+// document's other already-extracted values as context so the repair only
+// has to fix the one field that failed enforcement instead of re-deriving
+// the whole document.
+func (r *RLM) repairField(ctx stdcontext.Context, field string, context string, config *StructuredConfig, candidate map[string]interface{}) (interface{}, error) {
+	fieldSchema, ok := config.Schema.Properties[field]
+	if !ok {
+		return nil, fmt.Errorf("no schema for field %q", field)
+	}
+
+	siblings := make(map[string]interface{}, len(candidate))
+	for k, v := range candidate {
+		if k != field {
+			siblings[k] = v
+		}
+	}
+	siblingsJSON, _ := json.Marshal(siblings)
+
+	query := fmt.Sprintf(
+		"%s\n\nAlready-extracted values for the other fields:\n%s",
+		generateFieldQuery(field, fieldSchema), string(siblingsJSON),
+	)
+
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+
+	repairConfig := &StructuredConfig{
+		Schema:            wrapFieldSchema(field, fieldSchema),
+		ParallelExecution: false,
+		MaxRetries:        maxRetries,
+	}
+
+	result, _, err := r.structuredCompletionDirectContext(ctx, query, context, repairConfig)
+	if err != nil {
+		return nil, err
+	}
+	if val, ok := result[field]; ok {
+		return val, nil
+	}
+	if val, ok := result["__value__"]; ok {
+		return val, nil
+	}
+	for _, v := range result {
+		return v, nil
+	}
+	return nil, fmt.Errorf("repair for field %q returned no value", field)
+}
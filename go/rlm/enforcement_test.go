@@ -0,0 +1,120 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"testing"
+)
+
+func TestTopLevelField(t *testing.T) {
+	cases := map[string]string{
+		"/address/zip": "address",
+		"/name":        "name",
+		"":             "",
+	}
+	for path, want := range cases {
+		if got := topLevelField(path); got != want {
+			t.Errorf("topLevelField(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestEnforcementActionDefaultsToDeny(t *testing.T) {
+	config := &StructuredConfig{EnforcementActions: map[string]Action{"name": ActionWarn}}
+
+	if got := enforcementAction(config, "name"); got != ActionWarn {
+		t.Errorf("enforcementAction(name) = %v, want ActionWarn", got)
+	}
+	if got := enforcementAction(config, "age"); got != ActionDeny {
+		t.Errorf("enforcementAction(age) = %v, want ActionDeny", got)
+	}
+}
+
+func TestExtractCandidateObject_StripsMarkdownFence(t *testing.T) {
+	raw := "```json\n{\"name\": \"Joe\"}\n```"
+	candidate, ok := extractCandidateObject(raw)
+	if !ok {
+		t.Fatal("expected extractCandidateObject to succeed")
+	}
+	if candidate["name"] != "Joe" {
+		t.Errorf("expected name 'Joe', got %v", candidate["name"])
+	}
+}
+
+func TestExtractCandidateObject_NoObject(t *testing.T) {
+	if _, ok := extractCandidateObject("not json at all"); ok {
+		t.Error("expected extractCandidateObject to fail on non-JSON input")
+	}
+}
+
+func enforcementTestEngine() *RLM {
+	return New("gpt-4o-mini", Config{APIKey: "test-key", MaxDepth: 5, MaxIterations: 30})
+}
+
+func TestApplyEnforcement_ValidDocumentIsDone(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+	config := &StructuredConfig{Schema: schema}
+
+	r := enforcementTestEngine()
+	stats := &RLMStats{}
+	result, _, done, err := r.applyEnforcement(stdcontext.Background(), `{"name": "Joe"}`, "", config, stats)
+	if err != nil || !done {
+		t.Fatalf("expected done with no error, got done=%v err=%v", done, err)
+	}
+	if result["name"] != "Joe" {
+		t.Errorf("expected name 'Joe', got %v", result["name"])
+	}
+}
+
+func TestApplyEnforcement_DryrunRecordsViolationAndPassesThrough(t *testing.T) {
+	minimum := 0.0
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"age": {Type: "number", Minimum: &minimum},
+		},
+		Required: []string{"age"},
+	}
+	config := &StructuredConfig{
+		Schema:             schema,
+		EnforcementActions: map[string]Action{"age": ActionDryrun},
+	}
+
+	r := enforcementTestEngine()
+	stats := &RLMStats{}
+	result, _, done, err := r.applyEnforcement(stdcontext.Background(), `{"age": -5}`, "", config, stats)
+	if err != nil || !done {
+		t.Fatalf("expected done with no error, got done=%v err=%v", done, err)
+	}
+	if result["age"] != -5.0 {
+		t.Errorf("expected out-of-range value to pass through unchanged, got %v", result["age"])
+	}
+	if len(stats.EnforcementViolations) != 1 || stats.EnforcementViolations[0].Field != "age" {
+		t.Errorf("expected one recorded violation for 'age', got %+v", stats.EnforcementViolations)
+	}
+}
+
+func TestApplyEnforcement_DeniedFieldStillRetries(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+	config := &StructuredConfig{Schema: schema}
+
+	r := enforcementTestEngine()
+	stats := &RLMStats{}
+	_, feedback, done, err := r.applyEnforcement(stdcontext.Background(), `{}`, "", config, stats)
+	if done || err == nil {
+		t.Fatalf("expected a retry, got done=%v err=%v", done, err)
+	}
+	if feedback == "" {
+		t.Error("expected non-empty retry feedback for a denied field")
+	}
+	if len(stats.EnforcementViolations) != 0 {
+		t.Errorf("expected no recorded violations for a denied field, got %+v", stats.EnforcementViolations)
+	}
+}
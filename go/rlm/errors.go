@@ -1,6 +1,11 @@
 package rlm
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 // RLMError is the base error type for all RLM errors
 type RLMError struct {
@@ -49,6 +54,43 @@ func NewMaxDepthError(maxDepth int) *MaxDepthError {
 	}
 }
 
+// MaxNestedDepthError is returned by the schema validator when a document
+// (or a schema's own branches - allOf/anyOf/oneOf/not, $ref, nested
+// properties/items) nests deeper than MaxNestedValidationDepth, instead of
+// letting a pathological deeply-nested LLM response recurse until the
+// goroutine stack overflows. Distinct from MaxDepthError, which governs the
+// recursive_llm call tree rather than a single document's own nesting.
+type MaxNestedDepthError struct {
+	MaxDepth int
+	*RLMError
+}
+
+func NewMaxNestedDepthError(maxDepth int) *MaxNestedDepthError {
+	return &MaxNestedDepthError{
+		MaxDepth: maxDepth,
+		RLMError: &RLMError{
+			Message: fmt.Sprintf("document nests deeper than the max validation depth (%d)", maxDepth),
+		},
+	}
+}
+
+// MaxTokensError is returned when a Config.MaxTokens budget is exceeded
+type MaxTokensError struct {
+	MaxTokens  int
+	UsedTokens int
+	*RLMError
+}
+
+func NewMaxTokensError(maxTokens int, usedTokens int) *MaxTokensError {
+	return &MaxTokensError{
+		MaxTokens:  maxTokens,
+		UsedTokens: usedTokens,
+		RLMError: &RLMError{
+			Message: fmt.Sprintf("token budget (%d) exceeded (%d used) without FINAL()", maxTokens, usedTokens),
+		},
+	}
+}
+
 // REPLError is returned when REPL execution fails
 type REPLError struct {
 	Code string
@@ -65,10 +107,40 @@ func NewREPLError(message string, code string, cause error) *REPLError {
 	}
 }
 
+// SandboxLimitError is returned when a REPLExecutor constructed with
+// NewSandboxedREPLExecutor has a script interrupted by its SandboxConfig. It
+// is its own type (not a REPLError) so callers can distinguish "the sandbox's
+// own budget fired" from an ordinary script error or a per-call
+// ExecuteOptions limit; the underlying *REPLError is kept as its Cause.
+type SandboxLimitError struct {
+	// Limit identifies which SandboxConfig field was breached: one of
+	// "cpu_timeout", "wall_timeout", "max_loop_iterations", or
+	// "max_alloc_bytes".
+	Limit string
+	*RLMError
+}
+
+// NewSandboxLimitError wraps cause (the underlying *REPLError) as a
+// *SandboxLimitError identifying limit as the SandboxConfig field that fired.
+func NewSandboxLimitError(limit string, cause error) *SandboxLimitError {
+	return &SandboxLimitError{
+		Limit: limit,
+		RLMError: &RLMError{
+			Message: fmt.Sprintf("sandbox limit exceeded: %s", limit),
+			Cause:   cause,
+		},
+	}
+}
+
 // APIError is returned when LLM API calls fail
 type APIError struct {
 	StatusCode int
 	Response   string
+	// RetryAfter is parsed from the response's Retry-After header (seconds
+	// or an HTTP-date), zero if the header was absent or unparseable. The
+	// retry loop in retry.go honors it over its own backoff computation when
+	// set.
+	RetryAfter time.Duration
 	*RLMError
 }
 
@@ -81,3 +153,72 @@ func NewAPIError(statusCode int, response string) *APIError {
 		},
 	}
 }
+
+// IsRetryable reports whether this error's status code is one of the
+// transient conditions a caller would normally retry (429 rate-limited and
+// the common 5xx server errors), independent of any request-specific
+// RetryPolicy.RetryableStatus override.
+func (e *APIError) IsRetryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetriesExhaustedError is returned when CallChatCompletion's retry loop
+// (see RetryPolicy) gives up after its last attempt still failed.
+type RetriesExhaustedError struct {
+	Attempts int
+	Elapsed  time.Duration
+	*RLMError
+}
+
+// NewRetriesExhaustedError wraps the last attempt's error after attempts
+// tries spanning elapsed wall-clock time.
+func NewRetriesExhaustedError(attempts int, elapsed time.Duration, lastErr error) *RetriesExhaustedError {
+	return &RetriesExhaustedError{
+		Attempts: attempts,
+		Elapsed:  elapsed,
+		RLMError: &RLMError{
+			Message: fmt.Sprintf("LLM request failed after %d attempts over %s", attempts, elapsed),
+			Cause:   lastErr,
+		},
+	}
+}
+
+// CancelledError wraps ctx.Err() from a canceled or expired LLM call, so
+// callers can tell a user-initiated cancellation (or deadline) apart from an
+// APIError returned by the provider itself - errors.As(err, &rlm.APIError{})
+// only matches the latter.
+type CancelledError struct {
+	*RLMError
+}
+
+// NewCancelledError wraps cause (ctx.Err(), typically context.Canceled or
+// context.DeadlineExceeded) as a *CancelledError.
+func NewCancelledError(cause error) *CancelledError {
+	return &CancelledError{
+		RLMError: &RLMError{
+			Message: fmt.Sprintf("LLM request canceled: %v", cause),
+			Cause:   cause,
+		},
+	}
+}
+
+// wrapCancelled converts err into a *CancelledError when ctx was canceled or
+// its deadline elapsed, so CallChatCompletionContext callers get a
+// CancelledError instead of the raw *url.Error http.Client surfaces for a
+// canceled request. Any other err (including a nil ctx.Err()) passes through
+// unchanged.
+func wrapCancelled(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return NewCancelledError(ctxErr)
+	}
+	return err
+}
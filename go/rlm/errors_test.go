@@ -0,0 +1,36 @@
+package rlm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWrapCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := wrapCancelled(ctx, errors.New("dial tcp: operation was canceled"))
+
+	var cancelled *CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("wrapCancelled() = %v, want a *CancelledError", err)
+	}
+	if !errors.Is(cancelled.Cause, context.Canceled) {
+		t.Errorf("CancelledError.Cause = %v, want context.Canceled", cancelled.Cause)
+	}
+}
+
+func TestWrapCancelledPassesThroughOtherErrors(t *testing.T) {
+	err := NewAPIError(500, "boom")
+	got := wrapCancelled(context.Background(), err)
+	if got != err {
+		t.Errorf("wrapCancelled() = %v, want the original error unchanged when ctx isn't done", got)
+	}
+}
+
+func TestWrapCancelledNilError(t *testing.T) {
+	if got := wrapCancelled(context.Background(), nil); got != nil {
+		t.Errorf("wrapCancelled(nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,147 @@
+package rlm
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatChecker validates a value against a named "format" keyword (e.g.
+// "email", "uri", "date-time"). It receives the raw decoded JSON value, so a
+// checker for a string format should type-assert and return false for any
+// other type.
+type FormatChecker func(value interface{}) bool
+
+var (
+	formatCheckersMu sync.RWMutex
+	formatCheckers   = map[string]FormatChecker{
+		"email":     checkEmailFormat,
+		"uri":       checkURIFormat,
+		"date":      checkDateFormat,
+		"date-time": checkDateTimeFormat,
+		"uuid":      checkUUIDFormat,
+		"duration":  checkDurationFormat,
+		"ipv4":      checkIPv4Format,
+		"ipv6":      checkIPv6Format,
+		"hostname":  checkHostnameFormat,
+	}
+)
+
+// RegisterFormatChecker registers (or overrides) the FormatChecker used for
+// the given "format" keyword value, so schemas can enforce domain-specific
+// string formats such as "duration", "ports", or "semver" beyond the
+// built-in set.
+func RegisterFormatChecker(format string, checker FormatChecker) {
+	formatCheckersMu.Lock()
+	defer formatCheckersMu.Unlock()
+	formatCheckers[format] = checker
+}
+
+// lookupFormatChecker returns the checker registered for format, if any.
+func lookupFormatChecker(format string) (FormatChecker, bool) {
+	formatCheckersMu.RLock()
+	defer formatCheckersMu.RUnlock()
+	checker, ok := formatCheckers[format]
+	return checker, ok
+}
+
+func checkEmailFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := mail.ParseAddress(s)
+	return err == nil
+}
+
+func checkURIFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	parsed, err := url.Parse(s)
+	return err == nil && parsed.IsAbs()
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func checkUUIDFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	return uuidPattern.MatchString(s)
+}
+
+func checkDateFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+func checkDateTimeFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+
+// durationPattern matches an ISO 8601 duration, e.g. "P3Y6M4DT12H30M5S" or
+// "P2W". It's deliberately loose about which components are present (Go's
+// regexp has no lookahead to require "at least one"), so checkDurationFormat
+// rejects the all-zero-components case ("P", "PT") separately below.
+var durationPattern = regexp.MustCompile(`^P(\d+W|(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?)$`)
+
+func checkIPv4Format(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && strings.Count(s, ":") == 0
+}
+
+func checkIPv6Format(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
+}
+
+// hostnamePattern matches an RFC 1123 hostname: dot-separated labels of
+// letters, digits, and hyphens, each starting and ending with an
+// alphanumeric, none longer than 63 characters.
+var hostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+func checkHostnameFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+	return hostnamePattern.MatchString(s)
+}
+
+func checkDurationFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	if s == "P" || s == "PT" || !durationPattern.MatchString(s) {
+		return false
+	}
+	return true
+}
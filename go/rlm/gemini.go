@@ -0,0 +1,162 @@
+package rlm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// geminiBackend talks to Google's generateContent API, which groups message
+// text into "parts", calls the assistant role "model" instead of
+// "assistant", and takes the system prompt as a separate systemInstruction.
+type geminiBackend struct {
+	apiBase string
+	apiKey  string
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Chat retries per request.Retry (see withRetry) around a single request/
+// response round trip, honoring any Retry-After header Gemini's rate
+// limiter sends back.
+func (b *geminiBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	return withRetry(ctx, request, func(ctx context.Context) (ChatResponse, time.Duration, error) {
+		return b.chatOnce(ctx, request)
+	})
+}
+
+func (b *geminiBackend) chatOnce(ctx context.Context, request ChatRequest) (ChatResponse, time.Duration, error) {
+	system, messages := splitSystemPrompt(request.Messages)
+
+	payload := geminiRequest{Contents: make([]geminiContent, len(messages))}
+	if system != "" {
+		payload.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: system}}}
+	}
+	for i, m := range messages {
+		payload.Contents[i] = geminiContent{Role: geminiRole(m.Role), Parts: []geminiPart{{Text: m.Content}}}
+	}
+
+	body, err := mergeExtraParams(payload, request.ExtraParams)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	endpoint := b.endpoint(request.Model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.apiKey != "" {
+		req.Header.Set("x-goog-api-key", b.apiKey)
+	}
+	injectTraceContext(ctx, req.Header)
+
+	client := defaultHTTPClient
+	if request.Timeout > 0 {
+		client = &http.Client{Timeout: time.Duration(request.Timeout) * time.Second, Transport: defaultHTTPClient.Transport}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		apiErr := NewAPIError(resp.StatusCode, strings.TrimSpace(string(responseBody)))
+		apiErr.RetryAfter = retryAfter
+		return ChatResponse{}, retryAfter, apiErr
+	}
+
+	var parsed geminiResponse
+	if err := json.Unmarshal(responseBody, &parsed); err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	if parsed.Error != nil && parsed.Error.Message != "" {
+		return ChatResponse{}, 0, errors.New(parsed.Error.Message)
+	}
+
+	if len(parsed.Candidates) == 0 || len(parsed.Candidates[0].Content.Parts) == 0 {
+		return ChatResponse{}, 0, errors.New("no candidates returned by LLM")
+	}
+
+	content := parsed.Candidates[0].Content.Parts[0].Text
+	finishReason := parsed.Candidates[0].FinishReason
+	if parsed.UsageMetadata != nil {
+		usage := TokenUsage{
+			PromptTokens:     parsed.UsageMetadata.PromptTokenCount,
+			CompletionTokens: parsed.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      parsed.UsageMetadata.TotalTokenCount,
+		}
+		return ChatResponse{Content: content, Usage: usage, FinishReason: finishReason}, 0, nil
+	}
+
+	return ChatResponse{Content: content, Usage: estimateUsage(request.Messages, content), FinishReason: finishReason}, 0, nil
+}
+
+// SupportsStructuredOutput implements LLMProvider: Gemini offers both a
+// bare "valid JSON" mode (generationConfig.responseMimeType) and a full
+// schema-conformant mode (generationConfig.responseSchema).
+func (b *geminiBackend) SupportsStructuredOutput() StructuredCapability {
+	return StructuredCapability{JSON: true, Schema: true, ProviderMode: ProviderModeGemini}
+}
+
+// geminiRole maps the shared OpenAI-style role vocabulary onto Gemini's,
+// where the assistant turn is called "model".
+func geminiRole(role string) string {
+	if role == "assistant" {
+		return "model"
+	}
+	return role
+}
+
+func (b *geminiBackend) endpoint(model string) string {
+	base := strings.TrimSpace(b.apiBase)
+	if base == "" {
+		base = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	return fmt.Sprintf("%s/models/%s:generateContent", strings.TrimRight(base, "/"), stripProviderPrefix(model))
+}
@@ -0,0 +1,241 @@
+package rlm
+
+// This file implements the GenAI semantic-convention layer on top of
+// Observer's span/event plumbing: attributes and events modeled on the
+// OTel/Langfuse "gen_ai.*" conventions (still incubating upstream as of
+// semconv v1.26.0, hence the hand-rolled constants below instead of a
+// semconv package import), a companion Meter recording token-usage and
+// operation-duration histograms, and a per-model USD cost calculator.
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GenAI semantic-convention attribute keys and event names. These track the
+// OTel GenAI semantic conventions (https://opentelemetry.io/docs/specs/semconv/gen-ai/),
+// which are still marked "Development" upstream and so aren't yet exposed
+// as typed constants in go.opentelemetry.io/otel/semconv.
+const (
+	attrGenAISystem                = "gen_ai.system"
+	attrGenAIRequestModel          = "gen_ai.request.model"
+	attrGenAIRequestTemperature    = "gen_ai.request.temperature"
+	attrGenAIUsagePromptTokens     = "gen_ai.usage.prompt_tokens"
+	attrGenAIUsageCompletionTokens = "gen_ai.usage.completion_tokens"
+	attrGenAIUsageCost             = "gen_ai.usage.cost"
+
+	eventGenAIContentPrompt     = "gen_ai.content.prompt"
+	eventGenAIContentCompletion = "gen_ai.content.completion"
+
+	attrGenAIEventContent = "gen_ai.event.content"
+
+	// genAISystem is the gen_ai.system value reported for every LLM call.
+	// It's a constant rather than a field threaded through ChatRequest, so
+	// calls routed to a non-OpenAI Backend (Anthropic, Gemini, Ollama, gRPC)
+	// are also reported as "openai".
+	genAISystem = "openai"
+)
+
+// LLMCallInfo carries the GenAI semantic-convention details for one LLM
+// call: the attributes layered onto its "llm.call" span and the
+// measurements recorded on the companion Meter. Model is the only field a
+// caller always has; the rest are zero-valued when unavailable (e.g.
+// Temperature when the request didn't set one).
+type LLMCallInfo struct {
+	Model            string
+	MessageCount     int
+	PromptTokens     int
+	CompletionTokens int
+	// Temperature is nil when the request's ExtraParams had no
+	// "temperature" key, so the gen_ai.request.temperature attribute can be
+	// omitted rather than misreported as 0.
+	Temperature *float64
+	// Prompt and Completion are only attached as span events when the
+	// Observer's CaptureContent is enabled, since they may contain PII or
+	// proprietary data a caller doesn't want in their trace backend.
+	Prompt     string
+	Completion string
+}
+
+// genAIAttributes returns info's GenAI span attributes, omitting
+// gen_ai.request.temperature when info.Temperature is nil and
+// gen_ai.usage.cost when priceTable has no entry for info.Model (an
+// unpriced model shouldn't misreport a $0 cost as if it were known).
+func (info LLMCallInfo) genAIAttributes(priceTable map[string]ModelPricing) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String(attrGenAISystem, genAISystem),
+		attribute.String(attrGenAIRequestModel, info.Model),
+		attribute.Int(attrGenAIUsagePromptTokens, info.PromptTokens),
+		attribute.Int(attrGenAIUsageCompletionTokens, info.CompletionTokens),
+	}
+	if info.Temperature != nil {
+		attrs = append(attrs, attribute.Float64(attrGenAIRequestTemperature, *info.Temperature))
+	}
+	if _, ok := priceTable[info.Model]; ok {
+		cost := CalculateCost(priceTable, info.Model, info.PromptTokens, info.CompletionTokens)
+		attrs = append(attrs, attribute.Float64(attrGenAIUsageCost, cost))
+	}
+	return attrs
+}
+
+// recordGenAIEvents attaches gen_ai.content.prompt/completion events to span
+// when capture is true, serializing each side as its own event body. Events
+// are skipped entirely (not even recorded empty) when capture is false, so
+// a disabled CaptureContent leaves no trace of the content at all.
+func recordGenAIEvents(span trace.Span, info LLMCallInfo, capture bool) {
+	if !capture || span == nil {
+		return
+	}
+	if info.Prompt != "" {
+		span.AddEvent(eventGenAIContentPrompt, trace.WithAttributes(
+			attribute.String(attrGenAIEventContent, info.Prompt),
+		))
+	}
+	if info.Completion != "" {
+		span.AddEvent(eventGenAIContentCompletion, trace.WithAttributes(
+			attribute.String(attrGenAIEventContent, info.Completion),
+		))
+	}
+}
+
+// serializeMessages JSON-encodes messages for use as a gen_ai.content.prompt
+// event body, falling back to "" on a marshal error (Message has no field
+// that can fail to marshal today, but a caller shouldn't panic on telemetry).
+func serializeMessages(messages []Message) string {
+	encoded, err := json.Marshal(messages)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// extractTemperature reads a "temperature" key out of an ExtraParams-style
+// map as a *float64, returning nil when the key is absent or isn't a
+// number - ExtraParams values arrive as interface{} from caller-supplied
+// config and, when round-tripped through JSON, float64 is the only numeric
+// type encoding/json produces.
+func extractTemperature(params map[string]interface{}) *float64 {
+	v, ok := params["temperature"]
+	if !ok {
+		return nil
+	}
+	switch t := v.(type) {
+	case float64:
+		return &t
+	case int:
+		f := float64(t)
+		return &f
+	default:
+		return nil
+	}
+}
+
+// ModelPricing is the USD price per 1,000,000 tokens for one model's prompt
+// and completion tokens, used by CalculateCost.
+type ModelPricing struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
+}
+
+// CalculateCost estimates the USD cost of an LLM call from its token usage
+// using table's per-model pricing. A model with no entry in table costs 0
+// rather than erroring - an unpriced model shouldn't lose the rest of its
+// telemetry over a missing price.
+func CalculateCost(table map[string]ModelPricing, model string, promptTokens, completionTokens int) float64 {
+	pricing, ok := table[model]
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*pricing.PromptPerMillion + float64(completionTokens)*pricing.CompletionPerMillion) / 1_000_000
+}
+
+// genAIMeter holds the instruments Observer.LLMCall records against. It's
+// split out from Observer itself so a nil *genAIMeter (MetricsEnabled
+// false, or setup failed) can be checked once in recordGenAIMetrics instead
+// of nil-checking every instrument individually.
+type genAIMeter struct {
+	tokenUsage        metric.Int64Histogram
+	operationDuration metric.Float64Histogram
+}
+
+// buildGenAIMeter creates the GenAI token-usage and operation-duration
+// instruments on meter, the Meter shared across genAIMeter and rlmMeter -
+// see Observer.setupMeters for where meter and its export reader(s) come
+// from. Returns nil if either instrument fails to register, logging via
+// logger rather than erroring so a Meter-level problem doesn't also take
+// down tracing or the rest of the Observer.
+func buildGenAIMeter(meter metric.Meter, logger *log.Logger) *genAIMeter {
+	tokenUsage, err := meter.Int64Histogram(
+		"gen_ai.client.token.usage",
+		metric.WithDescription("Number of tokens used per GenAI client operation"),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create gen_ai.client.token.usage histogram: %v", err)
+		return nil
+	}
+
+	operationDuration, err := meter.Float64Histogram(
+		"gen_ai.client.operation.duration",
+		metric.WithDescription("Duration of GenAI client operations"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create gen_ai.client.operation.duration histogram: %v", err)
+		return nil
+	}
+
+	return &genAIMeter{
+		tokenUsage:        tokenUsage,
+		operationDuration: operationDuration,
+	}
+}
+
+// recordGenAIMetrics records info's token counts (tagged by model and
+// token type, per the gen_ai.client.token.usage convention) and duration
+// onto the companion Meter. A no-op when MetricsEnabled is false or setup
+// failed, so callers never need a nil check.
+func (o *Observer) recordGenAIMetrics(info LLMCallInfo, durationSeconds float64) {
+	if o.genAI == nil {
+		return
+	}
+	ctx := context.Background()
+	modelAttr := attribute.String(attrGenAIRequestModel, info.Model)
+
+	if info.PromptTokens > 0 {
+		o.genAI.tokenUsage.Record(ctx, int64(info.PromptTokens), metric.WithAttributes(
+			modelAttr, attribute.String("gen_ai.token.type", "input"),
+		))
+	}
+	if info.CompletionTokens > 0 {
+		o.genAI.tokenUsage.Record(ctx, int64(info.CompletionTokens), metric.WithAttributes(
+			modelAttr, attribute.String("gen_ai.token.type", "output"),
+		))
+	}
+	o.genAI.operationDuration.Record(ctx, durationSeconds, metric.WithAttributes(modelAttr))
+}
+
+// CollectMetrics returns a snapshot of every measurement recorded on the
+// companion Meter since the last collection - both the GenAI instruments
+// above and the broader rlm.* instruments in meter.go - or nil if
+// MetricsEnabled is false. It exists for tests and debug tooling that want
+// the raw metricdata without standing up a real metrics backend; the
+// ManualReader it reads from is always registered regardless of
+// MetricsExporter, so this keeps working even when a push/pull exporter is
+// also configured - see Observer.setupMeters.
+func (o *Observer) CollectMetrics() (*metricdata.ResourceMetrics, error) {
+	if o.meterReader == nil {
+		return nil, nil
+	}
+	var rm metricdata.ResourceMetrics
+	if err := o.meterReader.Collect(context.Background(), &rm); err != nil {
+		return nil, err
+	}
+	return &rm, nil
+}
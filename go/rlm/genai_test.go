@@ -0,0 +1,147 @@
+package rlm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCalculateCost(t *testing.T) {
+	table := map[string]ModelPricing{
+		"gpt-4o": {PromptPerMillion: 5, CompletionPerMillion: 15},
+	}
+
+	cost := CalculateCost(table, "gpt-4o", 1_000_000, 200_000)
+	want := 5.0 + 3.0
+	if cost != want {
+		t.Errorf("CalculateCost() = %v, want %v", cost, want)
+	}
+}
+
+func TestCalculateCost_UnknownModel(t *testing.T) {
+	cost := CalculateCost(map[string]ModelPricing{}, "mystery-model", 100, 100)
+	if cost != 0 {
+		t.Errorf("CalculateCost() for unknown model = %v, want 0", cost)
+	}
+}
+
+func TestExtractTemperature(t *testing.T) {
+	temp := extractTemperature(map[string]interface{}{"temperature": 0.7})
+	if temp == nil || *temp != 0.7 {
+		t.Errorf("extractTemperature() = %v, want 0.7", temp)
+	}
+
+	if got := extractTemperature(map[string]interface{}{}); got != nil {
+		t.Errorf("extractTemperature() with no key = %v, want nil", got)
+	}
+
+	if got := extractTemperature(map[string]interface{}{"temperature": "hot"}); got != nil {
+		t.Errorf("extractTemperature() with non-numeric value = %v, want nil", got)
+	}
+}
+
+func TestObserverLLMCall_GenAIAttributes(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{TraceEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	ctx := obs.StartTrace(context.Background(), "root", nil)
+	defer obs.EndTrace(ctx)
+
+	temp := 0.5
+	obs.LLMCall(LLMCallInfo{
+		Model:            "gpt-4o-mini",
+		MessageCount:     2,
+		PromptTokens:     10,
+		CompletionTokens: 20,
+		Temperature:      &temp,
+		Prompt:           "hello",
+		Completion:       "hi there",
+	}, time.Second, nil)
+
+	events := obs.GetEvents()
+	if len(events) == 0 {
+		t.Fatal("expected at least one event")
+	}
+	last := events[len(events)-1]
+	if last.Attributes["tokens_used"] != "30" {
+		t.Errorf("tokens_used = %q, want 30", last.Attributes["tokens_used"])
+	}
+}
+
+func TestGenAIAttributes_Cost(t *testing.T) {
+	info := LLMCallInfo{Model: "gpt-4o", PromptTokens: 1_000_000, CompletionTokens: 200_000}
+	priced := map[string]ModelPricing{"gpt-4o": {PromptPerMillion: 5, CompletionPerMillion: 15}}
+
+	attrs := info.genAIAttributes(priced)
+	var sawCost bool
+	for _, a := range attrs {
+		if string(a.Key) == attrGenAIUsageCost {
+			sawCost = true
+			if got, want := a.Value.AsFloat64(), 8.0; got != want {
+				t.Errorf("gen_ai.usage.cost = %v, want %v", got, want)
+			}
+		}
+	}
+	if !sawCost {
+		t.Error("expected gen_ai.usage.cost attribute for a priced model")
+	}
+
+	for _, a := range info.genAIAttributes(nil) {
+		if string(a.Key) == attrGenAIUsageCost {
+			t.Error("expected no gen_ai.usage.cost attribute for an unpriced model")
+		}
+	}
+}
+
+func TestObserverLLMCall_MetricsEnabled(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{MetricsEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	obs.LLMCall(LLMCallInfo{
+		Model:            "gpt-4o-mini",
+		MessageCount:     1,
+		PromptTokens:     10,
+		CompletionTokens: 20,
+	}, 500*time.Millisecond, nil)
+
+	rm, err := obs.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics() error = %v", err)
+	}
+	if rm == nil || len(rm.ScopeMetrics) == 0 {
+		t.Fatal("expected recorded metrics, got none")
+	}
+
+	var sawTokenUsage, sawDuration bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "gen_ai.client.token.usage":
+				sawTokenUsage = true
+			case "gen_ai.client.operation.duration":
+				sawDuration = true
+			}
+		}
+	}
+	if !sawTokenUsage {
+		t.Error("expected gen_ai.client.token.usage to be recorded")
+	}
+	if !sawDuration {
+		t.Error("expected gen_ai.client.operation.duration to be recorded")
+	}
+}
+
+func TestObserverLLMCall_MetricsDisabled(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{})
+	defer obs.Shutdown()
+
+	obs.LLMCall(LLMCallInfo{Model: "gpt-4o-mini", MessageCount: 1}, time.Second, nil)
+
+	rm, err := obs.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics() error = %v", err)
+	}
+	if rm != nil {
+		t.Error("expected nil metrics snapshot when MetricsEnabled is false")
+	}
+}
@@ -0,0 +1,80 @@
+package rlm
+
+import "strings"
+
+// JSONExtractor is extractBalancedJSON's incremental counterpart: instead of
+// scanning an already-buffered string, a caller feeds it bytes as they
+// arrive from a token stream via Write, and it calls OnValue with each
+// top-level JSON value (tracked with the same escape/string-aware brace
+// counting extractBalancedJSON uses) the instant its closing brace arrives.
+// Bytes already scanned are never rescanned, so Write stays cheap even as
+// the underlying buffer grows across a long stream.
+type JSONExtractor struct {
+	// OnValue is called once per balanced top-level JSON value, in the
+	// order their closing braces were seen.
+	OnValue func(value string)
+
+	buf      strings.Builder
+	scanned  int  // index into buf.String() already scanned
+	inString bool
+	escaped  bool
+	depth    int
+	start    int // offset where the current depth-0 value began; -1 if not inside one
+}
+
+// NewJSONExtractor returns a JSONExtractor that calls onValue for each
+// balanced top-level JSON value found across however many Write calls it
+// takes to deliver one.
+func NewJSONExtractor(onValue func(value string)) *JSONExtractor {
+	return &JSONExtractor{OnValue: onValue, start: -1}
+}
+
+// Write implements io.Writer, so a JSONExtractor can sit directly at the end
+// of an SSE token stream (e.g. io.Copy(extractor, stream)) or be fed
+// delta-by-delta from a StreamingBackend.ChatStream callback.
+func (e *JSONExtractor) Write(p []byte) (int, error) {
+	e.buf.Write(p)
+	data := e.buf.String()
+
+	for ; e.scanned < len(data); e.scanned++ {
+		c := data[e.scanned]
+
+		if e.escaped {
+			e.escaped = false
+			continue
+		}
+		if c == '\\' && e.inString {
+			e.escaped = true
+			continue
+		}
+		if c == '"' {
+			e.inString = !e.inString
+			continue
+		}
+		if e.inString {
+			continue
+		}
+
+		switch c {
+		case '{':
+			if e.depth == 0 {
+				e.start = e.scanned
+			}
+			e.depth++
+		case '}':
+			if e.depth == 0 {
+				continue
+			}
+			e.depth--
+			if e.depth == 0 && e.start >= 0 {
+				value := data[e.start : e.scanned+1]
+				e.start = -1
+				if e.OnValue != nil {
+					e.OnValue(value)
+				}
+			}
+		}
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,79 @@
+package rlm
+
+import "testing"
+
+func TestJSONExtractor_EmitsValueAsClosingBraceArrives(t *testing.T) {
+	var seen []string
+	e := NewJSONExtractor(func(value string) {
+		seen = append(seen, value)
+	})
+
+	e.Write([]byte(`{"name": "Ada"`))
+	if len(seen) != 0 {
+		t.Fatalf("seen = %v, want none before the closing brace arrives", seen)
+	}
+
+	e.Write([]byte(`}`))
+	if len(seen) != 1 || seen[0] != `{"name": "Ada"}` {
+		t.Fatalf(`seen = %v, want [{"name": "Ada"}]`, seen)
+	}
+}
+
+func TestJSONExtractor_HandlesNestedObjects(t *testing.T) {
+	var seen []string
+	e := NewJSONExtractor(func(value string) { seen = append(seen, value) })
+
+	input := `{"user": {"name": "Ada", "address": {"city": "NYC"}}}`
+	for i := 0; i < len(input); i++ {
+		e.Write([]byte{input[i]})
+	}
+
+	if len(seen) != 1 || seen[0] != input {
+		t.Fatalf("seen = %v, want a single complete value matching the input", seen)
+	}
+}
+
+func TestJSONExtractor_IgnoresBracesInsideStrings(t *testing.T) {
+	var seen []string
+	e := NewJSONExtractor(func(value string) { seen = append(seen, value) })
+
+	e.Write([]byte(`{"note": "use { and } in prose"}`))
+
+	if len(seen) != 1 {
+		t.Fatalf("seen = %v, want exactly 1 value (braces inside the string must not affect depth)", seen)
+	}
+}
+
+func TestJSONExtractor_HandlesEscapedQuotesInsideStrings(t *testing.T) {
+	var seen []string
+	e := NewJSONExtractor(func(value string) { seen = append(seen, value) })
+
+	e.Write([]byte(`{"note": "she said \"hi\""}`))
+
+	if len(seen) != 1 || seen[0] != `{"note": "she said \"hi\""}` {
+		t.Fatalf(`seen = %v, want the full object including the escaped quotes`, seen)
+	}
+}
+
+func TestJSONExtractor_EmitsMultipleTopLevelValues(t *testing.T) {
+	var seen []string
+	e := NewJSONExtractor(func(value string) { seen = append(seen, value) })
+
+	e.Write([]byte(`{"a": 1} some text {"b": 2}`))
+
+	if len(seen) != 2 || seen[0] != `{"a": 1}` || seen[1] != `{"b": 2}` {
+		t.Fatalf("seen = %v, want two separate top-level values", seen)
+	}
+}
+
+func TestJSONExtractor_DoesNotRescanAlreadyProcessedBytes(t *testing.T) {
+	calls := 0
+	e := NewJSONExtractor(func(value string) { calls++ })
+
+	e.Write([]byte(`{"a": 1}`))
+	e.Write([]byte(`{"b": 2}`))
+
+	if calls != 2 {
+		t.Fatalf("OnValue called %d times across two separate Write calls, want 2", calls)
+	}
+}
@@ -0,0 +1,57 @@
+package rlm
+
+import "sync"
+
+// KeywordHandler validates one custom ("x-"-prefixed) schema keyword against
+// an instance value. path is the JSON pointer to value; raw is the
+// keyword's own value, as found in JSONSchema.VendorExtensions[name]. A
+// handler returns the ValidationErrors it wants attached to the result, or
+// nil for no violation.
+//
+// KeywordHandler only ever sees vendor keywords - the dialect-defined
+// keywords this package already supports (enum, required, discriminator,
+// nullable, example, ...) are first-class JSONSchema fields walkSchema
+// checks directly and are not routed through the registry. Use this to
+// teach the validator about domain-specific keywords, e.g.:
+//
+//	RegisterKeyword("x-llm-retry-hint", func(path string, value, raw interface{}) []ValidationError {
+//		return nil // annotation-only: the recursive loop reads it via
+//		           // schemaAtPath(...).VendorExtensions["x-llm-retry-hint"]
+//		           // to decide whether a failed field is worth re-prompting.
+//	})
+type KeywordHandler func(path string, value interface{}, raw interface{}) []ValidationError
+
+var (
+	keywordHandlersMu sync.RWMutex
+	keywordHandlers   = map[string]KeywordHandler{}
+)
+
+// RegisterKeyword installs handler for a custom schema keyword (by
+// convention, vendor keywords are prefixed "x-"). walkSchema invokes it for
+// every schema whose VendorExtensions carries that key, in addition to its
+// own built-in checks. Registering under the same name again replaces the
+// previous handler.
+func RegisterKeyword(name string, handler KeywordHandler) {
+	keywordHandlersMu.Lock()
+	defer keywordHandlersMu.Unlock()
+	keywordHandlers[name] = handler
+}
+
+// runKeywordHandlers evaluates every registered handler whose keyword is
+// present on schema.VendorExtensions and appends whatever violations it
+// reports to result.
+func runKeywordHandlers(path string, value interface{}, schema *JSONSchema, result *ValidationResult) {
+	if len(schema.VendorExtensions) == 0 {
+		return
+	}
+
+	keywordHandlersMu.RLock()
+	defer keywordHandlersMu.RUnlock()
+	for name, handler := range keywordHandlers {
+		raw, ok := schema.VendorExtensions[name]
+		if !ok {
+			continue
+		}
+		result.Errors = append(result.Errors, handler(path, value, raw)...)
+	}
+}
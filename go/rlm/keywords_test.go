@@ -0,0 +1,82 @@
+package rlm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema_UnmarshalCapturesVendorExtensions(t *testing.T) {
+	var schema JSONSchema
+	raw := `{"type": "string", "x-llm-retry-hint": "re-ask with more context", "minLength": 2}`
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if schema.Type != "string" || schema.MinLength == nil || *schema.MinLength != 2 {
+		t.Errorf("declared fields not decoded correctly: %+v", schema)
+	}
+	if got := schema.VendorExtensions["x-llm-retry-hint"]; got != "re-ask with more context" {
+		t.Errorf(`VendorExtensions["x-llm-retry-hint"] = %v, want "re-ask with more context"`, got)
+	}
+}
+
+func TestJSONSchema_MarshalRoundTripsVendorExtensions(t *testing.T) {
+	schema := &JSONSchema{
+		Type:             "string",
+		VendorExtensions: map[string]interface{}{"x-llm-retry-hint": "try again"},
+	}
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded JSONSchema
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := decoded.VendorExtensions["x-llm-retry-hint"]; got != "try again" {
+		t.Errorf(`round-tripped VendorExtensions["x-llm-retry-hint"] = %v, want "try again"`, got)
+	}
+}
+
+func TestJSONSchema_DialectRoundTrips(t *testing.T) {
+	schema := &JSONSchema{Type: "object", Dialect: "https://json-schema.org/draft/2020-12/schema"}
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded JSONSchema
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Dialect != schema.Dialect {
+		t.Errorf("Dialect = %q, want %q", decoded.Dialect, schema.Dialect)
+	}
+}
+
+func TestRegisterKeyword_InvokedDuringValidation(t *testing.T) {
+	const keyword = "x-test-keyword-chunk9-5"
+	var sawValue interface{}
+	RegisterKeyword(keyword, func(path string, value interface{}, raw interface{}) []ValidationError {
+		sawValue = value
+		if raw != "flag-as-invalid" {
+			return nil
+		}
+		return []ValidationError{{Path: path, Keyword: keyword, Message: "custom keyword rejected this value"}}
+	})
+
+	schema := &JSONSchema{
+		Type:             "string",
+		VendorExtensions: map[string]interface{}{keyword: "flag-as-invalid"},
+	}
+
+	err := validateValue("hello", schema)
+	if err == nil {
+		t.Fatal("expected the registered KeywordHandler's violation to surface")
+	}
+	if sawValue != "hello" {
+		t.Errorf("handler saw value = %v, want %q", sawValue, "hello")
+	}
+}
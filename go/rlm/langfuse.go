@@ -0,0 +1,383 @@
+package rlm
+
+// This file implements the Langfuse side of Observer's event sink: mapping
+// ObservabilityEvents onto the Langfuse ingestion API
+// (https://api.reference.langfuse.com/#tag/ingestion) and shipping them to
+// {LangfuseHost}/api/public/ingestion in batches, off the calling goroutine.
+//
+// Langfuse observation nesting mirrors Observer's own: StartSpan parents
+// each span on whatever ctx the caller passes in, so a span can itself be
+// the parent of a deeper span (e.g. meta_agent's optimize_query span nests
+// under the completion span it was invoked from). Because the mapping rides
+// on the OTel span IDs recorded in ObservabilityEvent.TraceID/SpanID/ParentID,
+// Langfuse ingestion only produces meaningful traces when TraceEnabled is
+// also set.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	langfuseIngestionPath  = "/api/public/ingestion"
+	langfuseDefaultHost    = "https://cloud.langfuse.com"
+	langfuseQueueSize      = 1000
+	langfuseBatchSize      = 50
+	langfuseFlushInterval  = 5 * time.Second
+	langfuseMaxRetries     = 5
+	langfuseRetryBaseDelay = 500 * time.Millisecond
+)
+
+// langfuseIngestionEvent is one entry of a Langfuse ingestion batch request:
+// a typed envelope around a type-specific body (trace-create, span-create,
+// span-update, generation-create, event-create).
+type langfuseIngestionEvent struct {
+	ID        string      `json:"id"`
+	Timestamp string      `json:"timestamp"`
+	Type      string      `json:"type"`
+	Body      interface{} `json:"body"`
+}
+
+type langfuseTraceBody struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name,omitempty"`
+	Timestamp string            `json:"timestamp,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type langfuseSpanCreateBody struct {
+	ID                  string            `json:"id"`
+	TraceID             string            `json:"traceId"`
+	ParentObservationID string            `json:"parentObservationId,omitempty"`
+	Name                string            `json:"name,omitempty"`
+	StartTime           string            `json:"startTime,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+}
+
+type langfuseSpanUpdateBody struct {
+	ID      string `json:"id"`
+	TraceID string `json:"traceId"`
+	EndTime string `json:"endTime,omitempty"`
+}
+
+type langfuseGenerationBody struct {
+	ID                  string            `json:"id"`
+	TraceID             string            `json:"traceId"`
+	ParentObservationID string            `json:"parentObservationId,omitempty"`
+	Name                string            `json:"name,omitempty"`
+	StartTime           string            `json:"startTime,omitempty"`
+	EndTime             string            `json:"endTime,omitempty"`
+	Model               string            `json:"model,omitempty"`
+	Input               string            `json:"input,omitempty"`
+	Output              string            `json:"output,omitempty"`
+	Usage               *langfuseUsage    `json:"usage,omitempty"`
+	Level               string            `json:"level,omitempty"`
+	StatusMessage       string            `json:"statusMessage,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+}
+
+type langfuseUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+}
+
+type langfuseEventBody struct {
+	ID        string            `json:"id"`
+	TraceID   string            `json:"traceId,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	StartTime string            `json:"startTime,omitempty"`
+	Level     string            `json:"level,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// langfuseClient batches ObservabilityEvents into Langfuse ingestion
+// requests and POSTs them with HTTP Basic auth from a background goroutine.
+// Enqueuing never blocks the caller: once the bounded queue is full,
+// further events are dropped (with a debug log line) rather than piling up
+// unbounded behind a slow or unreachable Langfuse host.
+type langfuseClient struct {
+	host       string
+	publicKey  string
+	secretKey  string
+	httpClient *http.Client
+	logger     *log.Logger
+
+	queue  chan langfuseIngestionEvent
+	done   chan struct{}
+	wg     sync.WaitGroup // the run() goroutine
+	sendWG sync.WaitGroup // in-flight send() calls spawned by run()
+}
+
+// newLangfuseClient starts the background flusher and returns a client
+// ready to accept events. Callers must call shutdown to drain the queue and
+// stop the goroutine.
+func newLangfuseClient(config ObservabilityConfig, logger *log.Logger) *langfuseClient {
+	host := config.LangfuseHost
+	if host == "" {
+		host = langfuseDefaultHost
+	}
+
+	c := &langfuseClient{
+		host:       strings.TrimSuffix(host, "/"),
+		publicKey:  config.LangfusePublicKey,
+		secretKey:  config.LangfuseSecretKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+		queue:      make(chan langfuseIngestionEvent, langfuseQueueSize),
+		done:       make(chan struct{}),
+	}
+
+	c.wg.Add(1)
+	go c.run()
+	return c
+}
+
+// ingest maps an ObservabilityEvent onto the Langfuse ingestion shape and
+// queues it. Event types with no Langfuse equivalent (e.g. "trace_end" -
+// Langfuse infers a trace's end from its observations, with no explicit
+// close call) are silently dropped.
+func (c *langfuseClient) ingest(event ObservabilityEvent) {
+	ts := event.Timestamp.UTC().Format(time.RFC3339Nano)
+
+	switch event.Type {
+	case "trace_start":
+		c.enqueue(langfuseIngestionEvent{
+			ID:        newLangfuseID(),
+			Timestamp: ts,
+			Type:      "trace-create",
+			Body: langfuseTraceBody{
+				ID:        event.TraceID,
+				Name:      event.Name,
+				Timestamp: ts,
+				Metadata:  event.Attributes,
+			},
+		})
+	case "span_start":
+		c.enqueue(langfuseIngestionEvent{
+			ID:        newLangfuseID(),
+			Timestamp: ts,
+			Type:      "span-create",
+			Body: langfuseSpanCreateBody{
+				ID:                  event.SpanID,
+				TraceID:             event.TraceID,
+				ParentObservationID: event.ParentID,
+				Name:                event.Name,
+				StartTime:           ts,
+				Metadata:            event.Attributes,
+			},
+		})
+	case "span_end":
+		c.enqueue(langfuseIngestionEvent{
+			ID:        newLangfuseID(),
+			Timestamp: ts,
+			Type:      "span-update",
+			Body: langfuseSpanUpdateBody{
+				ID:      event.SpanID,
+				TraceID: event.TraceID,
+				EndTime: ts,
+			},
+		})
+	case "llm_call":
+		start := event.Timestamp.Add(-event.Duration).UTC().Format(time.RFC3339Nano)
+		level := ""
+		if event.Attributes["error"] != "" {
+			level = "ERROR"
+		}
+		c.enqueue(langfuseIngestionEvent{
+			ID:        newLangfuseID(),
+			Timestamp: ts,
+			Type:      "generation-create",
+			Body: langfuseGenerationBody{
+				ID:                  event.SpanID,
+				TraceID:             event.TraceID,
+				ParentObservationID: event.ParentID,
+				Name:                event.Name,
+				StartTime:           start,
+				EndTime:             ts,
+				Model:               event.Attributes["model"],
+				Input:               event.Attributes["prompt"],
+				Output:              event.Attributes["completion"],
+				Usage: &langfuseUsage{
+					PromptTokens:     atoiOrZero(event.Attributes["prompt_tokens"]),
+					CompletionTokens: atoiOrZero(event.Attributes["completion_tokens"]),
+				},
+				Level:         level,
+				StatusMessage: event.Attributes["error"],
+			},
+		})
+	case "event":
+		c.enqueue(langfuseIngestionEvent{
+			ID:        newLangfuseID(),
+			Timestamp: ts,
+			Type:      "event-create",
+			Body: langfuseEventBody{
+				ID:        newLangfuseID(),
+				TraceID:   event.TraceID,
+				Name:      event.Name,
+				StartTime: ts,
+				Metadata:  event.Attributes,
+			},
+		})
+	case "error":
+		c.enqueue(langfuseIngestionEvent{
+			ID:        newLangfuseID(),
+			Timestamp: ts,
+			Type:      "event-create",
+			Body: langfuseEventBody{
+				ID:        newLangfuseID(),
+				TraceID:   event.TraceID,
+				Name:      event.Name,
+				StartTime: ts,
+				Level:     "ERROR",
+				Metadata:  event.Attributes,
+			},
+		})
+	}
+}
+
+func (c *langfuseClient) enqueue(event langfuseIngestionEvent) {
+	select {
+	case c.queue <- event:
+	default:
+		c.logger.Printf("[langfuse] queue full (%d), dropping %s event", langfuseQueueSize, event.Type)
+	}
+}
+
+// run is the background flusher: it batches queued events up to
+// langfuseBatchSize, flushing early on a langfuseFlushInterval tick so a
+// trickle of events doesn't sit unsent indefinitely. On shutdown it drains
+// whatever is already queued before returning. Each flush's send() runs in
+// its own goroutine (tracked by sendWG) so a slow or backed-off send can't
+// stall draining the queue - otherwise a single flaky send would hold up
+// every event behind it for the full retry/backoff window.
+func (c *langfuseClient) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(langfuseFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]langfuseIngestionEvent, 0, langfuseBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		toSend := batch
+		batch = make([]langfuseIngestionEvent, 0, langfuseBatchSize)
+
+		c.sendWG.Add(1)
+		go func() {
+			defer c.sendWG.Done()
+			c.send(toSend)
+		}()
+	}
+
+	for {
+		select {
+		case event := <-c.queue:
+			batch = append(batch, event)
+			if len(batch) >= langfuseBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-c.done:
+			for {
+				select {
+				case event := <-c.queue:
+					batch = append(batch, event)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send POSTs batch to the ingestion endpoint, retrying with exponential
+// backoff on 429 (rate limited) or 5xx responses. Any other non-2xx
+// response is treated as non-retryable - a malformed batch will fail the
+// same way on every attempt.
+func (c *langfuseClient) send(batch []langfuseIngestionEvent) {
+	payload, err := json.Marshal(map[string]interface{}{"batch": batch})
+	if err != nil {
+		c.logger.Printf("[langfuse] failed to marshal batch of %d events: %v", len(batch), err)
+		return
+	}
+
+	delay := langfuseRetryBaseDelay
+	for attempt := 0; attempt <= langfuseMaxRetries; attempt++ {
+		status, err := c.post(payload)
+		if err == nil && status >= 200 && status < 300 {
+			return
+		}
+
+		retryable := err != nil || status == http.StatusTooManyRequests || status >= 500
+		if !retryable {
+			c.logger.Printf("[langfuse] ingestion rejected (status %d)", status)
+			return
+		}
+		if attempt == langfuseMaxRetries {
+			c.logger.Printf("[langfuse] giving up after %d attempts (status %d): %v", attempt+1, status, err)
+			return
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (c *langfuseClient) post(payload []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+langfuseIngestionPath, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.publicKey, c.secretKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the underlying connection can be reused by the
+	// transport's keep-alive pool instead of forcing a fresh TCP/TLS
+	// handshake on the next flush.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// shutdown signals the background flusher to drain the queue and send a
+// final batch, blocking until the flusher and every send it spawned finish.
+func (c *langfuseClient) shutdown() {
+	close(c.done)
+	c.wg.Wait()
+	c.sendWG.Wait()
+}
+
+func newLangfuseID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,142 @@
+package rlm
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLangfuseClient_IngestAndFlush(t *testing.T) {
+	var mu sync.Mutex
+	var batches []map[string]interface{}
+	var gotAuth bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/public/ingestion" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); ok && user == "pub" && pass == "secret" {
+			mu.Lock()
+			gotAuth = true
+			mu.Unlock()
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+
+		mu.Lock()
+		batches = append(batches, body)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := newLangfuseClient(ObservabilityConfig{
+		LangfuseHost:      server.URL,
+		LangfusePublicKey: "pub",
+		LangfuseSecretKey: "secret",
+	}, log.New(io.Discard, "", 0))
+
+	c.ingest(ObservabilityEvent{
+		Timestamp: time.Now(),
+		Type:      "trace_start",
+		Name:      "root",
+		TraceID:   "trace-1",
+	})
+	c.ingest(ObservabilityEvent{
+		Timestamp: time.Now(),
+		Type:      "llm_call",
+		Name:      "llm.gpt-4o",
+		TraceID:   "trace-1",
+		SpanID:    "span-1",
+		Duration:  2 * time.Second,
+		Attributes: map[string]string{
+			"model":             "gpt-4o",
+			"prompt_tokens":     "10",
+			"completion_tokens": "20",
+		},
+	})
+
+	c.shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) == 0 {
+		t.Fatal("expected at least one batch to be sent")
+	}
+	if !gotAuth {
+		t.Error("expected requests to carry HTTP Basic auth with the configured keys")
+	}
+
+	var sawTrace, sawGeneration bool
+	for _, batch := range batches {
+		items, _ := batch["batch"].([]interface{})
+		for _, itemRaw := range items {
+			item, _ := itemRaw.(map[string]interface{})
+			switch item["type"] {
+			case "trace-create":
+				sawTrace = true
+			case "generation-create":
+				sawGeneration = true
+				body, _ := item["body"].(map[string]interface{})
+				usage, _ := body["usage"].(map[string]interface{})
+				if usage["promptTokens"] != float64(10) {
+					t.Errorf("generation usage.promptTokens = %v, want 10", usage["promptTokens"])
+				}
+			}
+		}
+	}
+	if !sawTrace {
+		t.Error("expected a trace-create event in the ingested batches")
+	}
+	if !sawGeneration {
+		t.Error("expected a generation-create event in the ingested batches")
+	}
+}
+
+func TestLangfuseClient_UnmappedEventTypeDropped(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := newLangfuseClient(ObservabilityConfig{LangfuseHost: server.URL}, log.New(io.Discard, "", 0))
+	c.ingest(ObservabilityEvent{Timestamp: time.Now(), Type: "trace_end"})
+	c.shutdown()
+
+	if hits != 0 {
+		t.Errorf("expected no HTTP requests for an unmapped event type, got %d", hits)
+	}
+}
+
+func TestLangfuseClient_QueueFullDropsEvents(t *testing.T) {
+	// Build the client without starting run(), so no flusher drains the
+	// queue while the test fills it.
+	c := &langfuseClient{
+		logger: log.New(io.Discard, "", 0),
+		queue:  make(chan langfuseIngestionEvent, langfuseQueueSize),
+		done:   make(chan struct{}),
+	}
+
+	// Fill the queue directly so enqueue has to drop rather than block.
+	for i := 0; i < langfuseQueueSize; i++ {
+		c.queue <- langfuseIngestionEvent{Type: "event-create"}
+	}
+	c.enqueue(langfuseIngestionEvent{Type: "event-create"})
+
+	if len(c.queue) != langfuseQueueSize {
+		t.Errorf("queue len = %d, want %d (enqueue on a full queue should drop, not block or grow)", len(c.queue), langfuseQueueSize)
+	}
+
+	c.shutdown()
+}
@@ -1,25 +1,43 @@
 package rlm
 
 import (
+	stdcontext "context"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // MetaAgentConfig configures the meta-agent behavior.
 type MetaAgentConfig struct {
 	Enabled        bool   `json:"enabled"`
-	Model          string `json:"model,omitempty"`           // Model to use for meta-agent (defaults to main model)
+	Model          string `json:"model,omitempty"`            // Model to use for meta-agent (defaults to main model)
 	MaxOptimizeLen int    `json:"max_optimize_len,omitempty"` // Max context length before optimization (0 = always optimize)
+
+	// Cache, if set, lets OptimizeQuery reuse a previous optimization
+	// instead of re-invoking the LLM for repeated or near-identical
+	// queries in batch pipelines. NewMemoryOptimizationCache is the
+	// ready-made in-memory default. Nil (the zero value) disables caching.
+	Cache OptimizationCache `json:"-"`
+	// CacheTTL bounds how long a cached optimization stays valid. 0 means
+	// entries never expire on their own (they can still be evicted under
+	// LRU capacity pressure).
+	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
+	// SimilarityThreshold is how close two contexts' SimHash fingerprints
+	// must be - as a fraction of matching bits out of 64, 0..1 - to count
+	// as a cache hit rather than a miss. Defaults to 0.95 when Cache is
+	// set and SimilarityThreshold is left at its zero value.
+	SimilarityThreshold float64 `json:"similarity_threshold,omitempty"`
 }
 
 // MetaAgent optimizes queries before passing them to the RLM engine.
 // It analyzes raw, non-optimized messages and rewrites them for better
 // recursive decomposition and structured extraction.
 type MetaAgent struct {
-	config  MetaAgentConfig
-	rlm     *RLM
-	obs     *Observer
+	config MetaAgentConfig
+	rlm    *RLM
+	obs    *Observer
+	sf     singleflightGroup // collapses concurrent cache-miss optimizations of the same query+model
 }
 
 // NewMetaAgent creates a MetaAgent wrapping an RLM engine.
@@ -35,13 +53,15 @@ func NewMetaAgent(rlm *RLM, config MetaAgentConfig, obs *Observer) *MetaAgent {
 }
 
 // OptimizeQuery takes a raw query and context, and returns an optimized query
-// that is better suited for RLM processing.
-func (ma *MetaAgent) OptimizeQuery(query string, context string) (string, error) {
-	ctx := ma.obs.StartSpan("meta_agent.optimize_query", map[string]string{
+// that is better suited for RLM processing. ctx bounds the optimization LLM
+// call and is also the span parent, so this call nests correctly under the
+// completion (or recursive_llm call) that invoked it - see RLM.CompletionContext.
+func (ma *MetaAgent) OptimizeQuery(ctx stdcontext.Context, query string, context string) (string, error) {
+	ctx, span := ma.obs.StartSpan(ctx, "meta_agent.optimize_query", map[string]string{
 		"query_length":   fmt.Sprintf("%d", len(query)),
 		"context_length": fmt.Sprintf("%d", len(context)),
 	})
-	defer ma.obs.EndSpan(ctx)
+	defer span.End()
 
 	ma.obs.Debug("meta_agent", "Optimizing query: %s", truncateStr(query, 200))
 
@@ -51,6 +71,43 @@ func (ma *MetaAgent) OptimizeQuery(query string, context string) (string, error)
 		return query, nil
 	}
 
+	if ma.config.Cache == nil {
+		optimized, err := ma.callOptimize(ctx, query, context)
+		if err != nil {
+			return query, nil
+		}
+		return optimized, nil
+	}
+
+	key := ma.cacheKey(query, context, "")
+	if cached, ok := ma.config.Cache.Get(key, ma.maxHammingDistance()); ok {
+		ma.obs.Event("meta_agent.cache_hit", map[string]string{"model": ma.config.Model})
+		return cached, nil
+	}
+	ma.obs.Event("meta_agent.cache_miss", map[string]string{"model": ma.config.Model})
+
+	// Collapse concurrent misses for the same (query, model) into one
+	// upstream call instead of letting a batch pipeline stampede the LLM.
+	optimized, err, shared := ma.sf.do(key.NormalizedQuery+"\x00"+key.Model, func() (string, error) {
+		return ma.callOptimize(ctx, query, context)
+	})
+	if shared {
+		ma.obs.Event("meta_agent.cache_stampede_collapsed", map[string]string{"model": ma.config.Model})
+	}
+	if err != nil {
+		return query, nil
+	}
+
+	if evicted := ma.config.Cache.Set(key, optimized, ma.config.CacheTTL); evicted {
+		ma.obs.Event("meta_agent.cache_evicted", map[string]string{"model": ma.config.Model})
+	}
+
+	return optimized, nil
+}
+
+// callOptimize runs the actual LLM call behind OptimizeQuery's cache and
+// singleflight layers.
+func (ma *MetaAgent) callOptimize(ctx stdcontext.Context, query string, context string) (string, error) {
 	optimizePrompt := ma.buildOptimizePrompt(query, context)
 
 	messages := []Message{
@@ -65,14 +122,15 @@ func (ma *MetaAgent) OptimizeQuery(query string, context string) (string, error)
 		APIKey:      ma.rlm.apiKey,
 		Timeout:     ma.rlm.timeoutSeconds,
 		ExtraParams: ma.rlm.extraParams,
+		Retry:       ma.rlm.retry,
 	}
 
-	result, err := CallChatCompletion(request)
+	result, err := CallChatCompletionContext(ctx, request)
 	if err != nil {
 		ma.obs.Error("meta_agent", "Failed to optimize query: %v", err)
-		// Fall back to original query on error
-		return query, nil
+		return "", err
 	}
+	ma.obs.RecordMetaAgentOptimization()
 
 	optimized := strings.TrimSpace(result)
 	ma.obs.Debug("meta_agent", "Optimized query: %s", truncateStr(optimized, 200))
@@ -84,15 +142,39 @@ func (ma *MetaAgent) OptimizeQuery(query string, context string) (string, error)
 	return optimized, nil
 }
 
+// cacheKey builds the composite OptimizationCacheKey for a single
+// OptimizeQuery call. schemaHash is empty here; it exists so the same key
+// shape can be reused if OptimizeForStructured grows caching later.
+func (ma *MetaAgent) cacheKey(query string, context string, schemaHash string) OptimizationCacheKey {
+	return OptimizationCacheKey{
+		NormalizedQuery:    normalizeForCache(query),
+		ContextFingerprint: contextFingerprint(context),
+		Model:              ma.config.Model,
+		SchemaHash:         schemaHash,
+	}
+}
+
+// maxHammingDistance converts SimilarityThreshold (the fraction of a
+// 64-bit SimHash's bits that must match, 0..1) into the max Hamming
+// distance the cache treats as a hit. Defaults to a threshold of 0.95.
+func (ma *MetaAgent) maxHammingDistance() int {
+	threshold := ma.config.SimilarityThreshold
+	if threshold <= 0 {
+		threshold = 0.95
+	}
+	return int((1 - threshold) * 64)
+}
+
 // OptimizeForStructured takes a raw query and schema, and returns an optimized
-// query specifically designed for structured extraction.
-func (ma *MetaAgent) OptimizeForStructured(query string, context string, schema *JSONSchema) (string, error) {
-	ctx := ma.obs.StartSpan("meta_agent.optimize_structured", map[string]string{
+// query specifically designed for structured extraction. ctx bounds the
+// optimization LLM call and parents its span, the same as OptimizeQuery.
+func (ma *MetaAgent) OptimizeForStructured(ctx stdcontext.Context, query string, context string, schema *JSONSchema) (string, error) {
+	ctx, span := ma.obs.StartSpan(ctx, "meta_agent.optimize_structured", map[string]string{
 		"query_length":   fmt.Sprintf("%d", len(query)),
 		"context_length": fmt.Sprintf("%d", len(context)),
 		"schema_type":    schema.Type,
 	})
-	defer ma.obs.EndSpan(ctx)
+	defer span.End()
 
 	ma.obs.Debug("meta_agent", "Optimizing for structured extraction: %s", truncateStr(query, 200))
 
@@ -101,20 +183,48 @@ func (ma *MetaAgent) OptimizeForStructured(query string, context string, schema
 		return query, nil
 	}
 
-	optimizePrompt := fmt.Sprintf(
-		"I need to extract structured data from a document. Please optimize my query "+
-			"for better extraction accuracy.\n\n"+
-			"Original query: %s\n\n"+
-			"Target JSON Schema:\n%s\n\n"+
-			"Context preview (first 500 chars):\n%s\n\n"+
-			"Please provide an optimized extraction query that:\n"+
-			"1. Explicitly references each required field from the schema\n"+
-			"2. Provides clear extraction instructions for complex types (arrays, nested objects)\n"+
-			"3. Specifies expected formats and constraints\n"+
-			"4. Includes hints about where to find the data in the context\n\n"+
-			"Return ONLY the optimized query text, nothing else.",
-		query, string(schemaJSON), truncateStr(context, 500),
-	)
+	// When the backend can itself constrain decoding to the schema's shape
+	// (see structuredGrammarExtra), the LLM can't emit a field in the wrong
+	// place or type even if it wants to - so the per-field prose hints below
+	// are wasted tokens at best and a contradictory distraction at worst.
+	// Skip straight to sharpening the query's intent instead.
+	_, grammarConstrained := ma.rlm.structuredGrammarExtra(schema, "")
+
+	examplesHint := ""
+	if examples := collectSchemaExamples(schema, ""); len(examples) > 0 {
+		examplesJSON, err := json.Marshal(examples)
+		if err == nil {
+			examplesHint = fmt.Sprintf("\nExample values for these fields (from the schema's OpenAPI \"example\" keywords):\n%s\n", string(examplesJSON))
+		}
+	}
+
+	var optimizePrompt string
+	if grammarConstrained {
+		optimizePrompt = fmt.Sprintf(
+			"I need to extract structured data from a document. The output shape is already "+
+				"enforced by grammar-constrained decoding, so don't restate the schema fields - "+
+				"just sharpen the query so the right values end up in the right places.\n\n"+
+				"Original query: %s\n%s\n"+
+				"Context preview (first 500 chars):\n%s\n\n"+
+				"Return ONLY the optimized query text, nothing else.",
+			query, examplesHint, truncateStr(context, 500),
+		)
+	} else {
+		optimizePrompt = fmt.Sprintf(
+			"I need to extract structured data from a document. Please optimize my query "+
+				"for better extraction accuracy.\n\n"+
+				"Original query: %s\n\n"+
+				"Target JSON Schema:\n%s\n%s\n"+
+				"Context preview (first 500 chars):\n%s\n\n"+
+				"Please provide an optimized extraction query that:\n"+
+				"1. Explicitly references each required field from the schema\n"+
+				"2. Provides clear extraction instructions for complex types (arrays, nested objects)\n"+
+				"3. Specifies expected formats and constraints\n"+
+				"4. Includes hints about where to find the data in the context\n\n"+
+				"Return ONLY the optimized query text, nothing else.",
+			query, string(schemaJSON), examplesHint, truncateStr(context, 500),
+		)
+	}
 
 	messages := []Message{
 		{Role: "system", Content: metaAgentStructuredPrompt},
@@ -128,13 +238,15 @@ func (ma *MetaAgent) OptimizeForStructured(query string, context string, schema
 		APIKey:      ma.rlm.apiKey,
 		Timeout:     ma.rlm.timeoutSeconds,
 		ExtraParams: ma.rlm.extraParams,
+		Retry:       ma.rlm.retry,
 	}
 
-	result, err := CallChatCompletion(request)
+	result, err := CallChatCompletionContext(ctx, request)
 	if err != nil {
 		ma.obs.Error("meta_agent", "Failed to optimize structured query: %v", err)
 		return query, nil
 	}
+	ma.obs.RecordMetaAgentOptimization()
 
 	optimized := strings.TrimSpace(result)
 	ma.obs.Debug("meta_agent", "Optimized structured query: %s", truncateStr(optimized, 200))
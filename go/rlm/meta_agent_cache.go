@@ -0,0 +1,254 @@
+package rlm
+
+import (
+	"container/list"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OptimizationCache lets MetaAgent.OptimizeQuery skip the upstream LLM call
+// for a query it has already optimized in a semantically similar context.
+// NewMemoryOptimizationCache is the default, in-process implementation;
+// operators can plug in a Redis- or BoltDB-backed implementation of the
+// same interface for cross-process reuse, the same way MetricsObserver
+// lets them swap in Prometheus without touching call sites.
+type OptimizationCache interface {
+	// Get looks up the cached optimization for key, treating any stored
+	// entry whose ContextFingerprint is within maxHammingDistance bits of
+	// key.ContextFingerprint as a match. It returns ok=false on a miss or
+	// an expired entry.
+	Get(key OptimizationCacheKey, maxHammingDistance int) (value string, ok bool)
+	// Set stores value under key with the given TTL (0 = no expiry),
+	// reporting whether storing it evicted an older entry.
+	Set(key OptimizationCacheKey, value string, ttl time.Duration) (evicted bool)
+}
+
+// OptimizationCacheKey is the composite lookup key for a single
+// OptimizeQuery call (OptimizeForStructured is not cached). ContextFingerprint is a SimHash
+// over token shingles of the context rather than a byte-exact hash, so two
+// near-identical contexts (extra whitespace, a handful of edited words)
+// land within a small Hamming distance of each other instead of requiring
+// an exact match. SchemaHash is empty for plain OptimizeQuery calls; it's
+// part of the key shape so the same cache can also key schema-aware
+// optimization without the fields changing.
+type OptimizationCacheKey struct {
+	NormalizedQuery    string
+	ContextFingerprint uint64
+	Model              string
+	SchemaHash         string
+}
+
+// bucketKey groups cache entries that differ only in ContextFingerprint, so
+// a Get only has to scan the (usually tiny) set of entries that could
+// plausibly match on Hamming distance instead of the whole cache.
+func (k OptimizationCacheKey) bucketKey() string {
+	return k.NormalizedQuery + "\x00" + k.Model + "\x00" + k.SchemaHash
+}
+
+// contextFingerprint computes a 64-bit SimHash over whitespace-delimited
+// 3-token shingles of s. Hamming distance between two fingerprints falls as
+// the shingles they share grow, so near-duplicate contexts end up close in
+// bit-space even though they aren't byte-identical.
+func contextFingerprint(s string) uint64 {
+	const shingleSize = 3
+	tokens := strings.Fields(s)
+	if len(tokens) == 0 {
+		return 0
+	}
+	if len(tokens) < shingleSize {
+		return fnvHash64(strings.Join(tokens, " "))
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(tokens); i++ {
+		h := fnvHash64(strings.Join(tokens[i:i+shingleSize], " "))
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fp uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fp |= 1 << uint(bit)
+		}
+	}
+	return fp
+}
+
+func fnvHash64(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// hammingDistance counts the bits that differ between a and b.
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// normalizeForCache lowercases and collapses whitespace in s so that
+// cosmetic differences (casing, repeated spaces) don't fragment the cache.
+func normalizeForCache(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// memoryCacheEntry is one cached optimization, plus the list.Element that
+// tracks its recency for LRU eviction.
+type memoryCacheEntry struct {
+	bucket      string
+	fingerprint uint64
+	value       string
+	expiresAt   time.Time
+	elem        *list.Element
+}
+
+// MemoryOptimizationCache is an in-process, LRU-bounded OptimizationCache.
+// It's the default cache MetaAgent uses when MetaAgentConfig.Cache is set
+// without a custom implementation.
+type MemoryOptimizationCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front = most recently used
+	buckets  map[string][]*memoryCacheEntry
+}
+
+// NewMemoryOptimizationCache creates a MemoryOptimizationCache holding at
+// most capacity entries (0 or negative defaults to 1000), evicting the
+// least-recently-used entry once it's full.
+func NewMemoryOptimizationCache(capacity int) *MemoryOptimizationCache {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryOptimizationCache{
+		capacity: capacity,
+		order:    list.New(),
+		buckets:  make(map[string][]*memoryCacheEntry),
+	}
+}
+
+// Get implements OptimizationCache.
+func (c *MemoryOptimizationCache) Get(key OptimizationCacheKey, maxHammingDistance int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bucket := key.bucketKey()
+	now := time.Now()
+	entries := c.buckets[bucket]
+	for i := 0; i < len(entries); {
+		entry := entries[i]
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			c.removeEntryLocked(entry)
+			entries = c.buckets[bucket]
+			continue
+		}
+		i++
+	}
+
+	for _, entry := range entries {
+		if hammingDistance(entry.fingerprint, key.ContextFingerprint) <= maxHammingDistance {
+			c.order.MoveToFront(entry.elem)
+			return entry.value, true
+		}
+	}
+	return "", false
+}
+
+// Set implements OptimizationCache.
+func (c *MemoryOptimizationCache) Set(key OptimizationCacheKey, value string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bucket := key.bucketKey()
+	var expiresAt time.Time
+	if ttl != 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	entry := &memoryCacheEntry{
+		bucket:      bucket,
+		fingerprint: key.ContextFingerprint,
+		value:       value,
+		expiresAt:   expiresAt,
+	}
+	entry.elem = c.order.PushFront(entry)
+	c.buckets[bucket] = append(c.buckets[bucket], entry)
+
+	evicted := false
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeEntryLocked(oldest.Value.(*memoryCacheEntry))
+		evicted = true
+	}
+	return evicted
+}
+
+// removeEntryLocked drops entry from both the recency list and its bucket.
+// Callers must hold c.mu.
+func (c *MemoryOptimizationCache) removeEntryLocked(entry *memoryCacheEntry) {
+	c.order.Remove(entry.elem)
+	entries := c.buckets[entry.bucket]
+	for i, e := range entries {
+		if e == entry {
+			c.buckets[entry.bucket] = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(c.buckets[entry.bucket]) == 0 {
+		delete(c.buckets, entry.bucket)
+	}
+}
+
+// singleflightGroup collapses concurrent calls sharing a key into one
+// underlying call, so a cache-miss stampede for the same
+// (normalized query, model) pair hits the upstream LLM once instead of
+// once per waiting caller. The zero value is ready to use.
+type singleflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*singleflightCall
+}
+
+type singleflightCall struct {
+	wg    sync.WaitGroup
+	value string
+	err   error
+}
+
+// do runs fn for key, or waits for an in-flight call for the same key and
+// reuses its result. shared reports whether the caller got a reused result
+// rather than running fn itself.
+func (g *singleflightGroup) do(key string, fn func() (string, error)) (value string, err error, shared bool) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.value, call.err, true
+	}
+
+	call := &singleflightCall{}
+	call.wg.Add(1)
+	if g.calls == nil {
+		g.calls = make(map[string]*singleflightCall)
+	}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.value, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return call.value, call.err, false
+}
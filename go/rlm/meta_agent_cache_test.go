@@ -0,0 +1,142 @@
+package rlm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryOptimizationCache_HitAndMiss(t *testing.T) {
+	cache := NewMemoryOptimizationCache(10)
+	key := OptimizationCacheKey{
+		NormalizedQuery:    "summarize the document",
+		ContextFingerprint: contextFingerprint("alpha beta gamma delta"),
+		Model:              "gpt-4o",
+	}
+
+	if _, ok := cache.Get(key, 0); ok {
+		t.Fatal("expected a miss before any Set")
+	}
+
+	cache.Set(key, "optimized query", 0)
+
+	value, ok := cache.Get(key, 0)
+	if !ok {
+		t.Fatal("expected a hit after Set")
+	}
+	if value != "optimized query" {
+		t.Errorf("Get() = %q, want %q", value, "optimized query")
+	}
+}
+
+func TestMemoryOptimizationCache_FuzzyMatchWithinThreshold(t *testing.T) {
+	cache := NewMemoryOptimizationCache(10)
+	stored := OptimizationCacheKey{
+		NormalizedQuery:    "summarize the document",
+		ContextFingerprint: contextFingerprint("the quick brown fox jumps over the lazy dog"),
+		Model:              "gpt-4o",
+	}
+	cache.Set(stored, "optimized query", 0)
+
+	lookup := OptimizationCacheKey{
+		NormalizedQuery:    "summarize the document",
+		ContextFingerprint: contextFingerprint("the quick brown fox jumps over a lazy dog"),
+		Model:              "gpt-4o",
+	}
+
+	if _, ok := cache.Get(lookup, 0); ok {
+		t.Error("expected a near-identical context to miss at maxHammingDistance=0")
+	}
+	if _, ok := cache.Get(lookup, 64); !ok {
+		t.Error("expected a near-identical context to hit once the distance budget is wide open")
+	}
+}
+
+func TestMemoryOptimizationCache_TTLExpiry(t *testing.T) {
+	cache := NewMemoryOptimizationCache(10)
+	key := OptimizationCacheKey{NormalizedQuery: "q", Model: "gpt-4o"}
+
+	cache.Set(key, "optimized", -time.Second)
+
+	if _, ok := cache.Get(key, 64); ok {
+		t.Error("expected an already-expired entry to miss")
+	}
+}
+
+func TestMemoryOptimizationCache_LRUEviction(t *testing.T) {
+	cache := NewMemoryOptimizationCache(2)
+
+	keyFor := func(i int) OptimizationCacheKey {
+		return OptimizationCacheKey{NormalizedQuery: fmt.Sprintf("q%d", i), Model: "gpt-4o"}
+	}
+
+	cache.Set(keyFor(1), "one", 0)
+	cache.Set(keyFor(2), "two", 0)
+	if evicted := cache.Set(keyFor(3), "three", 0); !evicted {
+		t.Error("expected adding a 3rd entry beyond capacity 2 to evict the oldest")
+	}
+
+	if _, ok := cache.Get(keyFor(1), 64); ok {
+		t.Error("expected the least-recently-used entry to have been evicted")
+	}
+	if _, ok := cache.Get(keyFor(2), 64); !ok {
+		t.Error("expected the more recently used entry to survive")
+	}
+	if _, ok := cache.Get(keyFor(3), 64); !ok {
+		t.Error("expected the newly added entry to be present")
+	}
+}
+
+func TestContextFingerprint_SimilarTextIsCloser(t *testing.T) {
+	base := contextFingerprint("the quick brown fox jumps over the lazy dog")
+	similar := contextFingerprint("the quick brown fox jumps over a lazy dog")
+	different := contextFingerprint("quarterly revenue grew twelve percent year over year")
+
+	similarDistance := hammingDistance(base, similar)
+	differentDistance := hammingDistance(base, different)
+
+	if similarDistance >= differentDistance {
+		t.Errorf("expected similar text to have a smaller Hamming distance than unrelated text, got similar=%d different=%d", similarDistance, differentDistance)
+	}
+}
+
+func TestSingleflightGroup_CollapsesConcurrentCalls(t *testing.T) {
+	var sf singleflightGroup
+	var calls int32
+	var mu sync.Mutex
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	shared := make([]bool, 5)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			value, _, isShared := sf.do("same-key", func() (string, error) {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				return "result", nil
+			})
+			results[i] = value
+			shared[i] = isShared
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected exactly 1 underlying call for 5 concurrent callers sharing a key, got %d", calls)
+	}
+	for i, value := range results {
+		if value != "result" {
+			t.Errorf("results[%d] = %q, want %q", i, value, "result")
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package rlm
+
+import (
+	"context"
+	"log"
+	"runtime"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// rlmMeter holds the instruments backing Observer's rlm.* runtime
+// measurements: iteration/depth/call counts and durations for the
+// recursion loop itself, as opposed to genAIMeter's GenAI token/cost
+// measurements. Split out from Observer for the same reason genAIMeter is -
+// see buildRLMMeter.
+type rlmMeter struct {
+	iterations             metric.Int64Counter
+	depth                  metric.Int64Histogram
+	llmCalls               metric.Int64Counter
+	llmDuration            metric.Float64Histogram
+	parsingRetries         metric.Int64Counter
+	metaAgentOptimizations metric.Int64Counter
+	toolCalls              metric.Int64Counter
+}
+
+// buildRLMMeter creates the rlm.* instruments on meter, the Meter shared
+// with genAIMeter - see Observer.setupMeters. Returns nil if any
+// instrument fails to register, logging via logger rather than erroring so
+// a Meter-level problem doesn't also take down tracing or the rest of the
+// Observer.
+func buildRLMMeter(meter metric.Meter, logger *log.Logger) *rlmMeter {
+	iterations, err := meter.Int64Counter(
+		"rlm.iterations",
+		metric.WithDescription("Number of REPL iterations executed"),
+		metric.WithUnit("{iteration}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.iterations counter: %v", err)
+		return nil
+	}
+
+	depth, err := meter.Int64Histogram(
+		"rlm.depth",
+		metric.WithDescription("Recursion depth at each iteration"),
+		metric.WithUnit("{depth}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.depth histogram: %v", err)
+		return nil
+	}
+
+	llmCalls, err := meter.Int64Counter(
+		"rlm.llm.calls",
+		metric.WithDescription("Number of LLM API calls made"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.llm.calls counter: %v", err)
+		return nil
+	}
+
+	llmDuration, err := meter.Float64Histogram(
+		"rlm.llm.duration",
+		metric.WithDescription("Duration of LLM API calls"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.llm.duration histogram: %v", err)
+		return nil
+	}
+
+	parsingRetries, err := meter.Int64Counter(
+		"rlm.parsing.retries",
+		metric.WithDescription("Number of structured-output parsing retries"),
+		metric.WithUnit("{retry}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.parsing.retries counter: %v", err)
+		return nil
+	}
+
+	metaAgentOptimizations, err := meter.Int64Counter(
+		"rlm.meta_agent.optimizations",
+		metric.WithDescription("Number of MetaAgent query/structured-output optimizations performed"),
+		metric.WithUnit("{optimization}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.meta_agent.optimizations counter: %v", err)
+		return nil
+	}
+
+	toolCalls, err := meter.Int64Counter(
+		"rlm.tool.calls",
+		metric.WithDescription("Number of tool invocations, tagged by tool name"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		logger.Printf("Failed to create rlm.tool.calls counter: %v", err)
+		return nil
+	}
+
+	return &rlmMeter{
+		iterations:             iterations,
+		depth:                  depth,
+		llmCalls:               llmCalls,
+		llmDuration:            llmDuration,
+		parsingRetries:         parsingRetries,
+		metaAgentOptimizations: metaAgentOptimizations,
+		toolCalls:              toolCalls,
+	}
+}
+
+// RecordIteration records one REPL iteration at depth on the Meter. A
+// no-op when MetricsEnabled is false, so call sites never need a nil
+// check.
+func (o *Observer) RecordIteration(depth int) {
+	if o.rlm == nil {
+		return
+	}
+	ctx := context.Background()
+	depthAttr := attribute.Int("depth", depth)
+	o.rlm.iterations.Add(ctx, 1, metric.WithAttributes(depthAttr))
+	o.rlm.depth.Record(ctx, int64(depth), metric.WithAttributes(depthAttr))
+}
+
+// recordRLMCallMetrics records one LLM call's model and duration on the
+// Meter, alongside recordGenAIMetrics's token-usage measurements - both are
+// called together from Observer.LLMCall. A no-op when MetricsEnabled is
+// false.
+func (o *Observer) recordRLMCallMetrics(info LLMCallInfo, durationSeconds float64) {
+	if o.rlm == nil {
+		return
+	}
+	ctx := context.Background()
+	attrs := metric.WithAttributes(attribute.String("model", info.Model))
+	o.rlm.llmCalls.Add(ctx, 1, attrs)
+	o.rlm.llmDuration.Record(ctx, durationSeconds, attrs)
+}
+
+// RecordParsingRetries records retries structured-output parsing retries at
+// depth on the Meter. A no-op when MetricsEnabled is false or retries is 0.
+func (o *Observer) RecordParsingRetries(depth int, retries int) {
+	if o.rlm == nil || retries <= 0 {
+		return
+	}
+	o.rlm.parsingRetries.Add(context.Background(), int64(retries), metric.WithAttributes(attribute.Int("depth", depth)))
+}
+
+// RecordMetaAgentOptimization records one MetaAgent optimization call on the
+// Meter. A no-op when MetricsEnabled is false.
+func (o *Observer) RecordMetaAgentOptimization() {
+	if o.rlm == nil {
+		return
+	}
+	o.rlm.metaAgentOptimizations.Add(context.Background(), 1)
+}
+
+// RecordToolCall records one invocation of the named tool on the Meter. A
+// no-op when MetricsEnabled is false.
+func (o *Observer) RecordToolCall(tool string) {
+	if o.rlm == nil {
+		return
+	}
+	o.rlm.toolCalls.Add(context.Background(), 1, metric.WithAttributes(attribute.String("tool", tool)))
+}
+
+// registerRuntimeMetrics registers Go runtime observable gauges
+// (goroutines, heap bytes, GC count) on meter, read fresh from the runtime
+// package on every collection rather than polled on a timer - an
+// asynchronous instrument's callback only runs when a reader actually
+// collects, so this adds no background goroutine of its own.
+func registerRuntimeMetrics(meter metric.Meter) error {
+	goroutines, err := meter.Int64ObservableGauge(
+		"process.runtime.go.goroutines",
+		metric.WithDescription("Number of goroutines that currently exist"),
+		metric.WithUnit("{goroutine}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	heapAlloc, err := meter.Int64ObservableGauge(
+		"process.runtime.go.mem.heap_alloc",
+		metric.WithDescription("Bytes of allocated heap objects"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	gcCount, err := meter.Int64ObservableGauge(
+		"process.runtime.go.gc.count",
+		metric.WithDescription("Number of completed garbage collection cycles"),
+		metric.WithUnit("{gc}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, obs metric.Observer) error {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		obs.ObserveInt64(goroutines, int64(runtime.NumGoroutine()))
+		obs.ObserveInt64(heapAlloc, int64(stats.HeapAlloc))
+		obs.ObserveInt64(gcCount, int64(stats.NumGC))
+		return nil
+	}, goroutines, heapAlloc, gcCount)
+	return err
+}
@@ -0,0 +1,93 @@
+package rlm
+
+import (
+	"testing"
+)
+
+func TestRecordIteration_MetricsDisabled(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{})
+	defer obs.Shutdown()
+
+	// Must not panic when the Meter was never built.
+	obs.RecordIteration(2)
+	obs.RecordParsingRetries(2, 1)
+	obs.RecordMetaAgentOptimization()
+	obs.RecordToolCall("search_context")
+}
+
+func TestRecordIteration_MetricsEnabled(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{MetricsEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	obs.RecordIteration(1)
+	obs.RecordParsingRetries(1, 2)
+	obs.RecordMetaAgentOptimization()
+	obs.RecordToolCall("search_context")
+
+	rm, err := obs.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics() error = %v", err)
+	}
+	if rm == nil {
+		t.Fatal("expected recorded metrics, got none")
+	}
+
+	seen := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			seen[m.Name] = true
+		}
+	}
+	for _, name := range []string{
+		"rlm.iterations",
+		"rlm.depth",
+		"rlm.parsing.retries",
+		"rlm.meta_agent.optimizations",
+		"rlm.tool.calls",
+	} {
+		if !seen[name] {
+			t.Errorf("expected %s to be recorded, got %v", name, seen)
+		}
+	}
+}
+
+func TestRecordParsingRetries_ZeroIsNoop(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{MetricsEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	obs.RecordParsingRetries(0, 0)
+
+	rm, err := obs.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics() error = %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "rlm.parsing.retries" {
+				t.Error("expected rlm.parsing.retries not to be recorded for retries=0")
+			}
+		}
+	}
+}
+
+func TestRegisterRuntimeMetrics(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{MetricsEnabled: true, RuntimeMetrics: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	rm, err := obs.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics() error = %v", err)
+	}
+
+	var sawGoroutines bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "process.runtime.go.goroutines" {
+				sawGoroutines = true
+			}
+		}
+	}
+	if !sawGoroutines {
+		t.Error("expected process.runtime.go.goroutines to be registered when RuntimeMetrics is set")
+	}
+}
@@ -0,0 +1,31 @@
+package rlm
+
+import "time"
+
+// MetricsObserver receives low-level lifecycle hooks from the recursion
+// loop, independent of the trace/log Observer. It lets operators wire up a
+// metrics backend (Prometheus, StatsD, ...) without touching every call
+// site; see the rlm/prometheus subpackage for a ready-made Prometheus
+// implementation.
+type MetricsObserver interface {
+	// OnIteration fires once per REPL iteration, before the LLM call.
+	OnIteration(depth int, iter int)
+	// OnLLMCall fires after every LLM call completes, success or failure.
+	OnLLMCall(depth int, model string, tokensIn int, tokensOut int, latency time.Duration)
+	// OnREPLExec fires after a REPL execution completes.
+	OnREPLExec(depth int, ok bool, latency time.Duration)
+	// OnRecurse fires when a recursive_llm() call is about to start a sub-RLM.
+	OnRecurse(parentDepth int)
+	// OnFinal fires when a FINAL() answer is accepted.
+	OnFinal(depth int, retries int)
+}
+
+// noopMetricsObserver discards every hook. It's the default when
+// Config.MetricsObserver isn't set, so call sites never need a nil check.
+type noopMetricsObserver struct{}
+
+func (noopMetricsObserver) OnIteration(int, int)                           {}
+func (noopMetricsObserver) OnLLMCall(int, string, int, int, time.Duration) {}
+func (noopMetricsObserver) OnREPLExec(int, bool, time.Duration)            {}
+func (noopMetricsObserver) OnRecurse(int)                                  {}
+func (noopMetricsObserver) OnFinal(int, int)                               {}
@@ -0,0 +1,46 @@
+package rlm
+
+import (
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	iterations int
+	llmCalls   int
+	recurses   int
+	finals     int
+}
+
+func (m *recordingMetrics) OnIteration(int, int)                           { m.iterations++ }
+func (m *recordingMetrics) OnLLMCall(int, string, int, int, time.Duration) { m.llmCalls++ }
+func (m *recordingMetrics) OnREPLExec(int, bool, time.Duration)            {}
+func (m *recordingMetrics) OnRecurse(int)                                  { m.recurses++ }
+func (m *recordingMetrics) OnFinal(int, int)                               { m.finals++ }
+
+func TestNoopMetricsObserverDoesNotPanic(t *testing.T) {
+	var m MetricsObserver = noopMetricsObserver{}
+	m.OnIteration(0, 1)
+	m.OnLLMCall(0, "gpt-4o", 10, 5, time.Millisecond)
+	m.OnREPLExec(0, true, time.Millisecond)
+	m.OnRecurse(0)
+	m.OnFinal(0, 0)
+}
+
+func TestNewDefaultsToNoopMetrics(t *testing.T) {
+	r := New("gpt-4o", Config{MaxDepth: 5, MaxIterations: 30})
+	if r.metrics == nil {
+		t.Fatal("New() should default metrics to a non-nil observer")
+	}
+	if _, ok := r.metrics.(noopMetricsObserver); !ok {
+		t.Errorf("New() metrics = %T, want noopMetricsObserver", r.metrics)
+	}
+}
+
+func TestNewUsesConfiguredMetrics(t *testing.T) {
+	rec := &recordingMetrics{}
+	r := New("gpt-4o", Config{MaxDepth: 5, MaxIterations: 30, MetricsObserver: rec})
+	if r.metrics != MetricsObserver(rec) {
+		t.Fatal("New() should use the configured MetricsObserver")
+	}
+}
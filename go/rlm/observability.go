@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
@@ -13,8 +14,15 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -26,12 +34,80 @@ type ObservabilityConfig struct {
 	// TraceEnabled enables OpenTelemetry tracing
 	TraceEnabled bool `json:"trace_enabled"`
 
-	// TraceEndpoint is the OTLP endpoint for trace export (e.g., "localhost:4317")
+	// TraceEndpoint is the OTLP endpoint for trace export (e.g., "localhost:4317"
+	// for gRPC or "localhost:4318" for HTTP). Falls back to
+	// OTEL_EXPORTER_OTLP_ENDPOINT if unset.
 	TraceEndpoint string `json:"trace_endpoint,omitempty"`
 
+	// Protocol selects the trace exporter: "grpc" (default when TraceEndpoint
+	// is set), "http", or "stdout". Falls back to OTEL_EXPORTER_OTLP_PROTOCOL
+	// if unset; Debug mode always uses "stdout" regardless of this field.
+	Protocol string `json:"protocol,omitempty"`
+
+	// TraceInsecure disables TLS for the OTLP exporter, for talking to a
+	// local collector over plaintext. Has no effect on the stdout protocol.
+	TraceInsecure bool `json:"trace_insecure,omitempty"`
+
+	// TraceHeaders are extra headers sent with every OTLP export request
+	// (e.g. an "Authorization" bearer token for a hosted collector).
+	TraceHeaders map[string]string `json:"trace_headers,omitempty"`
+
+	// TraceCompression is the OTLP transport compression, "gzip" or ""
+	// (no compression).
+	TraceCompression string `json:"trace_compression,omitempty"`
+
 	// ServiceName is the service name for traces (default: "rlm")
 	ServiceName string `json:"service_name,omitempty"`
 
+	// ServiceVersion is the service.version resource attribute attached to
+	// every trace, left unset if empty.
+	ServiceVersion string `json:"service_version,omitempty"`
+
+	// MetricsEnabled enables the companion OTel Meter that records
+	// gen_ai.client.token.usage and gen_ai.client.operation.duration
+	// measurements alongside every LLMCall, plus the broader rlm.* runtime
+	// counters/histograms described on MetricsExporter. Independent of
+	// TraceEnabled, so a caller can have one signal without the other.
+	MetricsEnabled bool `json:"metrics_enabled,omitempty"`
+
+	// MetricsExporter selects how the Meter's measurements leave the
+	// process. "" (default) keeps them in-process only, polled via
+	// CollectMetrics - the existing behavior. "prometheus" additionally
+	// exposes them at a pull-based endpoint via Observer.PrometheusHandler.
+	// "otlp" additionally pushes them to an OTLP collector on a timer (see
+	// MetricsEndpoint/MetricsProtocol). CollectMetrics keeps working in
+	// every mode, since a ManualReader is always registered alongside
+	// whichever export reader MetricsExporter adds.
+	MetricsExporter string `json:"metrics_exporter,omitempty"`
+
+	// MetricsEndpoint is the OTLP endpoint metrics are pushed to when
+	// MetricsExporter is "otlp", independent of TraceEndpoint so traces and
+	// metrics can go to different collectors. Falls back to TraceEndpoint,
+	// then OTEL_EXPORTER_OTLP_ENDPOINT, if unset.
+	MetricsEndpoint string `json:"metrics_endpoint,omitempty"`
+
+	// MetricsProtocol selects the OTLP metrics exporter: "grpc" (default) or
+	// "http". Independent of Protocol, the trace exporter's protocol. Falls
+	// back to OTEL_EXPORTER_OTLP_METRICS_PROTOCOL if unset.
+	MetricsProtocol string `json:"metrics_protocol,omitempty"`
+
+	// RuntimeMetrics additionally registers Go runtime instruments
+	// (goroutine count, heap bytes, GC count) on the Meter alongside the
+	// rlm.* measurements, so operators get end-to-end SLOs without standing
+	// up separate runtime/metrics plumbing.
+	RuntimeMetrics bool `json:"runtime_metrics,omitempty"`
+
+	// CaptureContent gates whether LLMCall attaches the prompt/completion
+	// text to its span as gen_ai.content.prompt/gen_ai.content.completion
+	// events. Off by default since prompts and completions routinely carry
+	// PII or proprietary data a caller may not want in their trace backend.
+	CaptureContent bool `json:"capture_content,omitempty"`
+
+	// PriceTable maps a model name to its per-million-token USD pricing,
+	// used by CalculateCost to estimate LLMCall's dollar cost. A model with
+	// no entry costs 0.
+	PriceTable map[string]ModelPricing `json:"price_table,omitempty"`
+
 	// LogOutput controls where debug logs are written ("stderr", "stdout", or a file path)
 	LogOutput string `json:"log_output,omitempty"`
 
@@ -61,6 +137,12 @@ type ObservabilityEvent struct {
 	TraceID    string            `json:"trace_id,omitempty"`
 	SpanID     string            `json:"span_id,omitempty"`
 	ParentID   string            `json:"parent_id,omitempty"`
+	// Seq is a monotonically increasing sequence number assigned when the
+	// event is recorded, so an NDJSON-persisted stream (see
+	// WriteEventsNDJSON/ReadEventsNDJSON in replay.go) keeps a deterministic
+	// order on replay even across events with identical or clock-skewed
+	// timestamps.
+	Seq uint64 `json:"seq"`
 }
 
 // Observer manages observability for an RLM instance.
@@ -69,10 +151,19 @@ type Observer struct {
 	tracer   trace.Tracer
 	logger   *log.Logger
 	events   []ObservabilityEvent
+	seq      uint64
 	mu       sync.Mutex
 	provider *sdktrace.TracerProvider
 	rootCtx  context.Context
 	rootSpan trace.Span
+	genAI    *genAIMeter
+	rlm      *rlmMeter
+	langfuse *langfuseClient
+
+	// meterProvider and meterReader back both genAI and rlm above, plus
+	// whatever export reader MetricsExporter adds. See setupMeters.
+	meterProvider *sdkmetric.MeterProvider
+	meterReader   *sdkmetric.ManualReader
 }
 
 // NewObserver creates a new Observer with the given configuration.
@@ -90,6 +181,23 @@ func NewObserver(config ObservabilityConfig) *Observer {
 		obs.setupTracer()
 	}
 
+	// Setup the companion Meter (GenAI + rlm.* instruments) if enabled
+	if config.MetricsEnabled {
+		serviceName := config.ServiceName
+		if serviceName == "" {
+			serviceName = "rlm"
+		}
+		obs.setupMeters(serviceName, config.ServiceVersion)
+	}
+
+	// Setup the Langfuse ingestion client if enabled. Basic auth needs both
+	// keys, so a LangfuseEnabled flag left on from before this client
+	// existed (when it was a no-op) doesn't suddenly start background HTTP
+	// calls with empty credentials.
+	if config.LangfuseEnabled && config.LangfusePublicKey != "" && config.LangfuseSecretKey != "" {
+		obs.langfuse = newLangfuseClient(config, obs.logger)
+	}
+
 	return obs
 }
 
@@ -127,54 +235,238 @@ func (o *Observer) setupLogger() {
 }
 
 func (o *Observer) setupTracer() {
-	var exporter sdktrace.SpanExporter
-	var err error
-
-	// Use stdout exporter for debug mode, OTLP for production
-	if o.config.Debug || o.config.TraceEndpoint == "" {
-		exporter, err = stdouttrace.New(
-			stdouttrace.WithPrettyPrint(),
-		)
-	} else {
-		// For OTLP endpoint, fall back to stdout for now
-		// Users can configure OTEL_EXPORTER_OTLP_ENDPOINT env var
-		// and use the OTEL SDK's auto-configuration
-		exporter, err = stdouttrace.New(
-			stdouttrace.WithPrettyPrint(),
-		)
+	serviceName := o.config.ServiceName
+	if serviceName == "" {
+		serviceName = "rlm"
 	}
 
+	exporter, err := o.newTraceExporter()
 	if err != nil {
 		o.logger.Printf("Failed to create trace exporter: %v", err)
 		return
 	}
 
-	serviceName := o.config.ServiceName
-	if serviceName == "" {
-		serviceName = "rlm"
+	res, err := newTraceResource(serviceName, o.config.ServiceVersion)
+	if err != nil {
+		o.logger.Printf("Failed to build trace resource: %v", err)
+		res = resource.Default()
 	}
 
 	o.provider = sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
 	)
 
 	otel.SetTracerProvider(o.provider)
+	// W3C tracecontext propagation lets outbound LLM/tool HTTP requests
+	// carry traceparent/tracestate headers (see injectTraceContext), so a
+	// backend LLM proxy or tool server can join this trace instead of
+	// starting its own disconnected one.
+	otel.SetTextMapPropagator(propagation.TraceContext{})
 	o.tracer = o.provider.Tracer(serviceName)
 }
 
-// StartTrace begins a new root trace for an RLM operation.
-func (o *Observer) StartTrace(name string, attrs map[string]string) context.Context {
+// newTraceExporter builds the SpanExporter for the configured protocol. Debug
+// mode always uses the human-readable stdout exporter regardless of
+// Protocol/TraceEndpoint, since it exists for local inspection, not
+// production export.
+func (o *Observer) newTraceExporter() (sdktrace.SpanExporter, error) {
+	if o.config.Debug {
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+
+	protocol := o.config.Protocol
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	if protocol == "" {
+		if o.config.TraceEndpoint == "" {
+			protocol = "stdout"
+		} else {
+			protocol = "grpc"
+		}
+	}
+
+	if o.config.TraceCompression != "" && o.config.TraceCompression != "gzip" {
+		return nil, fmt.Errorf("rlm: unsupported trace compression %q (only \"gzip\" or \"\" is supported)", o.config.TraceCompression)
+	}
+
+	switch protocol {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "http", "http/protobuf", "http/json":
+		// "http/protobuf" and "http/json" are the values the OTel spec's
+		// OTEL_EXPORTER_OTLP_PROTOCOL env var actually uses; "http" is this
+		// package's own shorthand for the same exporter.
+		return o.newOTLPHTTPExporter()
+	case "grpc":
+		return o.newOTLPGRPCExporter()
+	default:
+		return nil, fmt.Errorf("rlm: unsupported trace protocol %q (want \"grpc\", \"http\", \"http/protobuf\", \"http/json\", or \"stdout\")", protocol)
+	}
+}
+
+// otlpRetryConfig is the exponential-backoff retry policy shared by the gRPC
+// and HTTP exporters. Both vendor their own equivalent of cenkalti/backoff
+// internally behind this RetryConfig shape, so there's no separate backoff
+// dependency to wire in here.
+var otlpRetryConfig = struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}{
+	Enabled:         true,
+	InitialInterval: 1 * time.Second,
+	MaxInterval:     30 * time.Second,
+	MaxElapsedTime:  2 * time.Minute,
+}
+
+func (o *Observer) newOTLPGRPCExporter() (sdktrace.SpanExporter, error) {
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(o.config.TraceEndpoint),
+		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(otlpRetryConfig)),
+	}
+	if o.config.TraceInsecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(o.config.TraceHeaders) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(o.config.TraceHeaders))
+	}
+	if o.config.TraceCompression == "gzip" {
+		opts = append(opts, otlptracegrpc.WithCompressor(o.config.TraceCompression))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+}
+
+func (o *Observer) newOTLPHTTPExporter() (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(o.config.TraceEndpoint),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig(otlpRetryConfig)),
+	}
+	if o.config.TraceInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(o.config.TraceHeaders) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(o.config.TraceHeaders))
+	}
+	if o.config.TraceCompression == "gzip" {
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return otlptrace.New(ctx, otlptracehttp.NewClient(opts...))
+}
+
+// newTraceResource builds the OTEL resource attached to every span: the
+// service name/version plus ambient host/process attributes picked up by the
+// SDK's default detectors, merged on top of resource.Default() so the
+// telemetry.sdk.* attributes and OTEL_RESOURCE_ATTRIBUTES support it carries
+// aren't lost - sdktrace.WithResource merges its argument with
+// resource.Environment() only, not resource.Default(), so that merge has to
+// happen here instead.
+func newTraceResource(serviceName, serviceVersion string) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	if serviceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(serviceVersion))
+	}
+
+	custom, err := resource.New(context.Background(),
+		resource.WithHost(),
+		resource.WithOS(),
+		resource.WithProcessPID(),
+		resource.WithAttributes(attrs...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.Merge(resource.Default(), custom)
+}
+
+// setupMeters builds the shared MeterProvider backing both genAIMeter (the
+// GenAI token-usage/operation-duration histograms) and rlmMeter (the
+// broader rlm.iterations/depth/llm.calls/... counters), plus Go runtime
+// instruments when RuntimeMetrics is set. A ManualReader is always
+// registered, independent of MetricsExporter, so CollectMetrics keeps
+// working (and backs the Prometheus handler's pull-based scrape) even when
+// an export reader is also pushing the same measurements to a collector.
+func (o *Observer) setupMeters(serviceName, serviceVersion string) {
+	res, err := newTraceResource(serviceName, serviceVersion)
+	if err != nil {
+		o.logger.Printf("Failed to build meter resource: %v", err)
+		res = nil
+	}
+
+	manualReader := sdkmetric.NewManualReader()
+	opts := []sdkmetric.Option{sdkmetric.WithReader(manualReader)}
+	if res != nil {
+		opts = append(opts, sdkmetric.WithResource(res))
+	}
+
+	switch o.config.MetricsExporter {
+	case "", "otlp", "prometheus":
+		// "" keeps measurements in-process only; "prometheus" is handled by
+		// PrometheusHandler reading the same ManualReader, not a reader here.
+	default:
+		o.logger.Printf("Unrecognized MetricsExporter %q (want \"\", \"otlp\", or \"prometheus\"); metrics will stay in-process only", o.config.MetricsExporter)
+	}
+
+	if o.config.MetricsExporter == "otlp" {
+		exportReader, err := o.newOTLPMetricReader()
+		if err != nil {
+			o.logger.Printf("Failed to create OTLP metric reader: %v", err)
+		} else {
+			opts = append(opts, sdkmetric.WithReader(exportReader))
+		}
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
+	meter := provider.Meter(serviceName)
+
+	o.meterProvider = provider
+	o.meterReader = manualReader
+	o.genAI = buildGenAIMeter(meter, o.logger)
+	o.rlm = buildRLMMeter(meter, o.logger)
+
+	if o.config.RuntimeMetrics {
+		if err := registerRuntimeMetrics(meter); err != nil {
+			o.logger.Printf("Failed to register runtime metrics: %v", err)
+		}
+	}
+}
+
+// PrometheusHandler returns an http.Handler serving the Meter's
+// measurements in the Prometheus text exposition format, suitable for
+// mounting at "/metrics". It's non-nil only when MetricsEnabled and
+// MetricsExporter are both set ("" and "otlp" don't expose a pull
+// endpoint); callers should check for nil before mounting it.
+func (o *Observer) PrometheusHandler() http.Handler {
+	if o.meterReader == nil || o.config.MetricsExporter != "prometheus" {
+		return nil
+	}
+	return newPrometheusHandler(o.meterReader)
+}
+
+// StartTrace begins a new root trace for an RLM operation, parented on ctx
+// when ctx already carries a span (e.g. a recursive_llm call shares its
+// parent's ctx - see RLM.buildREPLEnv) so nested completions join the same
+// trace instead of each starting an unrelated root.
+func (o *Observer) StartTrace(ctx context.Context, name string, attrs map[string]string) context.Context {
 	if o.tracer == nil {
-		o.rootCtx = context.Background()
-		return o.rootCtx
+		o.setRoot(ctx, nil)
+		return ctx
 	}
 
 	otelAttrs := mapToAttributes(attrs)
-	ctx, span := o.tracer.Start(context.Background(), name,
+	ctx, span := o.tracer.Start(ctx, name,
 		trace.WithAttributes(otelAttrs...),
 	)
-	o.rootCtx = ctx
-	o.rootSpan = span
+	o.setRoot(ctx, span)
 
 	o.recordEvent(ObservabilityEvent{
 		Timestamp:  time.Now(),
@@ -188,32 +480,65 @@ func (o *Observer) StartTrace(name string, attrs map[string]string) context.Cont
 	return ctx
 }
 
-// EndTrace ends the root trace.
+// EndTrace ends the span ctx carries (as started by StartTrace) and, if this
+// Observer owns a TracerProvider, force-flushes it so the trace is exported
+// before the caller returns.
 func (o *Observer) EndTrace(ctx context.Context) {
-	if o.rootSpan != nil {
-		o.rootSpan.End()
+	if span := trace.SpanFromContext(ctx); span != nil {
+		span.End()
 	}
 	if o.provider != nil {
 		_ = o.provider.ForceFlush(context.Background())
 	}
 }
 
-// StartSpan begins a new child span.
-func (o *Observer) StartSpan(name string, attrs map[string]string) context.Context {
+// SpanHandle is the child-span handle returned by StartSpan. Call End() when
+// the operation it covers completes - typically via defer, right after
+// StartSpan, matching the pattern StartTrace/EndTrace already established
+// for the root span.
+type SpanHandle struct {
+	span trace.Span
+	obs  *Observer
+}
+
+// End ends the span and records its "span_end" observability event. A zero
+// SpanHandle (returned when tracing is disabled) is a safe no-op.
+func (h SpanHandle) End() {
+	if h.span == nil {
+		return
+	}
+	h.span.End()
+
+	if h.obs != nil && h.span.SpanContext().IsValid() {
+		sc := h.span.SpanContext()
+		h.obs.recordEvent(ObservabilityEvent{
+			Timestamp: time.Now(),
+			Type:      "span_end",
+			TraceID:   sc.TraceID().String(),
+			SpanID:    sc.SpanID().String(),
+		})
+	}
+}
+
+// StartSpan begins a new child span parented on ctx - the caller's own ctx,
+// not a field on Observer - so that concurrent callers sharing one Observer
+// (e.g. nested recursive_llm calls, which share their parent RLM's observer
+// for trace continuity) each get correctly nested spans instead of racing to
+// overwrite a single shared "current span" pointer. Returns the span-bearing
+// context to pass down to whatever the span covers, plus a SpanHandle to End
+// it with.
+func (o *Observer) StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, SpanHandle) {
 	if o.tracer == nil {
-		if o.rootCtx == nil {
-			o.rootCtx = context.Background()
-		}
-		return o.rootCtx
+		return ctx, SpanHandle{}
 	}
 
-	parentCtx := o.rootCtx
-	if parentCtx == nil {
-		parentCtx = context.Background()
+	var parentID string
+	if parent := trace.SpanFromContext(ctx); parent != nil && parent.SpanContext().IsValid() {
+		parentID = parent.SpanContext().SpanID().String()
 	}
 
 	otelAttrs := mapToAttributes(attrs)
-	ctx, span := o.tracer.Start(parentCtx, name,
+	spanCtx, span := o.tracer.Start(ctx, name,
 		trace.WithAttributes(otelAttrs...),
 	)
 
@@ -224,52 +549,103 @@ func (o *Observer) StartSpan(name string, attrs map[string]string) context.Conte
 		Attributes: attrs,
 		TraceID:    span.SpanContext().TraceID().String(),
 		SpanID:     span.SpanContext().SpanID().String(),
+		ParentID:   parentID,
 	})
 
-	return ctx
+	return spanCtx, SpanHandle{span: span, obs: o}
 }
 
-// EndSpan ends a child span.
-func (o *Observer) EndSpan(ctx context.Context) {
-	span := trace.SpanFromContext(ctx)
-	if span != nil {
-		span.End()
-	}
-}
-
-// LLMCall records an LLM API call event.
-func (o *Observer) LLMCall(model string, messageCount int, tokensUsed int, duration time.Duration, err error) {
+// LLMCall records an LLM API call: a debug log line, an "llm.call" span
+// tagged with GenAI semantic-convention attributes (gen_ai.system,
+// gen_ai.request.model, gen_ai.usage.*), gen_ai.client.token.usage/
+// gen_ai.client.operation.duration and rlm.llm.calls/rlm.llm.duration
+// measurements on the companion Meter (when MetricsEnabled), and - when
+// CaptureContent is set - prompt/completion span events.
+func (o *Observer) LLMCall(info LLMCallInfo, duration time.Duration, err error) {
 	attrs := map[string]string{
-		"model":         model,
-		"message_count": fmt.Sprintf("%d", messageCount),
-		"tokens_used":   fmt.Sprintf("%d", tokensUsed),
-		"duration_ms":   fmt.Sprintf("%d", duration.Milliseconds()),
+		"model":             info.Model,
+		"message_count":     fmt.Sprintf("%d", info.MessageCount),
+		"tokens_used":       fmt.Sprintf("%d", info.PromptTokens+info.CompletionTokens),
+		"prompt_tokens":     fmt.Sprintf("%d", info.PromptTokens),
+		"completion_tokens": fmt.Sprintf("%d", info.CompletionTokens),
+		"duration_ms":       fmt.Sprintf("%d", duration.Milliseconds()),
 	}
 	if err != nil {
 		attrs["error"] = err.Error()
 	}
+	if o.config.CaptureContent {
+		if info.Prompt != "" {
+			attrs["prompt"] = info.Prompt
+		}
+		if info.Completion != "" {
+			attrs["completion"] = info.Completion
+		}
+	}
 
-	o.Debug("llm_call", "model=%s messages=%d duration=%s", model, messageCount, duration)
+	o.Debug("llm_call", "model=%s messages=%d duration=%s", info.Model, info.MessageCount, duration)
 
-	if o.tracer != nil && o.rootCtx != nil {
-		_, span := o.tracer.Start(o.rootCtx, "llm.call",
-			trace.WithAttributes(mapToAttributes(attrs)...),
+	rootCtx, rootSpan := o.getRoot()
+
+	var traceID, spanID string
+	if o.tracer != nil && rootCtx != nil {
+		_, span := o.tracer.Start(rootCtx, "llm.call",
+			trace.WithAttributes(info.genAIAttributes(o.config.PriceTable)...),
 		)
+		sc := span.SpanContext()
+		traceID, spanID = sc.TraceID().String(), sc.SpanID().String()
+		recordGenAIEvents(span, info, o.config.CaptureContent)
 		if err != nil {
 			span.RecordError(err)
 		}
 		span.End()
 	}
 
+	o.recordGenAIMetrics(info, duration.Seconds())
+	o.recordRLMCallMetrics(info, duration.Seconds())
+
 	o.recordEvent(ObservabilityEvent{
 		Timestamp:  time.Now(),
 		Type:       "llm_call",
-		Name:       fmt.Sprintf("llm.%s", model),
+		Name:       fmt.Sprintf("llm.%s", info.Model),
 		Attributes: attrs,
 		Duration:   duration,
+		TraceID:    traceID,
+		SpanID:     spanID,
+		ParentID:   rootSpanID(rootSpan),
 	})
 }
 
+// setRoot stores the current root trace's ctx/span under o.mu. ctx/span are
+// read concurrently from LLMCall/Error/Event/rootSpanID on whatever
+// goroutine they're called from, and this Observer can be shared across
+// concurrent completions (see RLM.buildREPLEnv's "Share observer for trace
+// continuity" recursive_llm comment), so a plain field write here would race.
+func (o *Observer) setRoot(ctx context.Context, span trace.Span) {
+	o.mu.Lock()
+	o.rootCtx = ctx
+	o.rootSpan = span
+	o.mu.Unlock()
+}
+
+// getRoot returns the current root trace's ctx/span, or (nil, nil) if no
+// trace has been started yet. See setRoot for why this goes through o.mu.
+func (o *Observer) getRoot() (context.Context, trace.Span) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.rootCtx, o.rootSpan
+}
+
+// rootSpanID returns span's ID, or "" if span is nil (tracing disabled, or
+// no trace started yet). LLMCall starts its llm.call span on the root trace's
+// ctx, so this is the ParentID its event records; StartSpan instead records
+// the span carried by the ctx its caller passes in.
+func rootSpanID(span trace.Span) string {
+	if span == nil {
+		return ""
+	}
+	return span.SpanContext().SpanID().String()
+}
+
 // Debug logs a debug message if debug mode is enabled.
 func (o *Observer) Debug(component string, format string, args ...interface{}) {
 	if !o.config.Debug {
@@ -286,6 +662,15 @@ func (o *Observer) Error(component string, format string, args ...interface{}) {
 		o.logger.Printf("[ERROR][%s] %s", component, msg)
 	}
 
+	rootCtx, _ := o.getRoot()
+
+	var traceID string
+	if o.tracer != nil && rootCtx != nil {
+		if span := trace.SpanFromContext(rootCtx); span != nil {
+			traceID = span.SpanContext().TraceID().String()
+		}
+	}
+
 	o.recordEvent(ObservabilityEvent{
 		Timestamp: time.Now(),
 		Type:      "error",
@@ -293,6 +678,7 @@ func (o *Observer) Error(component string, format string, args ...interface{}) {
 		Attributes: map[string]string{
 			"message": msg,
 		},
+		TraceID: traceID,
 	})
 }
 
@@ -300,10 +686,14 @@ func (o *Observer) Error(component string, format string, args ...interface{}) {
 func (o *Observer) Event(name string, attrs map[string]string) {
 	o.Debug("event", "%s: %v", name, attrs)
 
-	if o.tracer != nil && o.rootCtx != nil {
-		span := trace.SpanFromContext(o.rootCtx)
+	rootCtx, _ := o.getRoot()
+
+	var traceID string
+	if o.tracer != nil && rootCtx != nil {
+		span := trace.SpanFromContext(rootCtx)
 		if span != nil {
 			span.AddEvent(name, trace.WithAttributes(mapToAttributes(attrs)...))
+			traceID = span.SpanContext().TraceID().String()
 		}
 	}
 
@@ -312,6 +702,7 @@ func (o *Observer) Event(name string, attrs map[string]string) {
 		Type:       "event",
 		Name:       name,
 		Attributes: attrs,
+		TraceID:    traceID,
 	})
 }
 
@@ -341,10 +732,20 @@ func (o *Observer) Shutdown() {
 		defer cancel()
 		_ = o.provider.Shutdown(ctx)
 	}
+	if o.meterProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = o.meterProvider.Shutdown(ctx)
+	}
+	if o.langfuse != nil {
+		o.langfuse.shutdown()
+	}
 }
 
 func (o *Observer) recordEvent(event ObservabilityEvent) {
 	o.mu.Lock()
+	o.seq++
+	event.Seq = o.seq
 	o.events = append(o.events, event)
 	o.mu.Unlock()
 
@@ -359,12 +760,26 @@ func (o *Observer) recordEvent(event ObservabilityEvent) {
 	}
 }
 
+// sendToLangfuse hands event to the background Langfuse ingestion client,
+// which maps it onto the Langfuse API shape and batches it for delivery.
+// See langfuse.go for the mapping and transport.
 func (o *Observer) sendToLangfuse(event ObservabilityEvent) {
-	// Langfuse integration - events are collected and can be sent via the
-	// Langfuse API. This is a lightweight integration that records trace data
-	// in a Langfuse-compatible format. For full Langfuse integration, users
-	// should use the Langfuse SDK directly with the events from GetEvents().
-	o.Debug("langfuse", "Event: %s/%s", event.Type, event.Name)
+	if o.langfuse == nil {
+		return
+	}
+	o.langfuse.ingest(event)
+}
+
+// injectTraceContext writes ctx's active span as W3C traceparent/tracestate
+// headers onto an outbound HTTP request (see openai.go, anthropic.go,
+// gemini.go, ollama.go, and tools.go's http_fetch tool), so a downstream LLM
+// backend or tool server can join the same distributed trace. A no-op
+// unless setupTracer registered propagation.TraceContext{} as the active
+// TextMapPropagator - otel.GetTextMapPropagator() defaults to a no-op
+// propagator that writes nothing, so this is safe to call unconditionally
+// regardless of whether tracing is enabled.
+func injectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
 }
 
 // mapToAttributes converts a map to OTEL attributes.
@@ -392,9 +807,64 @@ func ObservabilityConfigFromMap(config map[string]interface{}) ObservabilityConf
 	if v, ok := config["trace_endpoint"].(string); ok {
 		obs.TraceEndpoint = v
 	}
+	if v, ok := config["protocol"].(string); ok {
+		obs.Protocol = v
+	}
+	if v, ok := config["trace_insecure"].(bool); ok {
+		obs.TraceInsecure = v
+	}
+	if v, ok := config["trace_headers"].(map[string]interface{}); ok {
+		obs.TraceHeaders = make(map[string]string, len(v))
+		for k, headerValue := range v {
+			if s, ok := headerValue.(string); ok {
+				obs.TraceHeaders[k] = s
+			}
+		}
+	}
+	if v, ok := config["trace_compression"].(string); ok {
+		obs.TraceCompression = v
+	}
 	if v, ok := config["service_name"].(string); ok {
 		obs.ServiceName = v
 	}
+	if v, ok := config["service_version"].(string); ok {
+		obs.ServiceVersion = v
+	}
+	if v, ok := config["metrics_enabled"].(bool); ok {
+		obs.MetricsEnabled = v
+	}
+	if v, ok := config["metrics_exporter"].(string); ok {
+		obs.MetricsExporter = v
+	}
+	if v, ok := config["metrics_endpoint"].(string); ok {
+		obs.MetricsEndpoint = v
+	}
+	if v, ok := config["metrics_protocol"].(string); ok {
+		obs.MetricsProtocol = v
+	}
+	if v, ok := config["runtime_metrics"].(bool); ok {
+		obs.RuntimeMetrics = v
+	}
+	if v, ok := config["capture_content"].(bool); ok {
+		obs.CaptureContent = v
+	}
+	if v, ok := config["price_table"].(map[string]interface{}); ok {
+		obs.PriceTable = make(map[string]ModelPricing, len(v))
+		for model, entryRaw := range v {
+			entry, ok := entryRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var pricing ModelPricing
+			if p, ok := entry["prompt_per_million"].(float64); ok {
+				pricing.PromptPerMillion = p
+			}
+			if p, ok := entry["completion_per_million"].(float64); ok {
+				pricing.CompletionPerMillion = p
+			}
+			obs.PriceTable[model] = pricing
+		}
+	}
 	if v, ok := config["log_output"].(string); ok {
 		obs.LogOutput = v
 	}
@@ -419,6 +889,9 @@ func ObservabilityConfigFromMap(config map[string]interface{}) ObservabilityConf
 		obs.TraceEnabled = true
 		obs.TraceEndpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
 	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); v != "" && obs.Protocol == "" {
+		obs.Protocol = v
+	}
 	if os.Getenv("LANGFUSE_PUBLIC_KEY") != "" {
 		obs.LangfuseEnabled = true
 		obs.LangfusePublicKey = os.Getenv("LANGFUSE_PUBLIC_KEY")
@@ -460,7 +933,11 @@ func ExtractObservabilityConfig(config map[string]interface{}) map[string]interf
 	obsConfig := make(map[string]interface{})
 
 	obsKeys := []string{
-		"debug", "trace_enabled", "trace_endpoint", "service_name",
+		"debug", "trace_enabled", "trace_endpoint", "protocol",
+		"trace_insecure", "trace_headers", "trace_compression",
+		"service_name", "service_version",
+		"metrics_enabled", "metrics_exporter", "metrics_endpoint", "metrics_protocol",
+		"runtime_metrics", "capture_content", "price_table",
 		"log_output", "langfuse_enabled", "langfuse_public_key",
 		"langfuse_secret_key", "langfuse_host",
 	}
@@ -1,9 +1,14 @@
 package rlm
 
 import (
+	"context"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func TestNewObserver(t *testing.T) {
@@ -39,12 +44,12 @@ func TestNewNoopObserver(t *testing.T) {
 	}
 
 	// Should not panic with any operations
-	ctx := obs.StartTrace("test", nil)
+	ctx := obs.StartTrace(context.Background(), "test", nil)
 	obs.EndTrace(ctx)
 	obs.Debug("test", "message %s", "arg")
 	obs.Error("test", "error %s", "arg")
 	obs.Event("test", map[string]string{"key": "value"})
-	obs.LLMCall("model", 1, 0, time.Second, nil)
+	obs.LLMCall(LLMCallInfo{Model: "model", MessageCount: 1}, time.Second, nil)
 }
 
 func TestObserverEvents(t *testing.T) {
@@ -87,7 +92,7 @@ func TestObserverEventsJSON(t *testing.T) {
 func TestObserverLLMCall(t *testing.T) {
 	obs := NewObserver(ObservabilityConfig{Debug: true})
 
-	obs.LLMCall("gpt-4o-mini", 3, 150, 2*time.Second, nil)
+	obs.LLMCall(LLMCallInfo{Model: "gpt-4o-mini", MessageCount: 3, CompletionTokens: 150}, 2*time.Second, nil)
 
 	events := obs.GetEvents()
 	if len(events) != 1 {
@@ -133,9 +138,9 @@ func TestObserverSpans(t *testing.T) {
 	})
 	defer obs.Shutdown()
 
-	traceCtx := obs.StartTrace("root", map[string]string{"op": "test"})
-	spanCtx := obs.StartSpan("child", map[string]string{"step": "1"})
-	obs.EndSpan(spanCtx)
+	traceCtx := obs.StartTrace(context.Background(), "root", map[string]string{"op": "test"})
+	_, span := obs.StartSpan(traceCtx, "child", map[string]string{"step": "1"})
+	span.End()
 	obs.EndTrace(traceCtx)
 
 	events := obs.GetEvents()
@@ -145,6 +150,81 @@ func TestObserverSpans(t *testing.T) {
 	}
 }
 
+// TestObserverSpans_ParentsOnCallerCtx guards against StartSpan regressing
+// to a single Observer-wide "current span" field: two concurrent child
+// spans parented on two different ctxs must each record the right
+// ParentID, not whichever span happened to start last.
+func TestObserverSpans_ParentsOnCallerCtx(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{TraceEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	rootACtx := obs.StartTrace(context.Background(), "rootA", nil)
+	rootBCtx := obs.StartTrace(context.Background(), "rootB", nil)
+
+	_, spanA := obs.StartSpan(rootACtx, "childA", nil)
+	_, spanB := obs.StartSpan(rootBCtx, "childB", nil)
+	spanA.End()
+	spanB.End()
+	obs.EndTrace(rootACtx)
+	obs.EndTrace(rootBCtx)
+
+	var parentOfA, parentOfB, rootASpanID, rootBSpanID string
+	for _, event := range obs.GetEvents() {
+		switch event.Name {
+		case "rootA":
+			rootASpanID = event.SpanID
+		case "rootB":
+			rootBSpanID = event.SpanID
+		case "childA":
+			parentOfA = event.ParentID
+		case "childB":
+			parentOfB = event.ParentID
+		}
+	}
+
+	if parentOfA != rootASpanID {
+		t.Errorf("childA.ParentID = %q, want rootA's span ID %q", parentOfA, rootASpanID)
+	}
+	if parentOfB != rootBSpanID {
+		t.Errorf("childB.ParentID = %q, want rootB's span ID %q", parentOfB, rootBSpanID)
+	}
+}
+
+// otel.SetTextMapPropagator is process-global state (set by setupTracer, not
+// scoped to an Observer), so these two cases pin and restore it explicitly
+// rather than relying on whichever other test in this package happened to
+// run first.
+func TestInjectTraceContext_WritesTraceparentWhenTracingEnabled(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	defer otel.SetTextMapPropagator(prev)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	obs := NewObserver(ObservabilityConfig{TraceEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	ctx := obs.StartTrace(context.Background(), "root", nil)
+
+	header := http.Header{}
+	injectTraceContext(ctx, header)
+
+	if header.Get("traceparent") == "" {
+		t.Error("expected injectTraceContext to set a traceparent header once tracing is enabled")
+	}
+}
+
+func TestInjectTraceContext_NoopWithoutPropagator(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	defer otel.SetTextMapPropagator(prev)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	header := http.Header{}
+	injectTraceContext(context.Background(), header)
+
+	if header.Get("traceparent") != "" {
+		t.Errorf("expected no traceparent header without tracing enabled, got %q", header.Get("traceparent"))
+	}
+}
+
 func TestObservabilityConfigFromMap(t *testing.T) {
 	config := map[string]interface{}{
 		"debug":         true,
@@ -169,6 +249,72 @@ func TestObservabilityConfigFromMap(t *testing.T) {
 	}
 }
 
+func TestObservabilityConfigFromMap_OTLPFields(t *testing.T) {
+	config := map[string]interface{}{
+		"trace_endpoint":    "collector:4317",
+		"protocol":          "http",
+		"trace_insecure":    true,
+		"trace_compression": "gzip",
+		"service_version":   "1.2.3",
+		"trace_headers": map[string]interface{}{
+			"Authorization": "Bearer secret",
+		},
+	}
+
+	obs := ObservabilityConfigFromMap(config)
+
+	if obs.TraceEndpoint != "collector:4317" {
+		t.Errorf("expected trace_endpoint 'collector:4317', got '%s'", obs.TraceEndpoint)
+	}
+	if obs.Protocol != "http" {
+		t.Errorf("expected protocol 'http', got '%s'", obs.Protocol)
+	}
+	if !obs.TraceInsecure {
+		t.Error("expected trace_insecure to be true")
+	}
+	if obs.TraceCompression != "gzip" {
+		t.Errorf("expected trace_compression 'gzip', got '%s'", obs.TraceCompression)
+	}
+	if obs.ServiceVersion != "1.2.3" {
+		t.Errorf("expected service_version '1.2.3', got '%s'", obs.ServiceVersion)
+	}
+	if obs.TraceHeaders["Authorization"] != "Bearer secret" {
+		t.Errorf("expected trace_headers[Authorization] 'Bearer secret', got '%s'", obs.TraceHeaders["Authorization"])
+	}
+}
+
+func TestObserver_UnsupportedTraceProtocol(t *testing.T) {
+	obs := &Observer{config: ObservabilityConfig{Protocol: "carrier-pigeon"}}
+
+	if _, err := obs.newTraceExporter(); err == nil {
+		t.Error("expected an error for an unsupported trace protocol")
+	}
+}
+
+func TestNewTraceResource(t *testing.T) {
+	res, err := newTraceResource("my-service", "9.9.9")
+	if err != nil {
+		t.Fatalf("newTraceResource() error = %v", err)
+	}
+
+	attrs := res.Attributes()
+	var sawName, sawVersion bool
+	for _, kv := range attrs {
+		switch string(kv.Key) {
+		case "service.name":
+			sawName = kv.Value.AsString() == "my-service"
+		case "service.version":
+			sawVersion = kv.Value.AsString() == "9.9.9"
+		}
+	}
+	if !sawName {
+		t.Error("expected service.name attribute to be set")
+	}
+	if !sawVersion {
+		t.Error("expected service.version attribute to be set")
+	}
+}
+
 func TestObservabilityConfigFromMap_Nil(t *testing.T) {
 	obs := ObservabilityConfigFromMap(nil)
 	if obs.Debug || obs.TraceEnabled {
@@ -178,9 +324,9 @@ func TestObservabilityConfigFromMap_Nil(t *testing.T) {
 
 func TestExtractObservabilityConfig(t *testing.T) {
 	fullConfig := map[string]interface{}{
-		"debug":       true,
-		"model":       "gpt-4o",
-		"api_key":     "key",
+		"debug":        true,
+		"model":        "gpt-4o",
+		"api_key":      "key",
 		"service_name": "rlm-test",
 		"observability": map[string]interface{}{
 			"langfuse_enabled": true,
@@ -0,0 +1,141 @@
+package rlm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jbeck018/recursive-llm-ts/go/grammar"
+)
+
+// ollamaBackend talks to a local Ollama server's /api/chat endpoint, which
+// shares the OpenAI role vocabulary but returns a single non-streamed
+// "message" object rather than a "choices" array.
+type ollamaBackend struct {
+	apiBase string
+}
+
+type ollamaRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Grammar  string    `json:"grammar,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	DoneReason      string `json:"done_reason"`
+	Error           string `json:"error"`
+}
+
+// Chat retries per request.Retry (see withRetry) around a single request/
+// response round trip. Ollama is typically local and has no rate-limit
+// Retry-After header, but the same backoff/retry policy still helps for a
+// server restarting mid-recursion or a transient connection reset.
+func (b *ollamaBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	return withRetry(ctx, request, func(ctx context.Context) (ChatResponse, time.Duration, error) {
+		return b.chatOnce(ctx, request)
+	})
+}
+
+func (b *ollamaBackend) chatOnce(ctx context.Context, request ChatRequest) (ChatResponse, time.Duration, error) {
+	payload := ollamaRequest{
+		Model:    stripProviderPrefix(request.Model),
+		Messages: request.Messages,
+		Stream:   false,
+	}
+	if g, ok := request.ExtraParams["grammar"].(string); ok {
+		payload.Grammar = g
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	injectTraceContext(ctx, req.Header)
+
+	client := defaultHTTPClient
+	if request.Timeout > 0 {
+		client = &http.Client{Timeout: time.Duration(request.Timeout) * time.Second, Transport: defaultHTTPClient.Transport}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return ChatResponse{}, 0, NewAPIError(resp.StatusCode, strings.TrimSpace(string(responseBody)))
+	}
+
+	var parsed ollamaResponse
+	if err := json.Unmarshal(responseBody, &parsed); err != nil {
+		return ChatResponse{}, 0, err
+	}
+
+	if parsed.Error != "" {
+		return ChatResponse{}, 0, errors.New(parsed.Error)
+	}
+
+	if parsed.Message.Content == "" {
+		return ChatResponse{}, 0, errors.New("no message content returned by LLM")
+	}
+
+	content := parsed.Message.Content
+	if parsed.PromptEvalCount > 0 || parsed.EvalCount > 0 {
+		usage := TokenUsage{
+			PromptTokens:     parsed.PromptEvalCount,
+			CompletionTokens: parsed.EvalCount,
+			TotalTokens:      parsed.PromptEvalCount + parsed.EvalCount,
+		}
+		return ChatResponse{Content: content, Usage: usage, FinishReason: parsed.DoneReason}, 0, nil
+	}
+
+	return ChatResponse{Content: content, Usage: estimateUsage(request.Messages, content), FinishReason: parsed.DoneReason}, 0, nil
+}
+
+// BuildGrammar implements ConstrainedDecoder: Ollama's llama.cpp-derived
+// runtime accepts the same GBNF grammar format as a llama.cpp server, passed
+// through the "grammar" field set above rather than an OpenAI-style extra
+// param, so the grammar.FromJSON output can be forwarded as-is.
+func (b *ollamaBackend) BuildGrammar(schema *JSONSchema) (string, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+	return grammar.FromJSON(schemaJSON)
+}
+
+func (b *ollamaBackend) endpoint() string {
+	base := strings.TrimSpace(b.apiBase)
+	if base == "" {
+		base = "http://localhost:11434"
+	}
+	if strings.Contains(base, "/api/chat") {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/api/chat"
+}
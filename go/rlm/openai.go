@@ -1,7 +1,9 @@
 package rlm
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +16,15 @@ import (
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// ToolCalls carries the provider-native tool calls an assistant message
+	// made, so they round-trip back to the provider on the next turn - the
+	// API rejects a "tool" role message whose tool_call_id doesn't match one
+	// of these. Only set in provider-native tool-calling mode (ToolCallCompletion).
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID identifies which ToolCall a "tool" role message is
+	// responding to. Required by the OpenAI wire format on every such
+	// message.
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
 type ChatRequest struct {
@@ -23,14 +34,32 @@ type ChatRequest struct {
 	APIKey      string
 	Timeout     int
 	ExtraParams map[string]interface{}
+	// Tools, when non-empty, advertises provider-native function-calling
+	// tools (OpenAI's "tools" request field) instead of relying on prompt
+	// conventions like TOOL("name", {...}).
+	Tools []ToolDefinition
+	// ToolChoice maps to the OpenAI "tool_choice" field, e.g. "auto",
+	// "required", or "none". Ignored when Tools is empty.
+	ToolChoice string
+	// Retry enables automatic retries of transient failures (429, 5xx,
+	// network timeouts) with exponential backoff. Nil disables retries, so
+	// a single call behaves exactly as it always has.
+	Retry *RetryPolicy
 }
 
 type chatResponse struct {
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content   string     `json:"content"`
+			ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error"`
@@ -48,20 +77,93 @@ var (
 	}
 )
 
+// CallChatCompletion calls the chat completion endpoint using a background
+// context. Prefer CallChatCompletionContext when a caller-supplied deadline
+// or cancellation signal is available.
 func CallChatCompletion(request ChatRequest) (string, error) {
+	return CallChatCompletionContext(context.Background(), request)
+}
+
+// CallChatCompletionContext calls the chat completion endpoint, aborting the
+// request if ctx is canceled or its deadline elapses before a response is
+// received. A request.Timeout still applies as an additional per-call bound.
+func CallChatCompletionContext(ctx context.Context, request ChatRequest) (string, error) {
+	content, _, _, _, err := callOpenAICompatible(ctx, request)
+	return content, err
+}
+
+// callOpenAICompatible issues the request and returns the completion text,
+// its token usage (estimated from message/response length when the
+// provider's response omits a usage block), any provider-native tool calls
+// the model made, and its finish_reason. When request.Retry is set, transient
+// failures (rate limiting, 5xx, network timeouts) are retried with
+// exponential backoff, honoring a Retry-After header over the computed delay.
+func callOpenAICompatible(ctx context.Context, request ChatRequest) (string, TokenUsage, []ToolCall, string, error) {
+	if request.Retry == nil {
+		return callOpenAICompatibleOnce(ctx, request)
+	}
+
+	policy := *request.Retry
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		content, usage, toolCalls, finishReason, retryAfter, err := callOpenAICompatibleOnceWithRetryAfter(ctx, request)
+		if err == nil {
+			return content, usage, toolCalls, finishReason, nil
+		}
+		lastErr = err
+
+		if !isRetryableError(err, policy) {
+			return "", TokenUsage{}, nil, "", err
+		}
+		if attempt == maxAttempts {
+			break
+		}
+
+		if sleepErr := sleepOrAbort(ctx, retryDelay(attempt-1, retryAfter, policy)); sleepErr != nil {
+			return "", TokenUsage{}, nil, "", wrapCancelled(ctx, sleepErr)
+		}
+	}
+
+	return "", TokenUsage{}, nil, "", NewRetriesExhaustedError(maxAttempts, time.Since(start), lastErr)
+}
+
+// callOpenAICompatibleOnce is callOpenAICompatible's single-attempt
+// implementation, with no retry behavior.
+func callOpenAICompatibleOnce(ctx context.Context, request ChatRequest) (string, TokenUsage, []ToolCall, string, error) {
+	content, usage, toolCalls, finishReason, _, err := callOpenAICompatibleOnceWithRetryAfter(ctx, request)
+	return content, usage, toolCalls, finishReason, err
+}
+
+// callOpenAICompatibleOnceWithRetryAfter is callOpenAICompatibleOnce plus the
+// Retry-After delay parsed off an error response, so the retry loop above can
+// honor it without re-parsing the response itself.
+func callOpenAICompatibleOnceWithRetryAfter(ctx context.Context, request ChatRequest) (string, TokenUsage, []ToolCall, string, time.Duration, error) {
 	endpoint := buildEndpoint(request.APIBase)
 	payload := map[string]interface{}{
 		"model":    request.Model,
 		"messages": request.Messages,
 	}
 
+	if len(request.Tools) > 0 {
+		payload["tools"] = toolsWirePayload(request.Tools)
+		if request.ToolChoice != "" {
+			payload["tool_choice"] = request.ToolChoice
+		}
+	}
+
 	for key, value := range request.ExtraParams {
 		payload[key] = value
 	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, nil, "", 0, err
 	}
 
 	// Use shared client with connection pooling
@@ -74,18 +176,19 @@ func CallChatCompletion(request ChatRequest) (string, error) {
 		}
 	}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, nil, "", 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	if request.APIKey != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", request.APIKey))
 	}
+	injectTraceContext(ctx, req.Header)
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, nil, "", 0, wrapCancelled(ctx, err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -93,27 +196,210 @@ func CallChatCompletion(request ChatRequest) (string, error) {
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", TokenUsage{}, nil, "", 0, wrapCancelled(ctx, err)
 	}
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		return "", NewAPIError(resp.StatusCode, strings.TrimSpace(string(responseBody)))
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		apiErr := NewAPIError(resp.StatusCode, strings.TrimSpace(string(responseBody)))
+		apiErr.RetryAfter = retryAfter
+		return "", TokenUsage{}, nil, "", retryAfter, apiErr
 	}
 
 	var parsed chatResponse
 	if err := json.Unmarshal(responseBody, &parsed); err != nil {
-		return "", err
+		return "", TokenUsage{}, nil, "", 0, err
 	}
 
 	if parsed.Error != nil && parsed.Error.Message != "" {
-		return "", errors.New(parsed.Error.Message)
+		return "", TokenUsage{}, nil, "", 0, errors.New(parsed.Error.Message)
 	}
 
 	if len(parsed.Choices) == 0 {
-		return "", errors.New("no choices returned by LLM")
+		return "", TokenUsage{}, nil, "", 0, errors.New("no choices returned by LLM")
+	}
+
+	content := parsed.Choices[0].Message.Content
+	toolCalls := parsed.Choices[0].Message.ToolCalls
+	finishReason := parsed.Choices[0].FinishReason
+
+	if parsed.Usage != nil {
+		return content, TokenUsage{
+			PromptTokens:     parsed.Usage.PromptTokens,
+			CompletionTokens: parsed.Usage.CompletionTokens,
+			TotalTokens:      parsed.Usage.TotalTokens,
+		}, toolCalls, finishReason, 0, nil
+	}
+
+	return content, estimateUsage(request.Messages, content), toolCalls, finishReason, 0, nil
+}
+
+// streamEvent is one Server-Sent Event payload from an OpenAI-compatible
+// /chat/completions?stream=true endpoint: either an incremental delta, a
+// final usage report (when stream_options.include_usage is set), or both.
+type streamEvent struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// ChatStream implements StreamingBackend for openAIBackend: it opens the
+// same endpoint as Chat with "stream": true and parses the `data: {...}`
+// Server-Sent Events lines, emitting one StreamChunk per delta. The `data:
+// [DONE]` sentinel closes the channel without a final chunk.
+func (openAIBackend) ChatStream(ctx context.Context, request ChatRequest) (<-chan StreamChunk, error) {
+	endpoint := buildEndpoint(request.APIBase)
+	payload := map[string]interface{}{
+		"model":          request.Model,
+		"messages":       request.Messages,
+		"stream":         true,
+		"stream_options": map[string]interface{}{"include_usage": true},
+	}
+	for key, value := range request.ExtraParams {
+		payload[key] = value
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
 	}
 
-	return parsed.Choices[0].Message.Content, nil
+	client := defaultHTTPClient
+	if request.Timeout > 0 {
+		client = &http.Client{
+			Timeout:   time.Duration(request.Timeout) * time.Second,
+			Transport: defaultHTTPClient.Transport,
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if request.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", request.APIKey))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		responseBody, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, NewAPIError(resp.StatusCode, strings.TrimSpace(string(responseBody)))
+	}
+
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				return
+			}
+
+			var event streamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				sendStreamChunk(ctx, out, StreamChunk{Err: err})
+				return
+			}
+
+			if event.Usage != nil {
+				usage := TokenUsage{
+					PromptTokens:     event.Usage.PromptTokens,
+					CompletionTokens: event.Usage.CompletionTokens,
+					TotalTokens:      event.Usage.TotalTokens,
+				}
+				if !sendStreamChunk(ctx, out, StreamChunk{Usage: &usage}) {
+					return
+				}
+			}
+
+			if len(event.Choices) == 0 || event.Choices[0].Delta.Content == "" {
+				continue
+			}
+			if !sendStreamChunk(ctx, out, StreamChunk{Delta: event.Choices[0].Delta.Content}) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			sendStreamChunk(ctx, out, StreamChunk{Err: err})
+		}
+	}()
+
+	return out, nil
+}
+
+// CallChatCompletionStream is CallChatCompletionContext's synchronous
+// streaming counterpart: it drains openAIBackend's SSE stream, invoking
+// onDelta for each token as it arrives, and returns the fully accumulated
+// completion once the stream ends. An error returned by onDelta aborts the
+// stream immediately (its underlying HTTP request is canceled) and is
+// returned as-is, so a caller watching for a stop condition - e.g. a FINAL(
+// marker appearing mid-stream - can bail out without waiting for the rest of
+// the response.
+func CallChatCompletionStream(ctx context.Context, request ChatRequest, onDelta func(chunk string) error) (string, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	deltas, err := (openAIBackend{}).ChatStream(streamCtx, request)
+	if err != nil {
+		return "", err
+	}
+
+	var full strings.Builder
+	for sc := range deltas {
+		if sc.Err != nil {
+			return "", sc.Err
+		}
+		if sc.Delta == "" {
+			continue
+		}
+		full.WriteString(sc.Delta)
+		if err := onDelta(sc.Delta); err != nil {
+			cancel()
+			return full.String(), err
+		}
+	}
+
+	return full.String(), nil
+}
+
+// sendStreamChunk sends chunk on out unless ctx is done first, so the
+// producing goroutine doesn't block forever after a caller abandons the
+// stream (e.g. CompletionStreamContext cancels once FINAL() has closed).
+// It reports whether the send happened.
+func sendStreamChunk(ctx context.Context, out chan<- StreamChunk, chunk StreamChunk) bool {
+	select {
+	case out <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func buildEndpoint(apiBase string) string {
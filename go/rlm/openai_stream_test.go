@@ -0,0 +1,73 @@
+package rlm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallChatCompletionStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "text/event-stream" {
+			t.Errorf("Accept header = %q, want text/event-stream", accept)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"Hel", "lo"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", delta)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var got string
+	full, err := CallChatCompletionStream(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+	}, func(chunk string) error {
+		got += chunk
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CallChatCompletionStream() error = %v", err)
+	}
+	if full != "Hello" || got != "Hello" {
+		t.Errorf("CallChatCompletionStream() = %q, onDelta accumulated %q, want %q", full, got, "Hello")
+	}
+}
+
+func TestCallChatCompletionStreamOnDeltaErrorAborts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for _, delta := range []string{"a", "b", "c"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", delta)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	stop := errors.New("stop early")
+	seen := 0
+	_, err := CallChatCompletionStream(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+	}, func(chunk string) error {
+		seen++
+		if chunk == "b" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("CallChatCompletionStream() error = %v, want %v", err, stop)
+	}
+	if seen > 2 {
+		t.Errorf("onDelta called %d times, want at most 2 (aborted on 'b')", seen)
+	}
+}
@@ -0,0 +1,288 @@
+package rlm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// openAPISchema is the wire shape of an OpenAPI 3 schema object - a separate
+// type from JSONSchema because OpenAPI spells some keywords differently
+// ("nullable" instead of a type union, "example" instead of nothing) and
+// because a "$ref" object otherwise carries no sibling keywords worth typing
+// out.
+type openAPISchema struct {
+	Type                 string                    `json:"type"`
+	Ref                  string                    `json:"$ref"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Items                *openAPISchema            `json:"items"`
+	Required             []string                  `json:"required"`
+	Enum                 []interface{}             `json:"enum"`
+	Nullable             bool                      `json:"nullable"`
+	Description          string                    `json:"description"`
+	Example              interface{}               `json:"example"`
+	Format               string                    `json:"format"`
+	AllOf                []*openAPISchema          `json:"allOf"`
+	AnyOf                []*openAPISchema          `json:"anyOf"`
+	OneOf                []*openAPISchema          `json:"oneOf"`
+	Discriminator        *openAPIDiscriminator     `json:"discriminator"`
+	AdditionalProperties interface{}               `json:"additionalProperties"`
+	Minimum              *float64                  `json:"minimum"`
+	Maximum              *float64                  `json:"maximum"`
+	MinLength            *int                      `json:"minLength"`
+	MaxLength            *int                      `json:"maxLength"`
+	Pattern              string                    `json:"pattern"`
+}
+
+type openAPIDiscriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping"`
+}
+
+// openAPIDocument is the minimal subset of an OpenAPI 3 document
+// SchemaFromOpenAPI needs: the named schemas under components/schemas, plus
+// a root-level "$defs" map for OpenAPI 3.1/JSON-Schema-2020-12 documents
+// that put reusable schemas there instead. Every other section (paths,
+// info, servers, ...) is ignored.
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+	Defs map[string]*openAPISchema `json:"$defs"`
+}
+
+// SchemaFromOpenAPI loads a JSON-encoded OpenAPI 3 document and lowers the
+// named component under components/schemas into this package's JSONSchema
+// type, so StructuredCompletion can target an existing API contract instead
+// of a hand-authored schema.
+//
+// $ref is resolved only against "#/components/schemas/*" and "#/$defs/*" -
+// the two places real-world specs put reusable schemas; allOf branches are
+// merged into a single object schema (OpenAPI's composition model has no
+// equivalent in StructuredCompletion, which expects one flat Properties map
+// per schema); oneOf/anyOf keep their branches as JSONSchema.OneOf/AnyOf,
+// with an OpenAPI discriminator carried over as JSONSchema.Discriminator.
+// Every "example" value found while lowering is copied onto the
+// corresponding JSONSchema's Example field, for
+// MetaAgent.OptimizeForStructured to surface as a concrete extraction hint.
+//
+// Only JSON-encoded documents are supported: this package has no YAML
+// dependency to decode the more common .yaml OpenAPI files.
+func SchemaFromOpenAPI(spec []byte, componentName string) (*JSONSchema, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("rlm: invalid OpenAPI document: %w", err)
+	}
+
+	components := doc.Components.Schemas
+	if components == nil {
+		components = map[string]*openAPISchema{}
+	}
+	for name, def := range doc.Defs {
+		if _, exists := components[name]; !exists {
+			components[name] = def
+		}
+	}
+
+	root, ok := components[componentName]
+	if !ok {
+		return nil, fmt.Errorf("rlm: component %q not found in components/schemas or $defs", componentName)
+	}
+
+	return lowerOpenAPISchema(root, components, map[string]bool{})
+}
+
+// lowerOpenAPISchema recursively converts an openAPISchema into a JSONSchema,
+// resolving $ref against components and merging allOf branches. seen guards
+// against a $ref cycle turning this into infinite recursion.
+func lowerOpenAPISchema(s *openAPISchema, components map[string]*openAPISchema, seen map[string]bool) (*JSONSchema, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	if s.Ref != "" {
+		const componentsPrefix = "#/components/schemas/"
+		const defsPrefix = "#/$defs/"
+		var name string
+		switch {
+		case strings.HasPrefix(s.Ref, componentsPrefix):
+			name = strings.TrimPrefix(s.Ref, componentsPrefix)
+		case strings.HasPrefix(s.Ref, defsPrefix):
+			name = strings.TrimPrefix(s.Ref, defsPrefix)
+		default:
+			return nil, fmt.Errorf("rlm: unsupported $ref %q (only %s* or %s* is resolved)", s.Ref, componentsPrefix, defsPrefix)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("rlm: cyclic $ref detected at %q", name)
+		}
+		target, ok := components[name]
+		if !ok {
+			return nil, fmt.Errorf("rlm: $ref %q not found in components/schemas or $defs", s.Ref)
+		}
+		nextSeen := make(map[string]bool, len(seen)+1)
+		for k, v := range seen {
+			nextSeen[k] = v
+		}
+		nextSeen[name] = true
+		return lowerOpenAPISchema(target, components, nextSeen)
+	}
+
+	if len(s.AllOf) > 0 {
+		return mergeAllOf(s, components, seen)
+	}
+
+	schema := &JSONSchema{
+		Type:                 s.Type,
+		Required:             s.Required,
+		Nullable:             s.Nullable,
+		Description:          s.Description,
+		Example:              s.Example,
+		Format:               s.Format,
+		AdditionalProperties: s.AdditionalProperties,
+		Minimum:              s.Minimum,
+		Maximum:              s.Maximum,
+		MinLength:            s.MinLength,
+		MaxLength:            s.MaxLength,
+		Pattern:              s.Pattern,
+	}
+
+	for _, e := range s.Enum {
+		if str, ok := e.(string); ok {
+			schema.Enum = append(schema.Enum, str)
+		}
+	}
+
+	if s.Items != nil {
+		items, err := lowerOpenAPISchema(s.Items, components, seen)
+		if err != nil {
+			return nil, err
+		}
+		schema.Items = items
+	}
+
+	if len(s.Properties) > 0 {
+		schema.Properties = make(map[string]*JSONSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			lowered, err := lowerOpenAPISchema(prop, components, seen)
+			if err != nil {
+				return nil, err
+			}
+			schema.Properties[name] = lowered
+		}
+	}
+
+	if len(s.OneOf) > 0 {
+		branches, err := lowerBranches(s.OneOf, components, seen)
+		if err != nil {
+			return nil, err
+		}
+		schema.OneOf = branches
+	}
+	if len(s.AnyOf) > 0 {
+		branches, err := lowerBranches(s.AnyOf, components, seen)
+		if err != nil {
+			return nil, err
+		}
+		schema.AnyOf = branches
+	}
+	if s.Discriminator != nil {
+		schema.Discriminator = &Discriminator{
+			PropertyName: s.Discriminator.PropertyName,
+			Mapping:      s.Discriminator.Mapping,
+		}
+	}
+
+	return schema, nil
+}
+
+// collectSchemaExamples walks schema's Properties (and Items, for arrays)
+// collecting every non-nil Example value into a flat map keyed by dotted
+// path from prefix, so MetaAgent.OptimizeForStructured can surface them as
+// concrete extraction hints regardless of how deep a field sits.
+func collectSchemaExamples(schema *JSONSchema, prefix string) map[string]interface{} {
+	examples := map[string]interface{}{}
+	if schema == nil {
+		return examples
+	}
+
+	if schema.Example != nil && prefix != "" {
+		examples[prefix] = schema.Example
+	}
+
+	for name, prop := range schema.Properties {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		for k, v := range collectSchemaExamples(prop, path) {
+			examples[k] = v
+		}
+	}
+
+	if schema.Items != nil {
+		path := prefix + "[]"
+		for k, v := range collectSchemaExamples(schema.Items, path) {
+			examples[k] = v
+		}
+	}
+
+	return examples
+}
+
+func lowerBranches(branches []*openAPISchema, components map[string]*openAPISchema, seen map[string]bool) ([]*JSONSchema, error) {
+	out := make([]*JSONSchema, 0, len(branches))
+	for _, b := range branches {
+		lowered, err := lowerOpenAPISchema(b, components, seen)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, lowered)
+	}
+	return out, nil
+}
+
+// mergeAllOf lowers every allOf branch and flattens their object schemas
+// into one, the way an OpenAPI document expects allOf composition (e.g. "Dog
+// allOf [Animal, {properties: {breed: ...}}]") to behave for a single
+// extracted object. Sibling properties/required declared alongside allOf
+// (common for adding fields on top of a base) are merged in last so they can
+// override a base branch's.
+func mergeAllOf(s *openAPISchema, components map[string]*openAPISchema, seen map[string]bool) (*JSONSchema, error) {
+	merged := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+
+	for _, branch := range s.AllOf {
+		lowered, err := lowerOpenAPISchema(branch, components, seen)
+		if err != nil {
+			return nil, err
+		}
+		if lowered.Type != "" && lowered.Type != "object" {
+			return nil, fmt.Errorf("rlm: allOf branch has non-object type %q, only object merging is supported", lowered.Type)
+		}
+		for name, prop := range lowered.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, lowered.Required...)
+		if lowered.Description != "" {
+			merged.Description = lowered.Description
+		}
+	}
+
+	if len(s.Properties) > 0 {
+		for name, prop := range s.Properties {
+			lowered, err := lowerOpenAPISchema(prop, components, seen)
+			if err != nil {
+				return nil, err
+			}
+			merged.Properties[name] = lowered
+		}
+		merged.Required = append(merged.Required, s.Required...)
+	}
+	if s.Description != "" {
+		merged.Description = s.Description
+	}
+	if s.Example != nil {
+		merged.Example = s.Example
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,204 @@
+package rlm
+
+import "testing"
+
+func TestSchemaFromOpenAPI_SimpleObject(t *testing.T) {
+	spec := []byte(`{
+		"components": {
+			"schemas": {
+				"Person": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string", "example": "Ada Lovelace"},
+						"age": {"type": "integer"}
+					},
+					"required": ["name"]
+				}
+			}
+		}
+	}`)
+
+	schema, err := SchemaFromOpenAPI(spec, "Person")
+	if err != nil {
+		t.Fatalf("SchemaFromOpenAPI() error = %v", err)
+	}
+	if schema.Type != "object" {
+		t.Errorf("Type = %q, want object", schema.Type)
+	}
+	if len(schema.Properties) != 2 {
+		t.Fatalf("Properties = %v, want 2 entries", schema.Properties)
+	}
+	if schema.Properties["name"].Example != "Ada Lovelace" {
+		t.Errorf("name.Example = %v, want %q", schema.Properties["name"].Example, "Ada Lovelace")
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "name" {
+		t.Errorf("Required = %v, want [name]", schema.Required)
+	}
+}
+
+func TestSchemaFromOpenAPI_RefResolution(t *testing.T) {
+	spec := []byte(`{
+		"components": {
+			"schemas": {
+				"Address": {
+					"type": "object",
+					"properties": {"city": {"type": "string"}}
+				},
+				"Person": {
+					"type": "object",
+					"properties": {
+						"home": {"$ref": "#/components/schemas/Address"}
+					}
+				}
+			}
+		}
+	}`)
+
+	schema, err := SchemaFromOpenAPI(spec, "Person")
+	if err != nil {
+		t.Fatalf("SchemaFromOpenAPI() error = %v", err)
+	}
+	home := schema.Properties["home"]
+	if home == nil || home.Type != "object" {
+		t.Fatalf("Properties[home] = %+v, want a resolved object schema", home)
+	}
+	if home.Properties["city"].Type != "string" {
+		t.Errorf("home.city.Type = %q, want string", home.Properties["city"].Type)
+	}
+}
+
+func TestSchemaFromOpenAPI_AllOfMerge(t *testing.T) {
+	spec := []byte(`{
+		"components": {
+			"schemas": {
+				"Animal": {
+					"type": "object",
+					"properties": {"name": {"type": "string"}},
+					"required": ["name"]
+				},
+				"Dog": {
+					"allOf": [
+						{"$ref": "#/components/schemas/Animal"},
+						{"type": "object", "properties": {"breed": {"type": "string"}}, "required": ["breed"]}
+					]
+				}
+			}
+		}
+	}`)
+
+	schema, err := SchemaFromOpenAPI(spec, "Dog")
+	if err != nil {
+		t.Fatalf("SchemaFromOpenAPI() error = %v", err)
+	}
+	if schema.Type != "object" {
+		t.Errorf("Type = %q, want object", schema.Type)
+	}
+	if schema.Properties["name"] == nil || schema.Properties["breed"] == nil {
+		t.Fatalf("Properties = %v, want both name and breed merged in", schema.Properties)
+	}
+	if len(schema.Required) != 2 {
+		t.Errorf("Required = %v, want name and breed", schema.Required)
+	}
+}
+
+func TestSchemaFromOpenAPI_OneOfDiscriminator(t *testing.T) {
+	spec := []byte(`{
+		"components": {
+			"schemas": {
+				"Cat": {"type": "object", "properties": {"meow": {"type": "boolean"}}},
+				"Dog": {"type": "object", "properties": {"bark": {"type": "boolean"}}},
+				"Pet": {
+					"oneOf": [
+						{"$ref": "#/components/schemas/Cat"},
+						{"$ref": "#/components/schemas/Dog"}
+					],
+					"discriminator": {
+						"propertyName": "petType",
+						"mapping": {"cat": "#/components/schemas/Cat", "dog": "#/components/schemas/Dog"}
+					}
+				}
+			}
+		}
+	}`)
+
+	schema, err := SchemaFromOpenAPI(spec, "Pet")
+	if err != nil {
+		t.Fatalf("SchemaFromOpenAPI() error = %v", err)
+	}
+	if len(schema.OneOf) != 2 {
+		t.Fatalf("OneOf = %v, want 2 branches", schema.OneOf)
+	}
+	if schema.Discriminator == nil || schema.Discriminator.PropertyName != "petType" {
+		t.Fatalf("Discriminator = %+v, want propertyName petType", schema.Discriminator)
+	}
+	if schema.Discriminator.Mapping["cat"] != "#/components/schemas/Cat" {
+		t.Errorf("Discriminator.Mapping[cat] = %q", schema.Discriminator.Mapping["cat"])
+	}
+}
+
+func TestSchemaFromOpenAPI_ComponentNotFound(t *testing.T) {
+	spec := []byte(`{"components": {"schemas": {}}}`)
+	if _, err := SchemaFromOpenAPI(spec, "Missing"); err == nil {
+		t.Error("expected an error for a missing component")
+	}
+}
+
+func TestSchemaFromOpenAPI_UnsupportedRef(t *testing.T) {
+	spec := []byte(`{
+		"components": {
+			"schemas": {
+				"Person": {"$ref": "other.json#/Person"}
+			}
+		}
+	}`)
+	if _, err := SchemaFromOpenAPI(spec, "Person"); err == nil {
+		t.Error("expected an error for a $ref outside components/schemas")
+	}
+}
+
+func TestSchemaFromOpenAPI_DefsRefResolution(t *testing.T) {
+	spec := []byte(`{
+		"$defs": {
+			"Address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}}
+			},
+			"Person": {
+				"type": "object",
+				"properties": {
+					"home": {"$ref": "#/$defs/Address"}
+				}
+			}
+		}
+	}`)
+
+	schema, err := SchemaFromOpenAPI(spec, "Person")
+	if err != nil {
+		t.Fatalf("SchemaFromOpenAPI() error = %v", err)
+	}
+	home := schema.Properties["home"]
+	if home == nil || home.Type != "object" {
+		t.Fatalf("Properties[home] = %+v, want a resolved object schema", home)
+	}
+	if home.Properties["city"].Type != "string" {
+		t.Errorf("home.city.Type = %q, want string", home.Properties["city"].Type)
+	}
+}
+
+func TestCollectSchemaExamples(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"name": {Type: "string", Example: "Ada Lovelace"},
+			"tags": {Type: "array", Items: &JSONSchema{Type: "string", Example: "vip"}},
+		},
+	}
+
+	examples := collectSchemaExamples(schema, "")
+	if examples["name"] != "Ada Lovelace" {
+		t.Errorf(`examples["name"] = %v, want "Ada Lovelace"`, examples["name"])
+	}
+	if examples["tags[]"] != "vip" {
+		t.Errorf(`examples["tags[]"] = %v, want "vip"`, examples["tags[]"])
+	}
+}
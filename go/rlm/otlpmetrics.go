@@ -0,0 +1,86 @@
+package rlm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// newOTLPMetricReader builds the PeriodicReader that pushes rlm's Meter
+// measurements to an OTLP collector when MetricsExporter is "otlp". It
+// mirrors newTraceExporter's protocol/endpoint resolution (MetricsProtocol
+// falling back to OTEL_EXPORTER_OTLP_METRICS_PROTOCOL, MetricsEndpoint
+// falling back to TraceEndpoint then OTEL_EXPORTER_OTLP_ENDPOINT) but keeps
+// its own fields so traces and metrics can target different collectors.
+func (o *Observer) newOTLPMetricReader() (sdkmetric.Reader, error) {
+	endpoint := o.config.MetricsEndpoint
+	if endpoint == "" {
+		endpoint = o.config.TraceEndpoint
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	protocol := o.config.MetricsProtocol
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+	}
+	if protocol == "" {
+		protocol = "grpc"
+	}
+
+	var exporter sdkmetric.Exporter
+	var err error
+	switch protocol {
+	case "http", "http/protobuf", "http/json":
+		exporter, err = o.newOTLPMetricHTTPExporter(endpoint)
+	case "grpc":
+		exporter, err = o.newOTLPMetricGRPCExporter(endpoint)
+	default:
+		return nil, fmt.Errorf("rlm: unsupported metrics protocol %q (want \"grpc\", \"http\", \"http/protobuf\", \"http/json\")", protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sdkmetric.NewPeriodicReader(exporter), nil
+}
+
+func (o *Observer) newOTLPMetricGRPCExporter(endpoint string) (sdkmetric.Exporter, error) {
+	opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint)}
+	if o.config.TraceInsecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if len(o.config.TraceHeaders) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(o.config.TraceHeaders))
+	}
+	if o.config.TraceCompression == "gzip" {
+		opts = append(opts, otlpmetricgrpc.WithCompressor(o.config.TraceCompression))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return otlpmetricgrpc.New(ctx, opts...)
+}
+
+func (o *Observer) newOTLPMetricHTTPExporter(endpoint string) (sdkmetric.Exporter, error) {
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+	if o.config.TraceInsecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	if len(o.config.TraceHeaders) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(o.config.TraceHeaders))
+	}
+	if o.config.TraceCompression == "gzip" {
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return otlpmetrichttp.New(ctx, opts...)
+}
@@ -0,0 +1,82 @@
+package rlm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	finalTripleDouble = regexp.MustCompile(`(?s)FINAL\s*\(\s*"""(.*)"""`)
+	finalTripleSingle = regexp.MustCompile(`(?s)FINAL\s*\(\s*'''(.*)'''`)
+	finalDouble       = regexp.MustCompile(`(?s)FINAL\s*\(\s*"([^"]*)"`)
+	finalSingle       = regexp.MustCompile(`(?s)FINAL\s*\(\s*'([^']*)'`)
+	finalVar          = regexp.MustCompile(`FINAL_VAR\s*\(\s*(\w+)\s*\)`)
+	finalAny          = regexp.MustCompile(`FINAL\(|FINAL_VAR\(`)
+	toolCall          = regexp.MustCompile(`(?s)TOOL\s*\(\s*"([^"]+)"\s*,\s*(\{.*\})\s*\)`)
+)
+
+// ToolCallRequest is a parsed TOOL(...) invocation awaiting dispatch.
+type ToolCallRequest struct {
+	Name string
+	Args json.RawMessage
+}
+
+// ParseToolCall looks for a TOOL("name", {...json...}) call in response and
+// returns the tool name and raw JSON arguments. It does not validate the
+// arguments against the tool's schema; callers should do that at dispatch
+// time via ToolRegistry.Invoke.
+func ParseToolCall(response string) (ToolCallRequest, bool) {
+	match := toolCall.FindStringSubmatch(response)
+	if len(match) < 3 {
+		return ToolCallRequest{}, false
+	}
+
+	args := strings.TrimSpace(match[2])
+	if !json.Valid([]byte(args)) {
+		return ToolCallRequest{}, false
+	}
+
+	return ToolCallRequest{Name: match[1], Args: json.RawMessage(args)}, true
+}
+
+// IsFinal reports whether response contains a FINAL(...) or FINAL_VAR(...) marker.
+func IsFinal(response string) bool {
+	return finalAny.MatchString(response)
+}
+
+// ParseResponse extracts the final answer from response, looking first for an
+// inline FINAL(...) literal and falling back to a FINAL_VAR(...) environment lookup.
+func ParseResponse(response string, env map[string]interface{}) (string, bool) {
+	answer, ok := extractFinal(response)
+	if ok {
+		return answer, true
+	}
+
+	return extractFinalVar(response, env)
+}
+
+func extractFinal(response string) (string, bool) {
+	matchers := []*regexp.Regexp{finalTripleDouble, finalTripleSingle, finalDouble, finalSingle}
+	for _, matcher := range matchers {
+		match := matcher.FindStringSubmatch(response)
+		if len(match) > 1 {
+			return strings.TrimSpace(match[1]), true
+		}
+	}
+	return "", false
+}
+
+func extractFinalVar(response string, env map[string]interface{}) (string, bool) {
+	match := finalVar.FindStringSubmatch(response)
+	if len(match) < 2 {
+		return "", false
+	}
+
+	value, ok := env[match[1]]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
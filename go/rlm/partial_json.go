@@ -0,0 +1,417 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errIncomplete is returned internally by the partial-JSON scanner when the
+// buffer ends in the middle of a value, so the caller knows not to report it
+// yet (more input may still extend it, e.g. "tru" could become "true").
+var errIncomplete = errors.New("incomplete JSON value")
+
+// partialJSONParser incrementally scans an accumulating, possibly-truncated
+// JSON document and calls onValue as soon as each leaf value (and each
+// object/array) is unambiguously complete - i.e. its closing token actually
+// appeared in the input, not merely because the buffer ran out. Paths are
+// JSON pointers, e.g. "/items/3/address/zip".
+type partialJSONParser struct {
+	buf      strings.Builder
+	onValue  func(path string, value interface{})
+	reported map[string]bool
+}
+
+func newPartialJSONParser(onValue func(path string, value interface{})) *partialJSONParser {
+	return &partialJSONParser{onValue: onValue, reported: map[string]bool{}}
+}
+
+// Feed appends delta to the accumulated buffer and fires onValue for any
+// path that has newly become complete.
+func (p *partialJSONParser) Feed(delta string) {
+	p.buf.WriteString(delta)
+
+	s := &jsonScanner{data: []byte(p.buf.String())}
+	value, err := s.parseValue("", p.report)
+	if err != nil {
+		return
+	}
+	p.report("", value)
+}
+
+// report invokes onValue for path the first time it's seen; later calls for
+// an already-reported path (harmless re-parses on the next Feed) are no-ops.
+// path is delivered as-is (RFC 6901: the document root is "", not "/") per
+// this struct's own doc comment - unlike pointerOrRoot, which exists for
+// human-readable validation-error display, not for the paths this type hands
+// callers.
+func (p *partialJSONParser) report(path string, value interface{}) {
+	if p.reported[path] {
+		return
+	}
+	p.reported[path] = true
+	p.onValue(path, value)
+}
+
+// Snapshot returns a best-effort parse of everything fed so far, tolerating
+// truncation by auto-closing any open string, object, or array. It's meant
+// for a caller that wants the current partial state right now rather than
+// waiting for onValue callbacks, and may contain values that later change.
+func (p *partialJSONParser) Snapshot() map[string]interface{} {
+	closed := closePartialJSON(p.buf.String())
+	var value interface{}
+	if err := json.Unmarshal([]byte(closed), &value); err != nil {
+		return nil
+	}
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return nil
+}
+
+// fieldStabilizationTracker watches a stream of onValue reports nested under
+// a single sub-task's field and cancels that sub-task's context once quiet
+// has elapsed since the last report it saw - the field's value has
+// "stabilized" (e.g. a long array has stopped growing) and whatever tokens
+// remain (trailing commentary, a closing brace) aren't worth the wait.
+// structuredCompletionParallelStream uses one per in-flight sub-task to cut
+// long array extractions short once their items stop arriving.
+type fieldStabilizationTracker struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	quiet  time.Duration
+	cancel stdcontext.CancelFunc
+}
+
+func newFieldStabilizationTracker(quiet time.Duration, cancel stdcontext.CancelFunc) *fieldStabilizationTracker {
+	return &fieldStabilizationTracker{quiet: quiet, cancel: cancel}
+}
+
+// onValue resets the quiet timer; pass this as a partialJSONParser's onValue
+// callback (or chain it alongside another one) to have it observe every
+// report for the field being streamed.
+func (f *fieldStabilizationTracker) onValue(path string, value interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.timer = time.AfterFunc(f.quiet, f.cancel)
+}
+
+// stop cancels the pending quiet timer, for a caller that finished (or
+// failed) before stabilization fired and doesn't want a stray cancel landing
+// on a context it's already done with.
+func (f *fieldStabilizationTracker) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+}
+
+// closePartialJSON returns raw with any open string closed, any dangling
+// trailing "," or ":" trimmed, and any open "{"/"[" closed, so it can be
+// handed to json.Unmarshal for a best-effort snapshot of truncated input.
+func closePartialJSON(raw string) string {
+	var stack []byte
+	inString := false
+	escaped := false
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	result := raw
+	if inString {
+		result += `"`
+	}
+
+	trimmed := strings.TrimRight(result, " \t\n\r")
+	if n := len(trimmed); n > 0 && (trimmed[n-1] == ',' || trimmed[n-1] == ':') {
+		result = trimmed[:n-1]
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i] {
+		case '{':
+			result += "}"
+		case '[':
+			result += "]"
+		}
+	}
+	return result
+}
+
+// jsonScanner is a single-pass recursive-descent reader over an in-progress
+// JSON buffer. Every parseX method returns errIncomplete instead of an error
+// when the buffer ends before the value could be fully read, since the
+// buffer is expected to keep growing as more of the LLM's response arrives.
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) peek() (byte, bool) {
+	if s.pos >= len(s.data) {
+		return 0, false
+	}
+	return s.data[s.pos], true
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsonScanner) hasPrefix(lit string) bool {
+	if s.pos+len(lit) > len(s.data) {
+		return false
+	}
+	return string(s.data[s.pos:s.pos+len(lit)]) == lit
+}
+
+// parseValue parses one JSON value at the scanner's current position,
+// reporting path (and every completed descendant path) via report as soon
+// as each one closes.
+func (s *jsonScanner) parseValue(path string, report func(string, interface{})) (interface{}, error) {
+	s.skipSpace()
+	b, ok := s.peek()
+	if !ok {
+		return nil, errIncomplete
+	}
+
+	switch {
+	case b == '{':
+		return s.parseObject(path, report)
+	case b == '[':
+		return s.parseArray(path, report)
+	case b == '"':
+		return s.parseString()
+	case b == 't' || b == 'f':
+		return s.parseBool()
+	case b == 'n':
+		return s.parseNull()
+	default:
+		return s.parseNumber()
+	}
+}
+
+func (s *jsonScanner) parseObject(path string, report func(string, interface{})) (interface{}, error) {
+	start := s.pos
+	s.pos++ // consume '{'
+	obj := map[string]interface{}{}
+
+	s.skipSpace()
+	if b, ok := s.peek(); ok && b == '}' {
+		s.pos++
+		return obj, nil
+	}
+
+	for {
+		s.skipSpace()
+		b, ok := s.peek()
+		if !ok || b != '"' {
+			s.pos = start
+			return obj, errIncomplete
+		}
+
+		keyVal, err := s.parseString()
+		if err != nil {
+			s.pos = start
+			return obj, errIncomplete
+		}
+		key := keyVal.(string)
+
+		s.skipSpace()
+		if b, ok := s.peek(); !ok || b != ':' {
+			s.pos = start
+			return obj, errIncomplete
+		}
+		s.pos++ // consume ':'
+
+		fieldPath := childPath(path, key)
+		value, err := s.parseValue(fieldPath, report)
+		if err != nil {
+			s.pos = start
+			return obj, errIncomplete
+		}
+		obj[key] = value
+		report(fieldPath, value)
+
+		s.skipSpace()
+		b, ok = s.peek()
+		if !ok {
+			s.pos = start
+			return obj, errIncomplete
+		}
+		if b == ',' {
+			s.pos++
+			continue
+		}
+		if b == '}' {
+			s.pos++
+			return obj, nil
+		}
+		s.pos = start
+		return obj, errIncomplete
+	}
+}
+
+func (s *jsonScanner) parseArray(path string, report func(string, interface{})) (interface{}, error) {
+	start := s.pos
+	s.pos++ // consume '['
+	arr := []interface{}{}
+
+	s.skipSpace()
+	if b, ok := s.peek(); ok && b == ']' {
+		s.pos++
+		return arr, nil
+	}
+
+	for index := 0; ; index++ {
+		itemPath := indexPath(path, index)
+		value, err := s.parseValue(itemPath, report)
+		if err != nil {
+			s.pos = start
+			return arr, errIncomplete
+		}
+		arr = append(arr, value)
+		report(itemPath, value)
+
+		s.skipSpace()
+		b, ok := s.peek()
+		if !ok {
+			s.pos = start
+			return arr, errIncomplete
+		}
+		if b == ',' {
+			s.pos++
+			continue
+		}
+		if b == ']' {
+			s.pos++
+			return arr, nil
+		}
+		s.pos = start
+		return arr, errIncomplete
+	}
+}
+
+func (s *jsonScanner) parseString() (interface{}, error) {
+	start := s.pos
+	s.pos++ // consume opening quote
+	var sb strings.Builder
+	for {
+		b, ok := s.peek()
+		if !ok {
+			s.pos = start
+			return "", errIncomplete
+		}
+		if b == '\\' {
+			s.pos++
+			esc, ok := s.peek()
+			if !ok {
+				s.pos = start
+				return "", errIncomplete
+			}
+			switch esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(esc)
+			}
+			s.pos++
+			continue
+		}
+		if b == '"' {
+			s.pos++
+			return sb.String(), nil
+		}
+		sb.WriteByte(b)
+		s.pos++
+	}
+}
+
+// parseNumber only commits once a delimiter (or EOF that turns out to be
+// real, i.e. followed eventually by more input that isn't more digits) ends
+// it; at the live buffer's end it can't tell "42" from a still-growing
+// "423", so it reports errIncomplete until something else terminates it.
+func (s *jsonScanner) parseNumber() (interface{}, error) {
+	start := s.pos
+	for {
+		b, ok := s.peek()
+		if !ok {
+			s.pos = start
+			return nil, errIncomplete
+		}
+		if strings.IndexByte("0123456789+-.eE", b) >= 0 {
+			s.pos++
+			continue
+		}
+		break
+	}
+
+	raw := string(s.data[start:s.pos])
+	num, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		s.pos = start
+		return nil, errIncomplete
+	}
+	return num, nil
+}
+
+func (s *jsonScanner) parseBool() (interface{}, error) {
+	if s.hasPrefix("true") {
+		s.pos += 4
+		return true, nil
+	}
+	if s.hasPrefix("false") {
+		s.pos += 5
+		return false, nil
+	}
+	return nil, errIncomplete
+}
+
+func (s *jsonScanner) parseNull() (interface{}, error) {
+	if s.hasPrefix("null") {
+		s.pos += 4
+		return nil, nil
+	}
+	return nil, errIncomplete
+}
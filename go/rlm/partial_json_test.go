@@ -0,0 +1,135 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"testing"
+	"time"
+)
+
+func TestPartialJSONParserFiresOnValueAsFieldsComplete(t *testing.T) {
+	var seen []string
+	p := newPartialJSONParser(func(path string, value interface{}) {
+		seen = append(seen, path)
+	})
+
+	p.Feed(`{"name": "Ada"`)
+	if len(seen) != 1 || seen[0] != "/name" {
+		t.Fatalf("after first delta, seen = %v, want [/name]", seen)
+	}
+
+	p.Feed(`, "age": 36}`)
+	if len(seen) != 3 {
+		t.Fatalf("after full object, seen = %v, want 3 entries (/name, /age, root)", seen)
+	}
+	if seen[1] != "/age" || seen[2] != "" {
+		t.Errorf("seen = %v, want [/name /age \"\"]", seen)
+	}
+}
+
+func TestPartialJSONParserDoesNotReportAGrowingNumberEarly(t *testing.T) {
+	var seen []string
+	p := newPartialJSONParser(func(path string, value interface{}) {
+		seen = append(seen, path)
+	})
+
+	p.Feed(`{"count": 4`)
+	if len(seen) != 0 {
+		t.Fatalf("seen = %v, want none: a bare trailing digit could still grow", seen)
+	}
+
+	p.Feed(`2}`)
+	if len(seen) != 2 {
+		t.Fatalf("seen = %v, want 2 entries (/count, root) once the object closes", seen)
+	}
+}
+
+func TestPartialJSONParserDoesNotReportSamePathTwice(t *testing.T) {
+	var seen []string
+	p := newPartialJSONParser(func(path string, value interface{}) {
+		seen = append(seen, path)
+	})
+
+	p.Feed(`{"name":`)
+	p.Feed(` "Ada"}`)
+
+	count := 0
+	for _, path := range seen {
+		if path == "/name" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("/name reported %d times, want exactly once", count)
+	}
+}
+
+func TestPartialJSONParserSnapshotToleratesTruncation(t *testing.T) {
+	p := newPartialJSONParser(func(string, interface{}) {})
+	p.Feed(`{"name": "Ada", "tags": ["x", "y`)
+
+	snap := p.Snapshot()
+	if snap["name"] != "Ada" {
+		t.Errorf("Snapshot()[\"name\"] = %v, want Ada", snap["name"])
+	}
+	if _, ok := snap["tags"]; !ok {
+		t.Errorf("Snapshot() = %v, want a best-effort \"tags\" entry", snap)
+	}
+}
+
+func TestClosePartialJSONClosesOpenStringsAndBrackets(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`{"a": 1`, `{"a": 1}`},
+		{`{"a": "hello`, `{"a": "hello"}`},
+		{`{"a": [1, 2,`, `{"a": [1, 2]}`},
+		{`{"a": 1,`, `{"a": 1}`},
+		{`{"a":`, `{"a"}`},
+	}
+
+	for _, tt := range tests {
+		got := closePartialJSON(tt.raw)
+		if got != tt.want {
+			t.Errorf("closePartialJSON(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFieldStabilizationTrackerCancelsAfterQuiet(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	tracker := newFieldStabilizationTracker(20*time.Millisecond, cancel)
+
+	tracker.onValue("/items/0", "a")
+	if ctx.Err() != nil {
+		t.Fatal("context canceled before the quiet window elapsed")
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("context was not canceled once the quiet window elapsed with no further reports")
+	}
+}
+
+func TestFieldStabilizationTrackerResetsOnEachReport(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	tracker := newFieldStabilizationTracker(30*time.Millisecond, cancel)
+
+	for i := 0; i < 3; i++ {
+		tracker.onValue("/items/0", "a")
+		time.Sleep(15 * time.Millisecond)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("context canceled despite reports arriving faster than the quiet window")
+	}
+	tracker.stop()
+}
+
+func TestJSONScannerReturnsIncompleteForATruncatedValue(t *testing.T) {
+	s := &jsonScanner{data: []byte(`{"name": "Ada"`)}
+	_, err := s.parseValue("", func(string, interface{}) {})
+	if err != errIncomplete {
+		t.Errorf("parseValue() err = %v, want errIncomplete", err)
+	}
+}
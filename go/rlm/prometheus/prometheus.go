@@ -0,0 +1,104 @@
+// Package prometheus provides a ready-made rlm.MetricsObserver backed by
+// Prometheus counters and histograms, so operators can alert on runaway
+// recursion, track per-model latency distributions, and debug
+// parsing-retry storms without wrapping every RLM call site themselves.
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jbeck018/recursive-llm-ts/go/rlm"
+	promclient "github.com/prometheus/client_golang/prometheus"
+)
+
+// Observer is a rlm.MetricsObserver that records every hook as a Prometheus
+// metric, labeled by recursion depth and (where available) model.
+type Observer struct {
+	llmCallsTotal   *promclient.CounterVec
+	iterationsTotal *promclient.CounterVec
+	recursionDepth  *promclient.GaugeVec
+	llmLatency      *promclient.HistogramVec
+	replErrorsTotal *promclient.CounterVec
+	tokensTotal     *promclient.CounterVec
+}
+
+// NewObserver registers the Observer's metrics against the default
+// Prometheus registerer.
+func NewObserver() *Observer {
+	return NewObserverWithRegisterer(promclient.DefaultRegisterer)
+}
+
+// NewObserverWithRegisterer registers the Observer's metrics against reg,
+// for callers that keep their own Prometheus registry instead of the global
+// default.
+func NewObserverWithRegisterer(reg promclient.Registerer) *Observer {
+	o := &Observer{
+		llmCallsTotal: promclient.NewCounterVec(promclient.CounterOpts{
+			Name: "rlm_llm_calls_total",
+			Help: "Total number of LLM calls made by the RLM engine.",
+		}, []string{"depth", "model"}),
+		iterationsTotal: promclient.NewCounterVec(promclient.CounterOpts{
+			Name: "rlm_iterations_total",
+			Help: "Total number of REPL iterations executed.",
+		}, []string{"depth"}),
+		recursionDepth: promclient.NewGaugeVec(promclient.GaugeOpts{
+			Name: "rlm_recursion_depth",
+			Help: "Depth reached by the most recent recursive_llm() call.",
+		}, []string{"depth"}),
+		llmLatency: promclient.NewHistogramVec(promclient.HistogramOpts{
+			Name:    "rlm_llm_latency_seconds",
+			Help:    "LLM call latency in seconds.",
+			Buckets: promclient.DefBuckets,
+		}, []string{"depth", "model"}),
+		replErrorsTotal: promclient.NewCounterVec(promclient.CounterOpts{
+			Name: "rlm_repl_errors_total",
+			Help: "Total number of REPL executions that returned an error.",
+		}, []string{"depth"}),
+		tokensTotal: promclient.NewCounterVec(promclient.CounterOpts{
+			Name: "rlm_tokens_total",
+			Help: "Total number of tokens consumed, by direction (in/out) and recursion depth.",
+		}, []string{"direction", "model", "depth"}),
+	}
+
+	reg.MustRegister(
+		o.llmCallsTotal,
+		o.iterationsTotal,
+		o.recursionDepth,
+		o.llmLatency,
+		o.replErrorsTotal,
+		o.tokensTotal,
+	)
+
+	return o
+}
+
+var _ rlm.MetricsObserver = (*Observer)(nil)
+
+func (o *Observer) OnIteration(depth int, _ int) {
+	o.iterationsTotal.WithLabelValues(depthLabel(depth)).Inc()
+}
+
+func (o *Observer) OnLLMCall(depth int, model string, tokensIn int, tokensOut int, latency time.Duration) {
+	label := depthLabel(depth)
+	o.llmCallsTotal.WithLabelValues(label, model).Inc()
+	o.llmLatency.WithLabelValues(label, model).Observe(latency.Seconds())
+	o.tokensTotal.WithLabelValues("in", model, label).Add(float64(tokensIn))
+	o.tokensTotal.WithLabelValues("out", model, label).Add(float64(tokensOut))
+}
+
+func (o *Observer) OnREPLExec(depth int, ok bool, _ time.Duration) {
+	if !ok {
+		o.replErrorsTotal.WithLabelValues(depthLabel(depth)).Inc()
+	}
+}
+
+func (o *Observer) OnRecurse(parentDepth int) {
+	o.recursionDepth.WithLabelValues(depthLabel(parentDepth + 1)).Set(float64(parentDepth + 1))
+}
+
+func (o *Observer) OnFinal(int, int) {}
+
+func depthLabel(depth int) string {
+	return strconv.Itoa(depth)
+}
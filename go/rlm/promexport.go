@@ -0,0 +1,137 @@
+package rlm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// prometheusBridge adapts a ManualReader's OTel metricdata snapshot into
+// client_golang's prometheus.Collector interface, re-collecting from reader
+// on every scrape rather than caching - a ManualReader's Collect is cheap
+// and always returns the latest aggregated values.
+type prometheusBridge struct {
+	reader *sdkmetric.ManualReader
+}
+
+// newPrometheusHandler builds the http.Handler behind Observer.PrometheusHandler,
+// backed by its own private Registry rather than prometheus.DefaultRegisterer
+// so an Observer's metrics don't collide with whatever else the host process
+// registers globally.
+func newPrometheusHandler(reader *sdkmetric.ManualReader) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&prometheusBridge{reader: reader})
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// Describe sends no descriptors. This is the Collector's metric set isn't
+// known until Collect actually runs the reader, so prometheusBridge is an
+// "unchecked" Collector per client_golang's convention for that case - see
+// https://pkg.go.dev/github.com/prometheus/client_golang/prometheus#hdr-Custom_Collectors_and_constant_Metrics.
+func (b *prometheusBridge) Describe(chan<- *prometheus.Desc) {}
+
+func (b *prometheusBridge) Collect(ch chan<- prometheus.Metric) {
+	var rm metricdata.ResourceMetrics
+	if err := b.reader.Collect(context.Background(), &rm); err != nil {
+		return
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			collectMetric(ch, m)
+		}
+	}
+}
+
+// collectMetric converts one instrument's aggregated data points to
+// Prometheus metrics. rlm's instruments only ever produce Sum[int64],
+// Float64/Int64 Histogram, or (for the runtime gauges) Gauge[int64], but
+// the switch covers every Aggregation the otel SDK can emit so a future
+// instrument type doesn't silently vanish from /metrics.
+func collectMetric(ch chan<- prometheus.Metric, m metricdata.Metrics) {
+	name := prometheusName(m.Name)
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		valueType := sumValueType(data.IsMonotonic)
+		for _, dp := range data.DataPoints {
+			emitSimple(ch, name, m.Description, valueType, float64(dp.Value), dp.Attributes)
+		}
+	case metricdata.Sum[float64]:
+		valueType := sumValueType(data.IsMonotonic)
+		for _, dp := range data.DataPoints {
+			emitSimple(ch, name, m.Description, valueType, dp.Value, dp.Attributes)
+		}
+	case metricdata.Gauge[int64]:
+		for _, dp := range data.DataPoints {
+			emitSimple(ch, name, m.Description, prometheus.GaugeValue, float64(dp.Value), dp.Attributes)
+		}
+	case metricdata.Gauge[float64]:
+		for _, dp := range data.DataPoints {
+			emitSimple(ch, name, m.Description, prometheus.GaugeValue, dp.Value, dp.Attributes)
+		}
+	case metricdata.Histogram[int64]:
+		for _, dp := range data.DataPoints {
+			emitHistogram(ch, name, m.Description, float64(dp.Sum), dp.Count, dp.Bounds, dp.BucketCounts, dp.Attributes)
+		}
+	case metricdata.Histogram[float64]:
+		for _, dp := range data.DataPoints {
+			emitHistogram(ch, name, m.Description, dp.Sum, dp.Count, dp.Bounds, dp.BucketCounts, dp.Attributes)
+		}
+	}
+}
+
+func sumValueType(isMonotonic bool) prometheus.ValueType {
+	if isMonotonic {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
+}
+
+func emitSimple(ch chan<- prometheus.Metric, name, help string, valueType prometheus.ValueType, value float64, attrs attribute.Set) {
+	labelNames, labelValues := attributeLabels(attrs)
+	desc := prometheus.NewDesc(name, help, labelNames, nil)
+	ch <- prometheus.MustNewConstMetric(desc, valueType, value, labelValues...)
+}
+
+// emitHistogram converts an OTel explicit-bucket histogram data point to a
+// Prometheus histogram. bounds/bucketCounts are parallel except
+// bucketCounts has one extra trailing entry for the +Inf overflow bucket,
+// which Prometheus's own convention leaves implicit given the total count.
+func emitHistogram(ch chan<- prometheus.Metric, name, help string, sum float64, count uint64, bounds []float64, bucketCounts []uint64, attrs attribute.Set) {
+	labelNames, labelValues := attributeLabels(attrs)
+	desc := prometheus.NewDesc(name, help, labelNames, nil)
+
+	buckets := make(map[float64]uint64, len(bounds))
+	var cumulative uint64
+	for i, bound := range bounds {
+		cumulative += bucketCounts[i]
+		buckets[bound] = cumulative
+	}
+
+	ch <- prometheus.MustNewConstHistogram(desc, count, sum, buckets, labelValues...)
+}
+
+func attributeLabels(attrs attribute.Set) (names []string, values []string) {
+	iter := attrs.Iter()
+	names = make([]string, 0, iter.Len())
+	values = make([]string, 0, iter.Len())
+	for iter.Next() {
+		kv := iter.Attribute()
+		names = append(names, string(kv.Key))
+		values = append(values, kv.Value.Emit())
+	}
+	return names, values
+}
+
+// prometheusName converts an OTel instrument name ("rlm.llm.calls") to a
+// Prometheus-legal metric name ("rlm_llm_calls"); Prometheus names allow
+// only [a-zA-Z0-9_:], and "." is the only character OTel's own rlm.*/
+// gen_ai.*/process.runtime.go.* names use outside that set.
+func prometheusName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
@@ -0,0 +1,50 @@
+package rlm
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestObserver_PrometheusHandler_NilWhenNotConfigured(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{MetricsEnabled: true, ServiceName: "test"})
+	defer obs.Shutdown()
+
+	if h := obs.PrometheusHandler(); h != nil {
+		t.Error("expected a nil handler when MetricsExporter is not \"prometheus\"")
+	}
+}
+
+func TestObserver_PrometheusHandler_ServesScrapedMetrics(t *testing.T) {
+	obs := NewObserver(ObservabilityConfig{MetricsEnabled: true, MetricsExporter: "prometheus", ServiceName: "test"})
+	defer obs.Shutdown()
+
+	obs.RecordIteration(1)
+	obs.RecordToolCall("search_context")
+
+	handler := obs.PrometheusHandler()
+	if handler == nil {
+		t.Fatal("expected a non-nil PrometheusHandler")
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("GET /metrics = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "rlm_iterations") {
+		t.Errorf("expected rlm_iterations in scrape output, got: %s", body)
+	}
+	if !strings.Contains(body, "rlm_tool_calls") {
+		t.Errorf("expected rlm_tool_calls in scrape output, got: %s", body)
+	}
+}
+
+func TestPrometheusName(t *testing.T) {
+	if got, want := prometheusName("rlm.llm.calls"), "rlm_llm_calls"; got != want {
+		t.Errorf("prometheusName(%q) = %q, want %q", "rlm.llm.calls", got, want)
+	}
+}
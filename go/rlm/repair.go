@@ -0,0 +1,93 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"fmt"
+)
+
+// RepairCompletion takes a structured result that already failed schema
+// validation (e.g. one returned alongside an error from StructuredCompletion)
+// and converges it to a valid document by re-extracting only the violating
+// top-level fields, the same per-field sub-query applyEnforcement's
+// ActionRepair uses, rather than regenerating the whole object from scratch.
+// This is bound to a background context; prefer RepairCompletionContext when
+// a caller-supplied ctx is available.
+//
+// It's most valuable for large schemas - like the one
+// TestDecomposeSchema_SentimentAnalysis exercises - where most fields already
+// came back correct and only a handful of violations remain.
+func (r *RLM) RepairCompletion(query string, context string, previous map[string]interface{}, config *StructuredConfig) (map[string]interface{}, RLMStats, error) {
+	return r.RepairCompletionContext(stdcontext.Background(), query, context, previous, config)
+}
+
+// RepairCompletionContext is RepairCompletion's context-aware sibling: ctx
+// bounds each per-field repair sub-query (via repairField's own
+// structuredCompletionDirectContext call).
+func (r *RLM) RepairCompletionContext(ctx stdcontext.Context, query string, context string, previous map[string]interface{}, config *StructuredConfig) (map[string]interface{}, RLMStats, error) {
+	if config == nil || config.Schema == nil {
+		return nil, RLMStats{}, fmt.Errorf("structured config and schema are required")
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+
+	candidate := make(map[string]interface{}, len(previous))
+	for k, v := range previous {
+		candidate[k] = v
+	}
+
+	stats := RLMStats{Depth: r.currentDepth}
+	validator := validatorFor(config)
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, stats, err
+		}
+
+		validationErr := validator.Validate(candidate, config.Schema)
+		violations, isValidationResult := validationErr.(*ValidationResult)
+		if validationErr == nil || !isValidationResult || violations.Valid() {
+			stats.ParsingRetries = attempt
+			return candidate, stats, nil
+		}
+
+		fields := make(map[string]bool)
+		var order []string
+		for _, e := range violations.Errors {
+			field := topLevelField(e.Path)
+			if field == "" || fields[field] {
+				continue
+			}
+			fields[field] = true
+			order = append(order, field)
+		}
+		if len(order) == 0 {
+			// The violation isn't attributable to a single top-level field
+			// (e.g. a root-level minProperties/required violation) - there's
+			// no subtree to scope a repair sub-query to.
+			return nil, stats, validationErr
+		}
+
+		for _, field := range order {
+			repaired, err := r.repairField(ctx, field, context, config, candidate)
+			stats.LlmCalls++
+			stats.Iterations++
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			candidate[field] = repaired
+		}
+	}
+
+	if err := validator.Validate(candidate, config.Schema); err != nil {
+		if lastErr != nil {
+			return nil, stats, fmt.Errorf("repair did not converge after %d attempts (last field repair error: %v): %w", maxRetries, lastErr, err)
+		}
+		return nil, stats, fmt.Errorf("repair did not converge after %d attempts: %w", maxRetries, err)
+	}
+	return candidate, stats, nil
+}
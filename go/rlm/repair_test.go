@@ -0,0 +1,71 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"testing"
+)
+
+func TestRepairCompletion_AlreadyValidReturnsImmediately(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"name": "should not be called"}`}})
+
+	result, stats, err := engine.RepairCompletion("extract", "", map[string]interface{}{"name": "Ada"}, &StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("RepairCompletion() error = %v", err)
+	}
+	if result["name"] != "Ada" {
+		t.Errorf("RepairCompletion() result = %v, want the original value unchanged", result)
+	}
+	if stats.LlmCalls != 0 {
+		t.Errorf("LlmCalls = %d, want 0 when the document already validates", stats.LlmCalls)
+	}
+}
+
+func TestRepairCompletion_RepairsOnlyTheViolatingField(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"name": {Type: "string"},
+			"age":  {Type: "number"},
+		},
+		Required: []string{"name", "age"},
+	}
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"name": "Ada"}`}})
+
+	previous := map[string]interface{}{"name": 123.0, "age": 30.0} // name has the wrong type
+	result, stats, err := engine.RepairCompletion("extract", "", previous, &StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("RepairCompletion() error = %v", err)
+	}
+	if result["age"] != 30.0 {
+		t.Errorf("result[age] = %v, want the untouched original value 30", result["age"])
+	}
+	if result["name"] != "Ada" {
+		t.Errorf("result[name] = %v, want the repaired value from the sub-query", result["name"])
+	}
+	if stats.LlmCalls == 0 {
+		t.Error("LlmCalls = 0, want at least one repair sub-query for the violating field")
+	}
+}
+
+func TestRepairCompletion_FailsWhenViolationIsntFieldScoped(t *testing.T) {
+	minProps := 2
+	schema := &JSONSchema{Type: "object", MinProperties: &minProps}
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{}`}})
+
+	_, _, err := engine.RepairCompletion("extract", "", map[string]interface{}{"a": 1}, &StructuredConfig{Schema: schema})
+	if err == nil {
+		t.Fatal("expected an error for a root-level violation with no field to scope a repair to")
+	}
+}
+
+func TestRepairCompletion_RequiresSchema(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{}`}})
+	if _, _, err := engine.RepairCompletionContext(stdcontext.Background(), "q", "", nil, &StructuredConfig{}); err == nil {
+		t.Error("expected an error when config.Schema is nil")
+	}
+}
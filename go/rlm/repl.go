@@ -1,16 +1,20 @@
 package rlm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/dop251/goja"
 )
 
 type REPLExecutor struct {
 	maxOutputChars int
+	sandbox        SandboxConfig
 }
 
 func NewREPLExecutor() *REPLExecutor {
@@ -19,66 +23,272 @@ func NewREPLExecutor() *REPLExecutor {
 	}
 }
 
+// SandboxConfig configures a REPLExecutor's limits once, instead of the
+// caller repeating an ExecuteOptions on every ExecuteContext call. Its
+// timeout/budget fields lower into the equivalent ExecuteOptions field (a
+// call's own ExecuteOptions still wins when explicitly set); breaching one of
+// them returns a *SandboxLimitError identifying which limit fired, rather
+// than the bare sentinel errors ExecuteOptions produces on its own.
+type SandboxConfig struct {
+	// CPUTimeout and WallTimeout both bound wall-clock execution time - goja's
+	// tree-walking interpreter exposes no separate CPU-time clock to measure
+	// against, so both ultimately become a context.WithTimeout. When both are
+	// set, the shorter one governs.
+	CPUTimeout  time.Duration
+	WallTimeout time.Duration
+	// MaxAllocBytes is the sandbox-wide default for ExecuteOptions.MaxHeapBytes.
+	MaxAllocBytes int64
+	// MaxLoopIterations is translated into a wall-clock budget via
+	// estimatedInstructionRate, the same approximation ExecuteOptions.
+	// MaxInstructions uses - goja exposes no bytecode-level counter a host
+	// can hook to count iterations directly.
+	MaxLoopIterations uint64
+	// Whitelist, when true, removes eval and the Function constructor from
+	// the VM's global object and skips injecting the jsBootstrap helpers
+	// (json, math, Counter, defaultdict, range, sorted, sum, min, max,
+	// enumerate, zip, any, all) and console/print/len - only identifiers the
+	// caller passed in env are visible to the script. This is a best-effort
+	// restriction (goja is a real ECMAScript engine; a sufficiently clever
+	// script can still reach a function's .constructor), not a hard
+	// guarantee, so it should be paired with CPUTimeout/WallTimeout/
+	// MaxAllocBytes rather than relied on alone.
+	Whitelist bool
+}
+
+// NewSandboxedREPLExecutor builds a REPLExecutor whose ExecuteContext calls
+// enforce config by default, on top of whatever ExecuteOptions a call passes
+// explicitly.
+func NewSandboxedREPLExecutor(config SandboxConfig) *REPLExecutor {
+	return &REPLExecutor{
+		maxOutputChars: 2000,
+		sandbox:        config,
+	}
+}
+
+// applySandboxDefaults fills in any ExecuteOptions field the caller left at
+// its zero value from r.sandbox, so a SandboxConfig set at construction time
+// applies to every call without repeating it.
+func (r *REPLExecutor) applySandboxDefaults(opts ExecuteOptions) ExecuteOptions {
+	if opts.Timeout == 0 {
+		if r.sandbox.WallTimeout > 0 {
+			opts.Timeout = r.sandbox.WallTimeout
+		}
+		if r.sandbox.CPUTimeout > 0 && (opts.Timeout == 0 || r.sandbox.CPUTimeout < opts.Timeout) {
+			opts.Timeout = r.sandbox.CPUTimeout
+		}
+	}
+	if opts.MaxInstructions == 0 && r.sandbox.MaxLoopIterations > 0 {
+		opts.MaxInstructions = r.sandbox.MaxLoopIterations
+	}
+	if opts.MaxHeapBytes == 0 && r.sandbox.MaxAllocBytes > 0 {
+		opts.MaxHeapBytes = r.sandbox.MaxAllocBytes
+	}
+	return opts
+}
+
+// sandboxLimitName reports which SandboxConfig field is responsible for the
+// effective budget ExecuteContext used, by the same precedence
+// applySandboxDefaults applies - used to label a *SandboxLimitError when a
+// sandbox-configured REPLExecutor's call is interrupted.
+func (r *REPLExecutor) sandboxLimitName(deadlineExceeded bool) string {
+	if !deadlineExceeded {
+		return "max_alloc_bytes"
+	}
+	switch {
+	case r.sandbox.CPUTimeout > 0 && (r.sandbox.WallTimeout == 0 || r.sandbox.CPUTimeout < r.sandbox.WallTimeout):
+		return "cpu_timeout"
+	case r.sandbox.WallTimeout > 0:
+		return "wall_timeout"
+	case r.sandbox.MaxLoopIterations > 0:
+		return "max_loop_iterations"
+	default:
+		return "timeout"
+	}
+}
+
+// ExecuteOptions bounds a single ExecuteContext call beyond whatever ctx
+// already provides. Every field is optional (its zero value disables that
+// limit) and they compose - whichever fires first wins.
+type ExecuteOptions struct {
+	// Timeout bounds wall-clock execution time, equivalent to layering an
+	// additional context.WithTimeout on top of ctx.
+	Timeout time.Duration
+	// MaxInstructions bounds script execution via estimatedInstructionRate:
+	// goja's public API has no bytecode-level instruction counter a host
+	// can query, so Interrupt() - the same mechanism Timeout uses - is the
+	// closest approximation available to "abort after N instructions"
+	// without forking goja.
+	MaxInstructions uint64
+	// MaxHeapBytes aborts the script once process heap allocation grows by
+	// more than this many bytes since the call started, sampled on a
+	// ticker. This is process-wide (goja exposes no per-Runtime heap
+	// accounting), so it's a blunt signal good for catching a runaway
+	// allocation loop, not precise per-script memory accounting.
+	MaxHeapBytes int64
+}
+
+// estimatedInstructionRate is a rough ops/sec figure for goja's
+// tree-walking interpreter, used only to convert ExecuteOptions.
+// MaxInstructions into the wall-clock deadline described in its doc comment.
+const estimatedInstructionRate = 5_000_000
+
+// ErrExecutionCanceled is the error ExecuteContext returns (wrapped in
+// *REPLError) when ctx is canceled before the script finishes.
+var ErrExecutionCanceled = errors.New("repl: execution canceled")
+
+// ErrExecutionDeadlineExceeded is the error ExecuteContext returns (wrapped
+// in *REPLError) when ctx's deadline, ExecuteOptions.Timeout, or
+// ExecuteOptions.MaxInstructions' estimated budget elapses before the
+// script finishes.
+var ErrExecutionDeadlineExceeded = errors.New("repl: execution deadline exceeded")
+
+// ErrExecutionHeapExceeded is the error ExecuteContext returns (wrapped in
+// *REPLError) when ExecuteOptions.MaxHeapBytes is exceeded.
+var ErrExecutionHeapExceeded = errors.New("repl: execution heap budget exceeded")
+
+// Execute runs code against env with no cancellation, deadline, or resource
+// limits - the original REPLExecutor entry point. Prefer ExecuteContext for
+// any caller that can supply a ctx, since an LLM-authored script can always
+// contain a runaway loop.
 func (r *REPLExecutor) Execute(code string, env map[string]interface{}) (string, error) {
+	return r.ExecuteContext(context.Background(), code, env, ExecuteOptions{})
+}
+
+// ExecuteContext runs code against env, aborting the script (via goja's
+// vm.Interrupt) if ctx is canceled, ctx's deadline elapses, or any limit in
+// opts is hit. A script that's interrupted returns *REPLError wrapping
+// ErrExecutionCanceled, ErrExecutionDeadlineExceeded, or
+// ErrExecutionHeapExceeded - callers can use errors.Is against those to
+// distinguish cancellation from an ordinary script error.
+func (r *REPLExecutor) ExecuteContext(ctx context.Context, code string, env map[string]interface{}, opts ExecuteOptions) (string, error) {
+	opts = r.applySandboxDefaults(opts)
+	sandboxed := r.sandbox != (SandboxConfig{})
+
+	output, err := r.executeContext(ctx, code, env, opts)
+	if sandboxed && err != nil {
+		var replErr *REPLError
+		if errors.As(err, &replErr) {
+			switch {
+			case errors.Is(replErr.Cause, ErrExecutionHeapExceeded):
+				return output, NewSandboxLimitError(r.sandboxLimitName(false), err)
+			case errors.Is(replErr.Cause, ErrExecutionDeadlineExceeded):
+				return output, NewSandboxLimitError(r.sandboxLimitName(true), err)
+			}
+		}
+	}
+	return output, err
+}
+
+// executeContext is ExecuteContext's implementation, before any
+// SandboxConfig error translation.
+func (r *REPLExecutor) executeContext(ctx context.Context, code string, env map[string]interface{}, opts ExecuteOptions) (string, error) {
 	code = extractCode(code)
 	if strings.TrimSpace(code) == "" {
 		return "No code to execute", nil
 	}
 
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	if opts.MaxInstructions > 0 {
+		budget := time.Duration(opts.MaxInstructions) * time.Second / time.Duration(estimatedInstructionRate)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", NewREPLError("Code execution error", code, ErrExecutionDeadlineExceeded)
+		}
+		return "", NewREPLError("Code execution error", code, ErrExecutionCanceled)
+	}
+
 	vm := goja.New()
 	var output strings.Builder
 
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				vm.Interrupt(ErrExecutionDeadlineExceeded)
+			} else {
+				vm.Interrupt(ErrExecutionCanceled)
+			}
+		case <-stop:
+		}
+	}()
+
+	if opts.MaxHeapBytes > 0 {
+		heapStop := make(chan struct{})
+		defer close(heapStop)
+		go watchHeap(vm, opts.MaxHeapBytes, heapStop)
+	}
+
 	for key, value := range env {
 		if err := vm.Set(key, value); err != nil {
 			return "", fmt.Errorf("failed to set environment variable %s: %w", key, err)
 		}
 	}
 
-	writeOutput := func(call goja.FunctionCall) goja.Value {
-		parts := make([]string, 0, len(call.Arguments))
-		for _, arg := range call.Arguments {
-			parts = append(parts, arg.String())
+	if r.sandbox.Whitelist {
+		if err := vm.GlobalObject().Set("eval", goja.Undefined()); err != nil {
+			return "", fmt.Errorf("failed to disable eval: %w", err)
+		}
+		if err := vm.GlobalObject().Set("Function", goja.Undefined()); err != nil {
+			return "", fmt.Errorf("failed to disable Function: %w", err)
+		}
+	} else {
+		writeOutput := func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				parts = append(parts, arg.String())
+			}
+			output.WriteString(strings.Join(parts, " "))
+			output.WriteString("\n")
+			return goja.Undefined()
 		}
-		output.WriteString(strings.Join(parts, " "))
-		output.WriteString("\n")
-		return goja.Undefined()
-	}
 
-	console := map[string]func(goja.FunctionCall) goja.Value{
-		"log": writeOutput,
-	}
+		console := map[string]func(goja.FunctionCall) goja.Value{
+			"log": writeOutput,
+		}
 
-	if err := vm.Set("console", console); err != nil {
-		return "", fmt.Errorf("failed to set console: %w", err)
-	}
-	if err := vm.Set("print", writeOutput); err != nil {
-		return "", fmt.Errorf("failed to set print: %w", err)
-	}
-	if err := vm.Set("len", func(value goja.Value) int {
-		if value == nil || value == goja.Undefined() || value == goja.Null() {
-			return 0
+		if err := vm.Set("console", console); err != nil {
+			return "", fmt.Errorf("failed to set console: %w", err)
+		}
+		if err := vm.Set("print", writeOutput); err != nil {
+			return "", fmt.Errorf("failed to set print: %w", err)
 		}
-		if exported := value.Export(); exported != nil {
-			switch typed := exported.(type) {
-			case string:
-				return len(typed)
-			case []interface{}:
-				return len(typed)
-			case map[string]interface{}:
-				return len(typed)
+		if err := vm.Set("len", func(value goja.Value) int {
+			if value == nil || value == goja.Undefined() || value == goja.Null() {
+				return 0
 			}
+			if exported := value.Export(); exported != nil {
+				switch typed := exported.(type) {
+				case string:
+					return len(typed)
+				case []interface{}:
+					return len(typed)
+				case map[string]interface{}:
+					return len(typed)
+				}
+			}
+			return len(value.String())
+		}); err != nil {
+			return "", fmt.Errorf("failed to set len: %w", err)
 		}
-		return len(value.String())
-	}); err != nil {
-		return "", fmt.Errorf("failed to set len: %w", err)
-	}
 
-	if _, err := vm.RunString(jsBootstrap); err != nil {
-		return "", NewREPLError("Bootstrap execution error", jsBootstrap, err)
+		if _, err := vm.RunString(jsBootstrap); err != nil {
+			return "", wrapExecutionError("Bootstrap execution error", jsBootstrap, err)
+		}
 	}
 
 	if _, err := vm.RunString(code); err != nil {
-		return "", NewREPLError("Code execution error", code, err)
+		return "", wrapExecutionError("Code execution error", code, err)
 	}
 
 	if output.Len() == 0 {
@@ -106,6 +316,49 @@ func (r *REPLExecutor) Execute(code string, env map[string]interface{}) (string,
 	return trimmedOutput, nil
 }
 
+// wrapExecutionError converts a vm.RunString error into *REPLError, except
+// when it's a goja *InterruptedError carrying one of our sentinel errors -
+// in that case it returns the sentinel directly (still wrapped in
+// *REPLError for code/message context) so errors.Is(err,
+// ErrExecutionCanceled) etc. works for the caller regardless of which limit
+// fired.
+func wrapExecutionError(message, code string, err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if sentinel, ok := interrupted.Value().(error); ok {
+			return NewREPLError(message, code, sentinel)
+		}
+	}
+	return NewREPLError(message, code, err)
+}
+
+// watchHeap polls process heap allocation every 25ms and interrupts vm once
+// it has grown by more than maxBytes since this call started, or until stop
+// is closed. It's started as its own goroutine per ExecuteContext call with
+// a non-zero ExecuteOptions.MaxHeapBytes.
+func watchHeap(vm *goja.Runtime, maxBytes int64, stop <-chan struct{}) {
+	var start runtime.MemStats
+	runtime.ReadMemStats(&start)
+	baseline := int64(start.HeapAlloc)
+
+	ticker := time.NewTicker(25 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			var current runtime.MemStats
+			runtime.ReadMemStats(&current)
+			if int64(current.HeapAlloc)-baseline > maxBytes {
+				vm.Interrupt(ErrExecutionHeapExceeded)
+				return
+			}
+		}
+	}
+}
+
 func extractCode(text string) string {
 	if strings.Contains(text, "```python") {
 		return extractBlock(text, "```python")
@@ -122,6 +375,31 @@ func extractCode(text string) string {
 	return text
 }
 
+// detectCodeLanguage reports which REPL a response's code fence should run
+// under, mirroring extractCode's own fence matching so the two stay in sync:
+// a ```python fence routes to PythonREPLExecutor, everything else
+// (```javascript, ```js, a bare ``` fence, or no fence at all) keeps running
+// on REPLExecutor's goja VM, its long-standing default.
+func detectCodeLanguage(text string) string {
+	if strings.Contains(text, "```python") {
+		return "python"
+	}
+	return "javascript"
+}
+
+// Executor runs one code block against env, the shape both REPLExecutor
+// (goja/JS) and PythonREPLExecutor (a python3 subprocess) implement so RLM
+// can dispatch a ```python fence to the latter instead of silently piping
+// Python source into the JS VM.
+type Executor interface {
+	ExecuteContext(ctx context.Context, code string, env map[string]interface{}, opts ExecuteOptions) (string, error)
+}
+
+var (
+	_ Executor = (*REPLExecutor)(nil)
+	_ Executor = (*PythonREPLExecutor)(nil)
+)
+
 const jsBootstrap = `
 const json = {
   loads: (text) => JSON.parse(text),
@@ -0,0 +1,115 @@
+package rlm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PythonREPLExecutor runs model-generated Python by shelling out to a
+// "python3 -I" subprocess per call (isolated mode: no user site-packages, no
+// implicit cwd on sys.path), so a ```python code fence actually runs Python
+// instead of being piped into REPLExecutor's goja (JS) VM - see
+// detectCodeLanguage. It shares ExecuteOptions/SandboxConfig with
+// REPLExecutor, enforced via ctx's deadline rather than goja's
+// vm.Interrupt/heap-watcher mechanism, since a subprocess has no equivalent
+// hook: killing the process via ctx cancellation is the whole story.
+type PythonREPLExecutor struct {
+	maxOutputChars int
+	pythonPath     string
+	sandbox        SandboxConfig
+}
+
+// NewPythonREPLExecutor builds a PythonREPLExecutor with no resource limits
+// beyond whatever ExecuteOptions a call passes explicitly.
+func NewPythonREPLExecutor() *PythonREPLExecutor {
+	return &PythonREPLExecutor{maxOutputChars: 2000, pythonPath: "python3"}
+}
+
+// NewSandboxedPythonREPLExecutor builds a PythonREPLExecutor whose
+// ExecuteContext calls enforce config's CPUTimeout/WallTimeout by default, on
+// top of whatever ExecuteOptions a call passes explicitly. MaxAllocBytes and
+// MaxLoopIterations have no subprocess equivalent (no in-process VM to
+// watch/interrupt) and are ignored.
+func NewSandboxedPythonREPLExecutor(config SandboxConfig) *PythonREPLExecutor {
+	return &PythonREPLExecutor{maxOutputChars: 2000, pythonPath: "python3", sandbox: config}
+}
+
+// ExecuteContext runs code (after stripping its code fence via extractCode)
+// as a python3 -I subprocess, aborting it if ctx is canceled, ctx's deadline
+// elapses, or opts.Timeout/the sandbox's CPUTimeout/WallTimeout fires first.
+func (p *PythonREPLExecutor) ExecuteContext(ctx context.Context, code string, env map[string]interface{}, opts ExecuteOptions) (string, error) {
+	code = extractCode(code)
+	if strings.TrimSpace(code) == "" {
+		return "No code to execute", nil
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = p.sandbox.WallTimeout
+		if p.sandbox.CPUTimeout > 0 && (timeout == 0 || p.sandbox.CPUTimeout < timeout) {
+			timeout = p.sandbox.CPUTimeout
+		}
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", NewREPLError("Code execution error", code, ErrExecutionDeadlineExceeded)
+		}
+		return "", NewREPLError("Code execution error", code, ErrExecutionCanceled)
+	}
+
+	preamble := pythonEnvPreamble(env)
+
+	cmd := exec.CommandContext(ctx, p.pythonPath, "-I", "-c", preamble+code)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", NewREPLError("Code execution error", code, ErrExecutionDeadlineExceeded)
+		}
+		return "", NewREPLError("Code execution error", code, ErrExecutionCanceled)
+	}
+	if runErr != nil {
+		return "", NewREPLError("Code execution error", code, fmt.Errorf("%s: %s", runErr, strings.TrimSpace(stderr.String())))
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return "Code executed successfully (no output)", nil
+	}
+	if len(output) > p.maxOutputChars {
+		return fmt.Sprintf("%s\n\n[Output truncated: %d chars total, showing first %d]", output[:p.maxOutputChars], len(output), p.maxOutputChars), nil
+	}
+	return output, nil
+}
+
+// pythonEnvPreamble renders env's JSON-marshalable entries as Python locals
+// assigned from json.loads of their encoded form - the same "globals visible
+// to the script" contract buildREPLEnv gives goja, minus recursive_llm/re:
+// those are Go closures/structs with no meaningful Python equivalent, and
+// json.Marshal simply fails (silently skipped below) for the func value.
+func pythonEnvPreamble(env map[string]interface{}) string {
+	var b strings.Builder
+	b.WriteString("import json as _json\n")
+	for key, value := range env {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s = _json.loads(%q)\n", key, string(encoded))
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package rlm
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestREPLExecutor_ExecuteContext_TimeoutInterruptsRunawayLoop(t *testing.T) {
+	repl := NewREPLExecutor()
+
+	_, err := repl.ExecuteContext(context.Background(), "while (true) {}", nil, ExecuteOptions{Timeout: 50 * time.Millisecond})
+	if err == nil {
+		t.Fatal("ExecuteContext() error = nil, want a deadline-exceeded error for a runaway loop")
+	}
+	if !errors.Is(err, ErrExecutionDeadlineExceeded) {
+		t.Errorf("ExecuteContext() error = %v, want errors.Is(err, ErrExecutionDeadlineExceeded)", err)
+	}
+}
+
+func TestREPLExecutor_ExecuteContext_CanceledContext(t *testing.T) {
+	repl := NewREPLExecutor()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repl.ExecuteContext(ctx, "1 + 1", nil, ExecuteOptions{})
+	if !errors.Is(err, ErrExecutionCanceled) {
+		t.Errorf("ExecuteContext() error = %v, want errors.Is(err, ErrExecutionCanceled) for an already-canceled ctx", err)
+	}
+}
+
+func TestREPLExecutor_ExecuteContext_MaxHeapBytesInterrupts(t *testing.T) {
+	repl := NewREPLExecutor()
+
+	code := `
+	let data = [];
+	while (true) {
+		data.push(new Array(1000000).fill("x"));
+	}
+	`
+	_, err := repl.ExecuteContext(context.Background(), code, nil, ExecuteOptions{MaxHeapBytes: 1, Timeout: 5 * time.Second})
+	if err == nil {
+		t.Fatal("ExecuteContext() error = nil, want a heap-exceeded error for an allocation loop")
+	}
+	if !errors.Is(err, ErrExecutionHeapExceeded) {
+		t.Errorf("ExecuteContext() error = %v, want errors.Is(err, ErrExecutionHeapExceeded)", err)
+	}
+}
+
+func TestREPLExecutor_Execute_UnaffectedByNoOptions(t *testing.T) {
+	repl := NewREPLExecutor()
+
+	result, err := repl.Execute("1 + 1", nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result != "2" {
+		t.Errorf("Execute() = %q, want %q", result, "2")
+	}
+}
+
+func TestNewSandboxedREPLExecutor_WallTimeoutReturnsSandboxLimitError(t *testing.T) {
+	repl := NewSandboxedREPLExecutor(SandboxConfig{WallTimeout: 50 * time.Millisecond})
+
+	_, err := repl.ExecuteContext(context.Background(), "while (true) {}", nil, ExecuteOptions{})
+
+	var limitErr *SandboxLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("ExecuteContext() error = %v, want a *SandboxLimitError", err)
+	}
+	if limitErr.Limit != "wall_timeout" {
+		t.Errorf("SandboxLimitError.Limit = %q, want %q", limitErr.Limit, "wall_timeout")
+	}
+	if !errors.Is(err, ErrExecutionDeadlineExceeded) {
+		t.Errorf("ExecuteContext() error = %v, want errors.Is(err, ErrExecutionDeadlineExceeded)", err)
+	}
+}
+
+func TestNewSandboxedREPLExecutor_WhitelistDisablesBootstrapAndEval(t *testing.T) {
+	repl := NewSandboxedREPLExecutor(SandboxConfig{Whitelist: true})
+
+	_, err := repl.Execute(`Counter("aab")`, nil)
+	if err == nil {
+		t.Error("Execute() error = nil, want an error since Counter isn't injected in whitelist mode")
+	}
+
+	_, err = repl.Execute(`eval("1 + 1")`, nil)
+	if err == nil {
+		t.Error("Execute() error = nil, want an error since eval is disabled in whitelist mode")
+	}
+}
+
+func TestNewSandboxedREPLExecutor_WhitelistAllowsExplicitEnv(t *testing.T) {
+	repl := NewSandboxedREPLExecutor(SandboxConfig{Whitelist: true})
+
+	result, err := repl.Execute("x + 1", map[string]interface{}{"x": 41})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result != "42" {
+		t.Errorf("Execute() = %q, want %q", result, "42")
+	}
+}
+
+func TestDetectCodeLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"python fence", "```python\nprint(1)\n```", "python"},
+		{"js fence", "```js\n1 + 1\n```", "javascript"},
+		{"javascript fence", "```javascript\n1 + 1\n```", "javascript"},
+		{"bare fence", "```\n1 + 1\n```", "javascript"},
+		{"no fence", "1 + 1", "javascript"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCodeLanguage(tt.text); got != tt.want {
+				t.Errorf("detectCodeLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPythonEnvPreamble_SkipsUnmarshalableValues(t *testing.T) {
+	preamble := pythonEnvPreamble(map[string]interface{}{
+		"query":         "hello",
+		"recursive_llm": func(string, string) string { return "" },
+	})
+
+	if !strings.Contains(preamble, `query = _json.loads("\"hello\"")`) {
+		t.Errorf("pythonEnvPreamble() = %q, want it to assign query from a json.loads call", preamble)
+	}
+	if strings.Contains(preamble, "recursive_llm") {
+		t.Errorf("pythonEnvPreamble() = %q, want the unmarshalable recursive_llm func to be skipped", preamble)
+	}
+}
+
+func TestPythonREPLExecutor_ExecuteContext_RunsPython(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available in this environment")
+	}
+
+	repl := NewPythonREPLExecutor()
+	result, err := repl.ExecuteContext(context.Background(), "```python\nprint(1 + 1)\n```", nil, ExecuteOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteContext() error = %v", err)
+	}
+	if result != "2" {
+		t.Errorf("ExecuteContext() = %q, want %q", result, "2")
+	}
+}
+
+func TestPythonREPLExecutor_ExecuteContext_TimeoutInterruptsRunawayLoop(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available in this environment")
+	}
+
+	repl := NewPythonREPLExecutor()
+	_, err := repl.ExecuteContext(context.Background(), "```python\nwhile True: pass\n```", nil, ExecuteOptions{Timeout: 50 * time.Millisecond})
+	if !errors.Is(err, ErrExecutionDeadlineExceeded) {
+		t.Errorf("ExecuteContext() error = %v, want errors.Is(err, ErrExecutionDeadlineExceeded)", err)
+	}
+}
+
+func TestNew_ConfigSandboxAppliesToJSAndPythonExecutors(t *testing.T) {
+	sandbox := SandboxConfig{WallTimeout: time.Second}
+	r := New("gpt-4o", Config{Sandbox: &sandbox})
+
+	if r.repl.sandbox != sandbox {
+		t.Errorf("r.repl.sandbox = %+v, want %+v", r.repl.sandbox, sandbox)
+	}
+	if r.pyRepl.sandbox != sandbox {
+		t.Errorf("r.pyRepl.sandbox = %+v, want %+v", r.pyRepl.sandbox, sandbox)
+	}
+}
+
+func TestRLM_ExecuteREPL_DispatchesPythonFenceToPythonExecutor(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available in this environment")
+	}
+
+	r := New("gpt-4o", Config{})
+	result, err := r.executeREPL(context.Background(), "```python\nprint(21 * 2)\n```", nil)
+	if err != nil {
+		t.Fatalf("executeREPL() error = %v", err)
+	}
+	if result != "42" {
+		t.Errorf("executeREPL() = %q, want %q", result, "42")
+	}
+}
@@ -0,0 +1,84 @@
+package rlm
+
+// This file implements a record/replay harness for ObservabilityEvent
+// streams: capture a session with a Recorder, persist it with
+// WriteEventsNDJSON, reload it with ReadEventsNDJSON, and reissue it
+// against a different Observer with Replay. This enables offline analysis,
+// deterministic tests of meta-agent decisions, and re-exporting a session
+// to a different backend (e.g. Langfuse) without re-running the LLM calls
+// that produced it.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+)
+
+// NewRecorder returns an Observer configured purely to capture its
+// ObservabilityEvent stream - no tracer, no companion Meter, no Langfuse
+// ingestion, just recordEvent's existing append to o.events (which happens
+// for every Observer, recording or not). It's the record side of this
+// file's harness: a Recorder is the Observer you pass around during a real
+// run, then hand its GetEvents()/WriteEventsNDJSON output to Replay later.
+func NewRecorder() *Observer {
+	return &Observer{
+		events: make([]ObservabilityEvent, 0),
+		logger: log.New(io.Discard, "", 0),
+	}
+}
+
+// Replay reissues a previously recorded event stream against target,
+// running each event back through target's sinks (OnEvent callback,
+// Langfuse ingestion) in Seq order, regardless of the order events were
+// passed in. Pair it with NewRecorder or ReadEventsNDJSON to re-export a
+// session to a different backend, or to replay it into a test double's
+// OnEvent callback for a deterministic assertion against recorded
+// meta-agent decisions.
+//
+// Replay does not recreate OTel spans on target: events recorded with a
+// tracer enabled already carry their TraceID/SpanID/ParentID, but turning
+// those back into live spans needs the OTel SDK's span-builder APIs, not
+// just Observer's own surface. Only the generic event-sink side runs.
+func Replay(events []ObservabilityEvent, target *Observer) {
+	ordered := make([]ObservabilityEvent, len(events))
+	copy(ordered, events)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Seq < ordered[j].Seq })
+
+	for _, event := range ordered {
+		target.recordEvent(event)
+	}
+}
+
+// WriteEventsNDJSON writes the Observer's captured events as newline-
+// delimited JSON, one ObservabilityEvent per line, in recorded order. Pair
+// with ReadEventsNDJSON and Replay to persist a session and reissue it
+// later.
+func (o *Observer) WriteEventsNDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, event := range o.GetEvents() {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("encode event (seq %d): %w", event.Seq, err)
+		}
+	}
+	return nil
+}
+
+// ReadEventsNDJSON reads a newline-delimited ObservabilityEvent stream
+// previously written by WriteEventsNDJSON.
+func ReadEventsNDJSON(r io.Reader) ([]ObservabilityEvent, error) {
+	var events []ObservabilityEvent
+	dec := json.NewDecoder(r)
+	for {
+		var event ObservabilityEvent
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decode event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
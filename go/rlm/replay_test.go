@@ -0,0 +1,72 @@
+package rlm
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRecorder_CapturesEventsInOrder(t *testing.T) {
+	rec := NewRecorder()
+
+	rec.Event("first", nil)
+	rec.Event("second", nil)
+	rec.LLMCall(LLMCallInfo{Model: "gpt-4o-mini", MessageCount: 1}, time.Second, nil)
+
+	events := rec.GetEvents()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 recorded events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.Seq != uint64(i+1) {
+			t.Errorf("events[%d].Seq = %d, want %d", i, event.Seq, i+1)
+		}
+	}
+}
+
+func TestWriteReadEventsNDJSON_RoundTrip(t *testing.T) {
+	rec := NewRecorder()
+	rec.Event("alpha", map[string]string{"k": "v"})
+	rec.Event("beta", nil)
+
+	var buf bytes.Buffer
+	if err := rec.WriteEventsNDJSON(&buf); err != nil {
+		t.Fatalf("WriteEventsNDJSON() error = %v", err)
+	}
+
+	events, err := ReadEventsNDJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadEventsNDJSON() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events round-tripped, got %d", len(events))
+	}
+	if events[0].Name != "alpha" || events[0].Attributes["k"] != "v" {
+		t.Errorf("events[0] = %+v, want Name=alpha Attributes[k]=v", events[0])
+	}
+	if events[1].Name != "beta" {
+		t.Errorf("events[1].Name = %q, want beta", events[1].Name)
+	}
+}
+
+func TestReplay_ReissuesEventsInSeqOrder(t *testing.T) {
+	var gotNames []string
+	target := NewObserver(ObservabilityConfig{
+		OnEvent: func(event ObservabilityEvent) {
+			gotNames = append(gotNames, event.Name)
+		},
+	})
+	defer target.Shutdown()
+
+	// Pass events out of Seq order; Replay should still reissue them
+	// sorted by Seq rather than by slice position.
+	events := []ObservabilityEvent{
+		{Name: "second", Seq: 2},
+		{Name: "first", Seq: 1},
+	}
+	Replay(events, target)
+
+	if len(gotNames) != 2 || gotNames[0] != "first" || gotNames[1] != "second" {
+		t.Errorf("replayed names = %v, want [first second]", gotNames)
+	}
+}
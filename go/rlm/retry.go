@@ -0,0 +1,172 @@
+package rlm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures CallChatCompletion's retry behavior for transient
+// failures (rate limiting, 5xx responses, network timeouts). A nil
+// *RetryPolicy on ChatRequest disables retries entirely, preserving the
+// single-attempt behavior callers already depend on.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first -
+	// MaxAttempts=3 means up to 2 retries after an initial failure.
+	MaxAttempts int
+	// BaseDelay is the backoff delay before the first retry. Defaults to
+	// 500ms when zero.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay. Defaults to 30s when zero.
+	MaxDelay time.Duration
+	// RetryableStatus overrides which HTTP status codes are retried. When
+	// nil, (*APIError).IsRetryable is used.
+	RetryableStatus func(statusCode int) bool
+}
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+	retryBackoffFactor    = 2
+)
+
+// backoffDelay computes the exponential-backoff-with-full-jitter delay
+// before a retry (0-indexed: the delay before the 2nd attempt, the first
+// retry, is backoffDelay(0, ...)), per the "Full Jitter" approach: a uniformly random
+// duration between 0 and min(cap, base*factor^attempt).
+func backoffDelay(attempt int, base, maxDelay time.Duration) time.Duration {
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	upper := float64(base)
+	for i := 0; i < attempt; i++ {
+		upper *= retryBackoffFactor
+		if upper >= float64(maxDelay) {
+			upper = float64(maxDelay)
+			break
+		}
+	}
+
+	return time.Duration(rand.Int63n(int64(upper) + 1))
+}
+
+// retryDelay picks the delay before the next attempt: retryAfter (parsed
+// from the previous response's Retry-After header) when positive, otherwise
+// the computed exponential backoff.
+func retryDelay(attempt int, retryAfter time.Duration, policy RetryPolicy) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	return backoffDelay(attempt, policy.BaseDelay, policy.MaxDelay)
+}
+
+// isRetryableError reports whether err is a transient failure this package
+// knows how to retry: a *APIError satisfying IsRetryable (or the policy's
+// RetryableStatus override), or a network-level timeout/connection reset.
+func isRetryableError(err error, policy RetryPolicy) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if policy.RetryableStatus != nil {
+			return policy.RetryableStatus(apiErr.StatusCode)
+		}
+		return apiErr.IsRetryable()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter parses an HTTP Retry-After header value, either a number
+// of seconds or an HTTP-date. Returns zero when value is empty or
+// unparseable.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(when); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// withRetry runs attempt - one backend request/response round trip -
+// retrying per request.Retry's policy the same way callOpenAICompatible's
+// own loop does: exponential backoff with full jitter, honoring whatever
+// Retry-After delay attempt reports, and bailing out immediately on a
+// non-retryable error, and wrapping the final error in a RetriesExhaustedError
+// once the last attempt is used up. A nil request.Retry runs attempt exactly
+// once. Backends that don't build their own retry loop (anthropicBackend,
+// geminiBackend, ollamaBackend) wrap their single HTTP round trip in this so
+// request.Retry behaves identically across providers.
+func withRetry(ctx context.Context, request ChatRequest, attempt func(context.Context) (ChatResponse, time.Duration, error)) (ChatResponse, error) {
+	if request.Retry == nil {
+		response, _, err := attempt(ctx)
+		return response, err
+	}
+
+	policy := *request.Retry
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var lastErr error
+	for i := 1; i <= maxAttempts; i++ {
+		response, retryAfter, err := attempt(ctx)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+
+		if !isRetryableError(err, policy) {
+			return ChatResponse{}, err
+		}
+		if i == maxAttempts {
+			break
+		}
+		if sleepErr := sleepOrAbort(ctx, retryDelay(i-1, retryAfter, policy)); sleepErr != nil {
+			return ChatResponse{}, wrapCancelled(ctx, sleepErr)
+		}
+	}
+
+	return ChatResponse{}, NewRetriesExhaustedError(maxAttempts, time.Since(start), lastErr)
+}
+
+// sleepOrAbort waits for d, returning ctx.Err() immediately if ctx is
+// canceled or its deadline elapses first.
+func sleepOrAbort(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
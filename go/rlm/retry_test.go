@@ -0,0 +1,245 @@
+package rlm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallChatCompletionRetriesOnRetryableStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"overloaded"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	content, err := CallChatCompletionContext(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+		Retry: &RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    5 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallChatCompletionContext() error = %v", err)
+	}
+	if content != "ok" {
+		t.Errorf("CallChatCompletionContext() = %q, want %q", content, "ok")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("server received %d calls, want 3", got)
+	}
+}
+
+func TestCallChatCompletionRetriesExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	}))
+	defer server.Close()
+
+	_, err := CallChatCompletionContext(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+		Retry: &RetryPolicy{
+			MaxAttempts: 2,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    5 * time.Millisecond,
+		},
+	})
+
+	var exhausted *RetriesExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("CallChatCompletionContext() error = %v, want *RetriesExhaustedError", err)
+	}
+	if exhausted.Attempts != 2 {
+		t.Errorf("RetriesExhaustedError.Attempts = %d, want 2", exhausted.Attempts)
+	}
+}
+
+func TestCallChatCompletionNoRetryByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := CallChatCompletionContext(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+	})
+	if err == nil {
+		t.Fatal("CallChatCompletionContext() error = nil, want an error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("server received %d calls, want 1 (no retries without ChatRequest.Retry)", got)
+	}
+}
+
+func TestCallChatCompletionHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	content, err := CallChatCompletionContext(context.Background(), ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+		Retry:   &RetryPolicy{MaxAttempts: 2},
+	})
+	if err != nil {
+		t.Fatalf("CallChatCompletionContext() error = %v", err)
+	}
+	if content != "ok" {
+		t.Errorf("CallChatCompletionContext() = %q, want %q", content, "ok")
+	}
+}
+
+func TestCallChatCompletionAbortsOnCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CallChatCompletionContext(ctx, ChatRequest{
+		APIBase: server.URL,
+		Model:   "gpt-4o",
+		Retry: &RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   time.Second,
+		},
+	})
+
+	var cancelled *CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("CallChatCompletionContext() error = %v, want *CancelledError", err)
+	}
+}
+
+func TestWithRetry_RetriesUntilAttemptSucceeds(t *testing.T) {
+	var calls int32
+	request := ChatRequest{
+		Retry: &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+	}
+
+	response, err := withRetry(context.Background(), request, func(ctx context.Context) (ChatResponse, time.Duration, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return ChatResponse{}, 0, NewAPIError(http.StatusServiceUnavailable, "overloaded")
+		}
+		return ChatResponse{Content: "ok"}, 0, nil
+	})
+	if err != nil {
+		t.Fatalf("withRetry() error = %v", err)
+	}
+	if response.Content != "ok" {
+		t.Errorf("withRetry() content = %q, want %q", response.Content, "ok")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("attempt ran %d times, want 3", got)
+	}
+}
+
+func TestWithRetry_NilPolicyRunsOnce(t *testing.T) {
+	var calls int32
+	_, err := withRetry(context.Background(), ChatRequest{}, func(ctx context.Context) (ChatResponse, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return ChatResponse{}, 0, NewAPIError(http.StatusServiceUnavailable, "overloaded")
+	})
+	if err == nil {
+		t.Fatal("withRetry() error = nil, want an error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("attempt ran %d times, want 1 (no ChatRequest.Retry)", got)
+	}
+}
+
+func TestWithRetry_NonRetryableErrorStopsImmediately(t *testing.T) {
+	var calls int32
+	request := ChatRequest{Retry: &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond}}
+
+	_, err := withRetry(context.Background(), request, func(ctx context.Context) (ChatResponse, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return ChatResponse{}, 0, NewAPIError(http.StatusBadRequest, "bad request")
+	})
+	if err == nil {
+		t.Fatal("withRetry() error = nil, want an error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("attempt ran %d times, want 1 (400 is not retryable)", got)
+	}
+}
+
+func TestAnthropicBackend_ChatRetriesOnRetryableStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"overloaded"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	backend := &anthropicBackend{apiBase: server.URL}
+	response, err := backend.Chat(context.Background(), ChatRequest{
+		Model:    "claude-3-opus",
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Retry:    &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("anthropicBackend.Chat() error = %v", err)
+	}
+	if response.Content != "ok" {
+		t.Errorf("anthropicBackend.Chat() content = %q, want %q", response.Content, "ok")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("server received %d calls, want 3", got)
+	}
+}
+
+func TestNew_ConfigRetryPropagatesToChatRequest(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3}
+	r := New("gpt-4o", Config{Retry: policy})
+	if r.retry != policy {
+		t.Errorf("New() RLM.retry = %v, want %v", r.retry, policy)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("parseRetryAfter(%q) = %v, want 0", "", got)
+	}
+	if got := parseRetryAfter("5"); got != 5*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, want 5s", "5", got)
+	}
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	got := parseRetryAfter(future)
+	if got <= 0 || got > 11*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, want ~10s", future, got)
+	}
+}
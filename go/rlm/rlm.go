@@ -1,8 +1,13 @@
 package rlm
 
 import (
+	stdcontext "context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
+
+	"github.com/jbeck018/recursive-llm-ts/go/grammar"
 )
 
 type RLM struct {
@@ -12,14 +17,23 @@ type RLM struct {
 	apiKey           string
 	maxDepth         int
 	maxIterations    int
+	maxTokens        int
 	timeoutSeconds   int
 	useMetacognitive bool
 	extraParams      map[string]interface{}
 	currentDepth     int
 	repl             *REPLExecutor
+	pyRepl           *PythonREPLExecutor
 	stats            RLMStats
 	observer         *Observer
 	metaAgent        *MetaAgent
+	toolRegistry     *ToolRegistry
+	structured       *StructuredConfig
+	backend          Backend
+	metrics          MetricsObserver
+	retry            *RetryPolicy
+	detailedStats    bool
+	sandbox          *SandboxConfig
 }
 
 func New(model string, config Config) *RLM {
@@ -36,6 +50,18 @@ func New(model string, config Config) *RLM {
 		obs = NewNoopObserver()
 	}
 
+	metrics := config.MetricsObserver
+	if metrics == nil {
+		metrics = noopMetricsObserver{}
+	}
+
+	repl := NewREPLExecutor()
+	pyRepl := NewPythonREPLExecutor()
+	if config.Sandbox != nil {
+		repl = NewSandboxedREPLExecutor(*config.Sandbox)
+		pyRepl = NewSandboxedPythonREPLExecutor(*config.Sandbox)
+	}
+
 	r := &RLM{
 		model:            model,
 		recursiveModel:   recursiveModel,
@@ -43,13 +69,22 @@ func New(model string, config Config) *RLM {
 		apiKey:           config.APIKey,
 		maxDepth:         config.MaxDepth,
 		maxIterations:    config.MaxIterations,
+		maxTokens:        config.MaxTokens,
 		timeoutSeconds:   config.TimeoutSeconds,
 		useMetacognitive: config.UseMetacognitive,
 		extraParams:      config.ExtraParams,
 		currentDepth:     0,
-		repl:             NewREPLExecutor(),
+		repl:             repl,
+		pyRepl:           pyRepl,
 		stats:            RLMStats{},
 		observer:         obs,
+		toolRegistry:     config.ToolRegistry,
+		structured:       config.Structured,
+		backend:          resolveBackend(model, config),
+		metrics:          metrics,
+		retry:            config.Retry,
+		detailedStats:    config.DetailedStats,
+		sandbox:          config.Sandbox,
 	}
 
 	// Setup meta-agent if enabled
@@ -61,7 +96,16 @@ func New(model string, config Config) *RLM {
 }
 
 func (r *RLM) Completion(query string, context string) (string, RLMStats, error) {
-	ctx := r.observer.StartTrace("rlm.completion", map[string]string{
+	return r.CompletionContext(stdcontext.Background(), query, context)
+}
+
+// CompletionContext runs Completion bound to ctx: the LLM call for the
+// current iteration is aborted if ctx is canceled or its deadline elapses,
+// and the iteration loop itself stops early and returns ctx.Err() rather
+// than starting another round trip. Recursive calls made via recursive_llm()
+// inherit the same ctx.
+func (r *RLM) CompletionContext(ctx stdcontext.Context, query string, context string) (string, RLMStats, error) {
+	ctx = r.observer.StartTrace(ctx, "rlm.completion", map[string]string{
 		"model":          r.model,
 		"query_length":   fmt.Sprintf("%d", len(query)),
 		"context_length": fmt.Sprintf("%d", len(context)),
@@ -80,7 +124,7 @@ func (r *RLM) Completion(query string, context string) (string, RLMStats, error)
 
 	// Apply meta-agent optimization if enabled
 	if r.metaAgent != nil && r.currentDepth == 0 {
-		optimized, err := r.metaAgent.OptimizeQuery(query, context)
+		optimized, err := r.metaAgent.OptimizeQuery(ctx, query, context)
 		if err == nil && optimized != "" {
 			r.observer.Debug("rlm", "Using meta-agent optimized query")
 			query = optimized
@@ -88,18 +132,36 @@ func (r *RLM) Completion(query string, context string) (string, RLMStats, error)
 	}
 
 	r.stats.Depth = r.currentDepth
-	replEnv := r.buildREPLEnv(query, context)
+	replEnv := r.buildREPLEnv(ctx, query, context)
 	systemPrompt := BuildSystemPrompt(len(context), r.currentDepth, query, r.useMetacognitive)
+	if r.toolRegistry != nil {
+		systemPrompt += buildToolsPrompt(r.toolRegistry.List())
+	}
 	messages := []Message{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: query},
 	}
 
+	llmExtra := r.grammarExtra()
+	structuredRetries := 0
+
 	for iteration := 0; iteration < r.maxIterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			r.observer.Debug("rlm", "Completion canceled before iteration %d: %v", iteration+1, err)
+			return "", r.stats, err
+		}
+
+		if r.maxTokens > 0 && r.stats.TotalTokens >= r.maxTokens {
+			r.observer.Debug("rlm", "Token budget (%d) exceeded before iteration %d", r.maxTokens, iteration+1)
+			return "", r.stats, NewMaxTokensError(r.maxTokens, r.stats.TotalTokens)
+		}
+
 		r.stats.Iterations = iteration + 1
+		r.metrics.OnIteration(r.currentDepth, iteration+1)
+		r.observer.RecordIteration(r.currentDepth)
 		r.observer.Debug("rlm", "Iteration %d/%d at depth %d", iteration+1, r.maxIterations, r.currentDepth)
 
-		response, err := r.callLLM(messages)
+		response, err := r.callLLM(ctx, messages, llmExtra, iteration)
 		if err != nil {
 			r.observer.Error("rlm", "LLM call failed on iteration %d: %v", iteration+1, err)
 			return "", r.stats, err
@@ -108,31 +170,113 @@ func (r *RLM) Completion(query string, context string) (string, RLMStats, error)
 		if IsFinal(response) {
 			answer, ok := ParseResponse(response, replEnv)
 			if ok {
+				if r.structured != nil && r.structured.Schema != nil {
+					if validationErr := validateFinalAnswer(answer, r.structured.Schema); validationErr != nil {
+						maxRetries := r.structured.MaxRetries
+						if maxRetries == 0 {
+							maxRetries = 3
+						}
+						structuredRetries++
+						r.stats.ParsingRetries = structuredRetries
+						r.observer.RecordParsingRetries(r.currentDepth, 1)
+						r.observer.Debug("rlm", "FINAL answer failed schema validation (attempt %d/%d): %v", structuredRetries, maxRetries, validationErr)
+
+						if structuredRetries >= maxRetries {
+							return "", r.stats, fmt.Errorf("final answer did not validate against schema after %d attempts: %w", structuredRetries, validationErr)
+						}
+
+						messages = append(messages,
+							Message{Role: "assistant", Content: response},
+							Message{Role: "user", Content: buildValidationFeedback(validationErr, r.structured.Schema, answer)},
+						)
+						continue
+					}
+				}
+
 				r.observer.Debug("rlm", "FINAL answer found on iteration %d", iteration+1)
 				r.observer.Event("rlm.completion_success", map[string]string{
 					"iterations": fmt.Sprintf("%d", iteration+1),
 					"llm_calls":  fmt.Sprintf("%d", r.stats.LlmCalls),
 				})
+				r.metrics.OnFinal(r.currentDepth, structuredRetries)
+				if r.detailedStats && r.currentDepth == 0 {
+					r.stats.PerDepth = depthStatsFromCalls(r.stats.Calls)
+				}
 				return answer, r.stats, nil
 			}
 		}
 
-		execResult, err := r.repl.Execute(response, replEnv)
+		if r.toolRegistry != nil {
+			if call, ok := ParseToolCall(response); ok {
+				r.stats.ToolCalls++
+				r.observer.RecordToolCall(call.Name)
+				r.observer.Debug("rlm", "Tool call: %s(%s)", call.Name, truncateStr(string(call.Args), 200))
+
+				toolResult, err := r.toolRegistry.Invoke(ctx, call.Name, call.Args)
+				if err != nil {
+					r.observer.Error("rlm", "Tool call %s failed: %v", call.Name, err)
+					toolResult = fmt.Sprintf("Error: %s", err.Error())
+				}
+
+				messages = append(messages, Message{Role: "assistant", Content: response})
+				messages = append(messages, Message{Role: "user", Content: toolResult})
+				continue
+			}
+		}
+
+		replStart := time.Now()
+		execResult, err := r.executeREPL(ctx, response, replEnv)
+		r.metrics.OnREPLExec(r.currentDepth, err == nil, time.Since(replStart))
 		if err != nil {
 			r.observer.Debug("rlm", "REPL execution error: %v", err)
 			execResult = fmt.Sprintf("Error: %s", err.Error())
 		} else {
 			r.observer.Debug("rlm", "REPL output: %s", truncateStr(execResult, 200))
 		}
+		r.recordREPLExec(err)
 
 		messages = append(messages, Message{Role: "assistant", Content: response})
 		messages = append(messages, Message{Role: "user", Content: execResult})
 	}
 
+	if r.detailedStats && r.currentDepth == 0 {
+		r.stats.PerDepth = depthStatsFromCalls(r.stats.Calls)
+	}
 	return "", r.stats, NewMaxIterationsError(r.maxIterations)
 }
 
-func (r *RLM) callLLM(messages []Message) (string, error) {
+// executeREPL dispatches response's code fence to the JS REPL (r.repl,
+// goja) or, for a ```python fence, r.pyRepl - see detectCodeLanguage - so a
+// model emitting Python actually runs Python instead of having it piped
+// into the JS VM.
+func (r *RLM) executeREPL(ctx stdcontext.Context, response string, env map[string]interface{}) (string, error) {
+	if detectCodeLanguage(response) == "python" {
+		return r.pyRepl.ExecuteContext(ctx, response, env, ExecuteOptions{})
+	}
+	return r.repl.ExecuteContext(ctx, response, env, ExecuteOptions{})
+}
+
+// recordREPLExec backfills REPLExecs/REPLErrors onto the most recently
+// recorded CallRecord, when Config.DetailedStats is enabled. Only the plain
+// REPL-exec branch of CompletionContext's loop calls this, since the
+// FINAL-answer and tool-call branches don't run the REPL.
+func (r *RLM) recordREPLExec(execErr error) {
+	if !r.detailedStats || len(r.stats.Calls) == 0 {
+		return
+	}
+	last := &r.stats.Calls[len(r.stats.Calls)-1]
+	last.REPLExecs++
+	if execErr != nil {
+		last.REPLErrors++
+	}
+}
+
+// callLLM issues one chat completion call. extra, if non-nil, overlays
+// r.extraParams for this call only (e.g. a "grammar" param for structured
+// completions) without mutating shared RLM state. iteration identifies the
+// caller's own loop position, recorded onto RLMStats.Calls when
+// Config.DetailedStats is enabled.
+func (r *RLM) callLLM(ctx stdcontext.Context, messages []Message, extra map[string]interface{}, iteration int) (string, error) {
 	r.stats.LlmCalls++
 	defaultModel := r.model
 	if r.currentDepth > 0 {
@@ -143,41 +287,348 @@ func (r *RLM) callLLM(messages []Message) (string, error) {
 
 	start := time.Now()
 
+	params := r.extraParams
+	if len(extra) > 0 {
+		params = make(map[string]interface{}, len(r.extraParams)+len(extra))
+		for k, v := range r.extraParams {
+			params[k] = v
+		}
+		for k, v := range extra {
+			params[k] = v
+		}
+	}
+
 	request := ChatRequest{
 		Model:       defaultModel,
 		Messages:    messages,
 		APIBase:     r.apiBase,
 		APIKey:      r.apiKey,
 		Timeout:     r.timeoutSeconds,
-		ExtraParams: r.extraParams,
+		ExtraParams: params,
+		Retry:       r.retry,
 	}
 
-	result, err := CallChatCompletion(request)
+	response, err := r.backend.Chat(ctx, request)
 	duration := time.Since(start)
 
-	r.observer.LLMCall(defaultModel, len(messages), 0, duration, err)
+	info := LLMCallInfo{
+		Model:        defaultModel,
+		MessageCount: len(messages),
+		Temperature:  extractTemperature(params),
+	}
+	if r.observer.config.CaptureContent {
+		info.Prompt = serializeMessages(messages)
+	}
 
 	if err != nil {
+		r.observer.LLMCall(info, duration, err)
+		r.metrics.OnLLMCall(r.currentDepth, defaultModel, 0, 0, duration)
+		if r.detailedStats {
+			r.stats.Calls = append(r.stats.Calls, CallRecord{
+				Depth:     r.currentDepth,
+				Iteration: iteration,
+				Model:     defaultModel,
+				LatencyMs: duration.Milliseconds(),
+				Err:       err.Error(),
+			})
+		}
 		return "", err
 	}
 
-	r.observer.Debug("llm", "Response received (%d chars) in %s", len(result), duration)
-	return result, nil
+	r.stats.PromptTokens += response.Usage.PromptTokens
+	r.stats.CompletionTokens += response.Usage.CompletionTokens
+	r.stats.TotalTokens += response.Usage.TotalTokens
+	r.metrics.OnLLMCall(r.currentDepth, defaultModel, response.Usage.PromptTokens, response.Usage.CompletionTokens, duration)
+
+	if r.detailedStats {
+		r.stats.Calls = append(r.stats.Calls, CallRecord{
+			Depth:            r.currentDepth,
+			Iteration:        iteration,
+			Model:            defaultModel,
+			PromptTokens:     response.Usage.PromptTokens,
+			CompletionTokens: response.Usage.CompletionTokens,
+			LatencyMs:        duration.Milliseconds(),
+			FinishReason:     response.FinishReason,
+		})
+	}
+
+	info.PromptTokens = response.Usage.PromptTokens
+	info.CompletionTokens = response.Usage.CompletionTokens
+	info.Completion = response.Content
+	r.observer.LLMCall(info, duration, nil)
+
+	r.observer.Debug("llm", "Response received (%d chars, %d tokens) in %s", len(response.Content), response.Usage.TotalTokens, duration)
+	return response.Content, nil
+}
+
+// grammarExtra returns the "grammar" extra param to send to the LLM when a
+// StructuredConfig schema is configured, so GBNF-aware backends (llama.cpp,
+// LocalAI) constrain sampling to the schema's shape. Returns nil if no
+// structured schema is configured or the schema fails to convert.
+func (r *RLM) grammarExtra() map[string]interface{} {
+	if r.structured == nil || r.structured.Schema == nil {
+		return nil
+	}
+	extra, _ := r.structuredGrammarExtra(r.structured.Schema, r.structured.GrammarField)
+	return extra
+}
+
+// structuredGrammarExtra builds the grammar extra param for a structured
+// completion, preferring a backend's own ConstrainedDecoder over the generic
+// GBNF conversion so the backend gets its native grammar format. constrained
+// reports whether a working ConstrainedDecoder produced the grammar; callers
+// use that to skip the parse-validate-retry loop, since a capable backend
+// can't emit output that fails schema validation in the first place.
+// fieldName picks the extra param key a backend expects the grammar under;
+// an empty fieldName defaults to "grammar", the llama.cpp/LocalAI convention.
+func (r *RLM) structuredGrammarExtra(schema *JSONSchema, fieldName string) (extra map[string]interface{}, constrained bool) {
+	if fieldName == "" {
+		fieldName = "grammar"
+	}
+	if decoder, ok := r.backend.(ConstrainedDecoder); ok {
+		g, err := decoder.BuildGrammar(schema)
+		if err == nil && g != "" {
+			return map[string]interface{}{fieldName: g}, true
+		}
+		r.observer.Debug("rlm", "ConstrainedDecoder failed to build grammar, falling back: %v", err)
+	}
+	return schemaGrammarExtra(schema, fieldName, r.observer), false
+}
+
+// structuredResponseFormatExtra builds the ChatRequest extra param(s) that
+// route a structured completion's schema to a provider's native
+// structured-output surface, per config.ProviderMode (defaulting to
+// ProviderModeOpenAI when UseResponseFormat is set but ProviderMode isn't).
+// Returns a nil extra and mode "" when UseResponseFormat is false or the
+// schema can't be converted, so callers fall back to the ordinary
+// parse-validate-retry loop. guaranteed mirrors structuredGrammarExtra's
+// constrained return: whether the chosen mode is one the provider promises
+// will already be schema-conformant, letting callers skip retries.
+func (r *RLM) structuredResponseFormatExtra(config *StructuredConfig) (extra map[string]interface{}, mode ProviderMode, guaranteed bool) {
+	if !config.UseResponseFormat {
+		return nil, "", false
+	}
+
+	mode = config.ProviderMode
+	if mode == "" {
+		mode = ProviderModeOpenAI
+	}
+
+	name := config.SchemaName
+	if name == "" {
+		name = "response"
+	}
+
+	switch mode {
+	case ProviderModeOpenAI:
+		googleSchema, err := JSONSchemaToGoogleSchema(config.Schema)
+		if err != nil {
+			r.observer.Debug("rlm", "response_format: failed to convert schema, falling back: %v", err)
+			return nil, "", false
+		}
+		return map[string]interface{}{
+			"response_format": map[string]interface{}{
+				"type": "json_schema",
+				"json_schema": map[string]interface{}{
+					"name":   name,
+					"strict": config.Strict,
+					"schema": googleSchema,
+				},
+			},
+		}, mode, config.Strict
+
+	case ProviderModeGemini:
+		googleSchema, err := JSONSchemaToGoogleSchema(config.Schema)
+		if err != nil {
+			r.observer.Debug("rlm", "responseSchema: failed to convert schema, falling back: %v", err)
+			return nil, "", false
+		}
+		return map[string]interface{}{
+			"generationConfig": map[string]interface{}{
+				"responseMimeType": "application/json",
+				"responseSchema":   googleSchema,
+			},
+		}, mode, true
+
+	case ProviderModeAnthropicToolUse:
+		return map[string]interface{}{
+			"tools": []map[string]interface{}{{
+				"name":         name,
+				"description":  "Return the extracted data matching the required schema.",
+				"input_schema": config.Schema,
+			}},
+			"tool_choice": map[string]interface{}{"type": "tool", "name": name},
+		}, mode, true
+
+	case ProviderModeLlamaCppGrammar:
+		grammarExtra, constrained := r.structuredGrammarExtra(config.Schema, config.GrammarField)
+		return grammarExtra, mode, constrained
+
+	default:
+		return nil, ProviderModeNone, false
+	}
+}
+
+// structuredCapabilityCache memoizes LLMProvider.SupportsStructuredOutput()
+// per backend instance, keyed by the backend value itself (for pointer
+// backends such as *anthropicBackend this is pointer-identity; value
+// backends compare by value), so ModeAuto only probes a given provider once
+// rather than on every structured completion. Keying by %T alone would
+// conflate distinct backend instances of the same type - e.g. two
+// *anthropicBackend clients with different settings - into a single cached
+// capability.
+var (
+	structuredCapabilityCache   = make(map[Backend]StructuredCapability)
+	structuredCapabilityCacheMu sync.RWMutex
+)
+
+// probeStructuredCapability resolves r.backend's native structured-output
+// support for ModeAuto, caching the result so repeated ModeAuto completions
+// against the same backend instance don't re-probe every call. A backend
+// that doesn't implement LLMProvider is cached as the zero
+// StructuredCapability (no native support), which ModeAuto treats as
+// "fall back to ModePrompt".
+func (r *RLM) probeStructuredCapability() StructuredCapability {
+	structuredCapabilityCacheMu.RLock()
+	cached, ok := structuredCapabilityCache[r.backend]
+	structuredCapabilityCacheMu.RUnlock()
+	if ok {
+		return cached
+	}
+
+	var capability StructuredCapability
+	if provider, ok := r.backend.(LLMProvider); ok {
+		capability = provider.SupportsStructuredOutput()
+	}
+
+	structuredCapabilityCacheMu.Lock()
+	structuredCapabilityCache[r.backend] = capability
+	structuredCapabilityCacheMu.Unlock()
+	return capability
 }
 
-func (r *RLM) buildREPLEnv(query string, context string) map[string]interface{} {
+// nativeJSONModeExtra builds the ChatRequest extra param for ModeNativeJSON:
+// a provider's bare "valid JSON" guarantee with no schema attached. Returns
+// guaranteed=false always, since valid-JSON is weaker than schema-conformant
+// and the parse-validate-retry loop should stay in play.
+func nativeJSONModeExtra(providerMode ProviderMode) (extra map[string]interface{}, mode ProviderMode, guaranteed bool) {
+	mode = providerMode
+	if mode == "" {
+		mode = ProviderModeOpenAI
+	}
+
+	switch mode {
+	case ProviderModeOpenAI:
+		return map[string]interface{}{
+			"response_format": map[string]interface{}{"type": "json_object"},
+		}, mode, false
+	case ProviderModeGemini:
+		return map[string]interface{}{
+			"generationConfig": map[string]interface{}{"responseMimeType": "application/json"},
+		}, mode, false
+	default:
+		return nil, ProviderModeNone, false
+	}
+}
+
+// resolveStructuredOutput is structuredResponseFormatExtra's StructuredMode-
+// aware front end. config.Mode, when set, takes precedence over
+// UseResponseFormat/ProviderMode: ModePrompt disables native routing
+// entirely, ModeNativeJSON/ModeNativeSchema force the matching surface, and
+// ModeAuto probes the backend's LLMProvider capability (see
+// probeStructuredCapability) and picks the strongest mode it supports. An
+// empty Mode preserves the original UseResponseFormat/ProviderMode behavior
+// for callers that haven't adopted Mode yet.
+func (r *RLM) resolveStructuredOutput(config *StructuredConfig) (extra map[string]interface{}, mode ProviderMode, guaranteed bool) {
+	switch config.Mode {
+	case "":
+		return r.structuredResponseFormatExtra(config)
+
+	case ModePrompt:
+		return nil, ProviderModeNone, false
+
+	case ModeNativeSchema:
+		forced := *config
+		forced.UseResponseFormat = true
+		forced.Strict = true
+		return r.structuredResponseFormatExtra(&forced)
+
+	case ModeNativeJSON:
+		return nativeJSONModeExtra(config.ProviderMode)
+
+	case ModeAuto:
+		capability := r.probeStructuredCapability()
+		providerMode := config.ProviderMode
+		if providerMode == "" {
+			providerMode = capability.ProviderMode
+		}
+		switch {
+		case capability.Schema:
+			forced := *config
+			forced.UseResponseFormat = true
+			forced.Strict = true
+			forced.ProviderMode = providerMode
+			return r.structuredResponseFormatExtra(&forced)
+		case capability.JSON:
+			return nativeJSONModeExtra(providerMode)
+		default:
+			return nil, ProviderModeNone, false
+		}
+
+	default:
+		return r.structuredResponseFormatExtra(config)
+	}
+}
+
+// schemaGrammarExtra converts schema to a GBNF grammar and wraps it as a
+// ChatRequest extra param under fieldName. Returns nil (rather than an
+// error) if conversion fails, since a malformed grammar should degrade to
+// unconstrained sampling rather than abort the completion.
+func schemaGrammarExtra(schema *JSONSchema, fieldName string, observer *Observer) map[string]interface{} {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		observer.Debug("rlm", "Failed to marshal schema for grammar: %v", err)
+		return nil
+	}
+
+	g, err := grammar.FromJSON(schemaJSON)
+	if err != nil {
+		observer.Debug("rlm", "Failed to build grammar from schema: %v", err)
+		return nil
+	}
+
+	return map[string]interface{}{fieldName: g}
+}
+
+// validateFinalAnswer parses a FINAL() answer as JSON and validates it
+// against schema, reusing the same validation the structured completion
+// path uses.
+func validateFinalAnswer(answer string, schema *JSONSchema) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(answer), &value); err != nil {
+		return fmt.Errorf("final answer is not valid JSON: %w", err)
+	}
+	return validateValue(value, schema)
+}
+
+func (r *RLM) buildREPLEnv(ctx stdcontext.Context, query string, context string) map[string]interface{} {
 	env := map[string]interface{}{
 		"context": context,
 		"query":   query,
 	}
 
 	env["re"] = NewRegexHelper()
-	env["recursive_llm"] = func(subQuery string, subContext string) string {
+	env["recursive_llm"] = func(subQuery string, subContext string, subSchema ...interface{}) string {
 		if r.currentDepth+1 >= r.maxDepth {
 			return fmt.Sprintf("Max recursion depth (%d) reached", r.maxDepth)
 		}
 
+		if err := ctx.Err(); err != nil {
+			return fmt.Sprintf("Error: %s", err.Error())
+		}
+
 		r.observer.Debug("rlm", "Recursive call at depth %d: %s", r.currentDepth+1, truncateStr(subQuery, 100))
+		r.metrics.OnRecurse(r.currentDepth)
 
 		subConfig := Config{
 			RecursiveModel:   r.recursiveModel,
@@ -185,16 +636,36 @@ func (r *RLM) buildREPLEnv(query string, context string) map[string]interface{}
 			APIKey:           r.apiKey,
 			MaxDepth:         r.maxDepth,
 			MaxIterations:    r.maxIterations,
+			MaxTokens:        r.maxTokens,
 			TimeoutSeconds:   r.timeoutSeconds,
 			UseMetacognitive: r.useMetacognitive,
 			ExtraParams:      r.extraParams,
+			Backend:          r.backend, // reuse the resolved backend rather than re-dialing from api_base/api_key
+			MetricsObserver:  r.metrics,
+			Retry:            r.retry,
+			DetailedStats:    r.detailedStats,
+			Sandbox:          r.sandbox,
 		}
 
 		subRLM := New(r.recursiveModel, subConfig)
 		subRLM.currentDepth = r.currentDepth + 1
 		subRLM.observer = r.observer // Share observer for trace continuity
 
-		answer, _, err := subRLM.Completion(subQuery, subContext)
+		if len(subSchema) > 0 && subSchema[0] != nil {
+			schema, err := schemaFromJSValue(subSchema[0])
+			if err != nil {
+				return fmt.Sprintf("Error: invalid schema: %s", err.Error())
+			}
+			raw, subStats, err := subRLM.StructuredCompletionJSONContext(ctx, subQuery, subContext, schema)
+			r.mergeSubStats(subStats)
+			if err != nil {
+				return fmt.Sprintf("Error: %s", err.Error())
+			}
+			return string(raw)
+		}
+
+		answer, subStats, err := subRLM.CompletionContext(ctx, subQuery, subContext)
+		r.mergeSubStats(subStats)
 		if err != nil {
 			return fmt.Sprintf("Error: %s", err.Error())
 		}
@@ -204,6 +675,40 @@ func (r *RLM) buildREPLEnv(query string, context string) map[string]interface{}
 	return env
 }
 
+// schemaFromJSValue converts the JS-sourced value goja exports for a
+// recursive_llm(query, context, schema) call's third argument - a plain
+// object, since goja exports a JS object to Go as map[string]interface{} -
+// into a *JSONSchema by round-tripping it through JSON, reusing
+// JSONSchema.UnmarshalJSON rather than hand-walking the map.
+func schemaFromJSValue(value interface{}) (*JSONSchema, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var schema JSONSchema
+	if err := json.Unmarshal(encoded, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// mergeSubStats folds a recursive sub-call's stats into r.stats so a single
+// top-level Completion reports total cost across the whole recursion tree:
+// token and call counts accumulate, and subStats itself is appended to
+// ByDepth so callers can see what each recursion level consumed.
+func (r *RLM) mergeSubStats(subStats RLMStats) {
+	r.stats.LlmCalls += subStats.LlmCalls
+	r.stats.ToolCalls += subStats.ToolCalls
+	r.stats.ParsingRetries += subStats.ParsingRetries
+	r.stats.PromptTokens += subStats.PromptTokens
+	r.stats.CompletionTokens += subStats.CompletionTokens
+	r.stats.TotalTokens += subStats.TotalTokens
+	r.stats.ByDepth = append(r.stats.ByDepth, subStats)
+	if r.detailedStats {
+		r.stats.Calls = append(r.stats.Calls, subStats.Calls...)
+	}
+}
+
 // GetObserver returns the observer for external access to events/traces.
 func (r *RLM) GetObserver() *Observer {
 	return r.observer
@@ -2,17 +2,75 @@ package rlm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 )
 
+// SchemaValidatorBackend validates a decoded JSON value against a JSONSchema.
+// parseAndValidateJSON uses DefaultValidatorBackend unless a caller passes a
+// different one (see StructuredConfig.ValidatorBackend), so alternative
+// validation libraries - or just a stricter/looser pass over the same
+// schema - can be swapped in without touching the retry loop that calls it.
+type SchemaValidatorBackend interface {
+	Validate(data interface{}, schema *JSONSchema) error
+}
+
+// strictValidatorBackend validates using this package's own hand-rolled
+// walker (validate.go) rather than a third-party library. It's the default
+// because it's the only backend that understands the DependsOn extension and
+// returns a *ValidationResult, which buildValidationFeedback needs to give
+// the LLM per-field retry hints instead of a single opaque error string.
+type strictValidatorBackend struct{}
+
+func (strictValidatorBackend) Validate(data interface{}, schema *JSONSchema) error {
+	if dataMap, ok := data.(map[string]interface{}); ok {
+		return validateAgainstSchema(dataMap, schema)
+	}
+	return validateValue(data, schema)
+}
+
+// googleValidatorBackend validates using Google's jsonschema-go package via
+// NewSchemaValidator, for callers who want Draft 2020-12 compliant behavior
+// (e.g. $dynamicRef) over this package's own DependsOn-aware walker. Its
+// errors don't carry per-field paths, so buildValidationFeedback falls back
+// to a generic retry message for them.
+type googleValidatorBackend struct{}
+
+func (googleValidatorBackend) Validate(data interface{}, schema *JSONSchema) error {
+	validator, err := NewSchemaValidator(schema)
+	if err != nil {
+		return err
+	}
+	return validator.Validate(data)
+}
+
+// DefaultValidatorBackend is the SchemaValidatorBackend parseAndValidateJSON
+// falls back to when StructuredConfig.ValidatorBackend is unset.
+var DefaultValidatorBackend SchemaValidatorBackend = strictValidatorBackend{}
+
+// GoogleValidatorBackend is the bundled SchemaValidatorBackend wrapping
+// Google's jsonschema-go package, exported so callers can opt into it via
+// StructuredConfig.ValidatorBackend without needing to implement the
+// interface themselves.
+var GoogleValidatorBackend SchemaValidatorBackend = googleValidatorBackend{}
+
 // SchemaValidator provides JSON Schema validation using Google's jsonschema-go package.
 // It wraps Google's library to provide proper Draft 2020-12 compliant validation
 // while maintaining backward compatibility with our existing JSONSchema type.
 type SchemaValidator struct {
 	resolved *jsonschema.Resolved
 	raw      *JSONSchema
+	// formats holds this validator's own RegisterFormat overrides, consulted
+	// before defaultFormatCheckers so a single instance can customize a
+	// format without affecting every other SchemaValidator in the process.
+	formats map[string]FormatChecker
 }
 
 // NewSchemaValidator creates a SchemaValidator from our internal JSONSchema type.
@@ -52,21 +110,88 @@ func NewSchemaValidator(schema *JSONSchema) (*SchemaValidator, error) {
 	}, nil
 }
 
-// Validate checks data against the JSON schema using Google's validator.
-// Falls back to our internal validation if the Google validator isn't available.
+// Validate checks data against the JSON schema using Google's validator,
+// then separately walks sv.raw alongside data checking any "format" keyword
+// against RegisterFormat/RegisterDefaultFormat checkers - formats Google's
+// library doesn't know about (see the "duration", "ports", "semver",
+// "go-template", and "regex-compilable" checkers registered by default).
+// Falls back to our internal validation if the Google validator isn't
+// available. All format failures are collected and joined with the schema
+// validation error (if any) rather than stopping at the first.
 func (sv *SchemaValidator) Validate(data interface{}) error {
+	var schemaErr error
 	if sv.resolved != nil {
 		if err := sv.resolved.Validate(data); err != nil {
-			return fmt.Errorf("schema validation failed: %w", err)
+			schemaErr = fmt.Errorf("schema validation failed: %w", err)
 		}
+	} else if dataMap, ok := data.(map[string]interface{}); ok {
+		// Fallback to internal validation for simple schemas
+		schemaErr = validateAgainstSchema(dataMap, sv.raw)
+	} else {
+		schemaErr = validateValue(data, sv.raw)
+	}
+
+	formatErrs := sv.checkFormats(sv.raw, data)
+	if schemaErr == nil && len(formatErrs) == 0 {
 		return nil
 	}
+	return errors.Join(append([]error{schemaErr}, formatErrs...)...)
+}
 
-	// Fallback to internal validation for simple schemas
-	if dataMap, ok := data.(map[string]interface{}); ok {
-		return validateAgainstSchema(dataMap, sv.raw)
+// RegisterFormat registers (or overrides) the checker used for the given
+// "format" keyword value on this SchemaValidator only, taking precedence
+// over any checker of the same name registered with RegisterDefaultFormat.
+func (sv *SchemaValidator) RegisterFormat(name string, check func(any) bool) {
+	if sv.formats == nil {
+		sv.formats = make(map[string]FormatChecker)
 	}
-	return validateValue(data, sv.raw)
+	sv.formats[name] = check
+}
+
+// lookupFormat resolves a "format" keyword to a checker, preferring an
+// instance-level override (RegisterFormat) over the process-wide default
+// set (RegisterDefaultFormat / defaultFormatCheckers).
+func (sv *SchemaValidator) lookupFormat(name string) (FormatChecker, bool) {
+	if checker, ok := sv.formats[name]; ok {
+		return checker, true
+	}
+	defaultFormatCheckersMu.RLock()
+	defer defaultFormatCheckersMu.RUnlock()
+	checker, ok := defaultFormatCheckers[name]
+	return checker, ok
+}
+
+// checkFormats recursively walks schema alongside data, invoking the
+// checker registered for every node's Format (if any) and collecting every
+// failure rather than returning on the first.
+func (sv *SchemaValidator) checkFormats(schema *JSONSchema, data interface{}) []error {
+	if schema == nil {
+		return nil
+	}
+
+	var errs []error
+	if schema.Format != "" {
+		if checker, ok := sv.lookupFormat(schema.Format); ok && !checker(data) {
+			errs = append(errs, fmt.Errorf("value %v does not match format %q", data, schema.Format))
+		}
+	}
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for name, propSchema := range schema.Properties {
+			if value, ok := v[name]; ok {
+				errs = append(errs, sv.checkFormats(propSchema, value)...)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for _, item := range v {
+				errs = append(errs, sv.checkFormats(schema.Items, item)...)
+			}
+		}
+	}
+
+	return errs
 }
 
 // ValidateJSON validates a JSON byte slice against the schema.
@@ -78,6 +203,86 @@ func (sv *SchemaValidator) ValidateJSON(jsonData []byte) error {
 	return sv.Validate(data)
 }
 
+// defaultFormatCheckers is the process-wide registry RegisterDefaultFormat
+// writes to and SchemaValidator.lookupFormat falls back to when an instance
+// has no RegisterFormat override for a given name. It ships a starter set
+// covering formats that show up in DSLs like compose files and OpenAPI specs
+// but that Google's jsonschema-go library doesn't check.
+var (
+	defaultFormatCheckersMu sync.RWMutex
+	defaultFormatCheckers   = map[string]FormatChecker{
+		"duration":         checkGoDurationFormat,
+		"ports":            checkPortsFormat,
+		"semver":           checkSemverFormat,
+		"go-template":      checkGoTemplateFormat,
+		"regex-compilable": checkRegexCompilableFormat,
+	}
+)
+
+// RegisterDefaultFormat registers (or overrides) the checker used for the
+// given "format" keyword value on every SchemaValidator that doesn't
+// override it with its own RegisterFormat call.
+func RegisterDefaultFormat(name string, check func(any) bool) {
+	defaultFormatCheckersMu.Lock()
+	defer defaultFormatCheckersMu.Unlock()
+	defaultFormatCheckers[name] = check
+}
+
+// checkGoDurationFormat accepts any string time.ParseDuration accepts, e.g.
+// "300ms" or "1h30m" - Go's own duration syntax, distinct from the
+// ISO 8601 "duration" format checked by validate.go's strict backend.
+func checkGoDurationFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// portMappingPattern matches a Docker Compose-style port mapping, e.g. "80",
+// "8080:80", "127.0.0.1:8080:80", or "8080:80/udp".
+var portMappingPattern = regexp.MustCompile(`^(\d{1,3}(\.\d{1,3}){3}:)?\d{1,5}(-\d{1,5})?(:\d{1,5}(-\d{1,5})?)?(/(tcp|udp))?$`)
+
+func checkPortsFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	return portMappingPattern.MatchString(s)
+}
+
+// semverPattern is a simplified MAJOR.MINOR.PATCH pattern with optional
+// -prerelease and +build parts. Unlike the regex recommended on semver.org,
+// it accepts leading zeros and empty dot-separated identifiers.
+var semverPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+func checkSemverFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	return semverPattern.MatchString(s)
+}
+
+func checkGoTemplateFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := template.New("format-check").Parse(s)
+	return err == nil
+}
+
+func checkRegexCompilableFormat(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := regexp.Compile(s)
+	return err == nil
+}
+
 // JSONSchemaToGoogleSchema converts our internal JSONSchema to Google's Schema type.
 // This is useful for operations that need the Google type directly.
 func JSONSchemaToGoogleSchema(schema *JSONSchema) (*jsonschema.Schema, error) {
@@ -120,6 +325,145 @@ func InferSchemaFromJSON(jsonData []byte) (*JSONSchema, error) {
 	return inferSchemaFromValue(data), nil
 }
 
+// InferSchemaFromType is InferSchemaFromJSON's reflection-driven sibling: it
+// walks a Go type instead of a decoded JSON example, so a struct's schema
+// can't drift from its definition the way a hand-authored *JSONSchema or an
+// example payload can. Pointer types are dereferenced, and fields honor
+// `json:"name,omitempty"` for property naming and required-ness plus a
+// `jsonschema:"..."` tag for description, minimum/maximum, minLength/
+// maxLength, pattern, enum, and format (see applyJSONSchemaTag).
+//
+// Struct types are tracked in a visited-type table as they're walked; a type
+// encountered a second time (a cycle, directly or through a chain of other
+// structs) is lowered to a "$ref" into the returned schema's Definitions
+// ($defs) section instead of recursing forever.
+func InferSchemaFromType(t reflect.Type) (*JSONSchema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("rlm: cannot infer a schema for a nil type")
+	}
+
+	defs := make(map[string]*JSONSchema)
+	schema, err := inferSchemaFromType(t, make(map[reflect.Type]*pendingTypeSchema), defs)
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) > 0 {
+		schema.Definitions = defs
+	}
+	return schema, nil
+}
+
+// InferSchemaFromValue is InferSchemaFromType's reflect.Value-driven sibling,
+// for callers that have a value in hand (often a zero value of the target
+// type) rather than a reflect.Type, e.g. InferSchemaFromValue(Person{}).
+func InferSchemaFromValue(v any) (*JSONSchema, error) {
+	if v == nil {
+		return nil, fmt.Errorf("rlm: cannot infer a schema for a nil value")
+	}
+	return InferSchemaFromType(reflect.TypeOf(v))
+}
+
+// GenerateSchema is InferSchemaFromValue's preferred entry point for callers
+// building a schema from a Go value rather than hand-constructing a
+// *JSONSchema tree: GenerateSchema(Person{}) instead of
+// InferSchemaFromValue(Person{}).
+func GenerateSchema(v any) (*JSONSchema, error) {
+	return InferSchemaFromValue(v)
+}
+
+// MustGenerateSchema is GenerateSchema for callers (package init, tests)
+// that know the value's type is schema-able and would rather panic on a
+// mistake than plumb the error through, mirroring the standard library's
+// Must-prefixed helpers (regexp.MustCompile, template.Must).
+func MustGenerateSchema(v any) *JSONSchema {
+	schema, err := GenerateSchema(v)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// pendingTypeSchema tracks a struct type's $defs name and in-progress schema
+// while inferSchemaFromType is still walking its fields, so a cycle back to
+// it resolves to a $ref instead of recursing forever.
+type pendingTypeSchema struct {
+	name   string
+	schema *JSONSchema
+}
+
+// inferSchemaFromType is InferSchemaFromType's recursive worker. pending
+// tracks struct types currently being walked (for cycle detection); defs
+// collects the $defs entries emitted for any cycle found along the way.
+func inferSchemaFromType(t reflect.Type, pending map[reflect.Type]*pendingTypeSchema, defs map[string]*JSONSchema) (*JSONSchema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct {
+		if p, ok := pending[t]; ok {
+			defs[p.name] = p.schema
+			return &JSONSchema{Ref: "#/$defs/" + p.name}, nil
+		}
+
+		name := t.Name()
+		if name == "" {
+			name = fmt.Sprintf("anonymous%d", len(pending))
+		}
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		pending[t] = &pendingTypeSchema{name: name, schema: schema}
+		defer delete(pending, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue // unexported
+			}
+
+			fieldName, omitempty := jsonFieldName(field)
+			if fieldName == "-" {
+				continue
+			}
+
+			fieldSchema, err := inferSchemaFromType(field.Type, pending, defs)
+			if err != nil {
+				return nil, err
+			}
+			forceRequired := applyJSONSchemaTag(fieldSchema, field.Type, field.Tag.Get("jsonschema"))
+			if field.Type.Kind() == reflect.Ptr {
+				fieldSchema.Nullable = true
+			}
+
+			schema.Properties[fieldName] = fieldSchema
+			if !omitempty || forceRequired {
+				schema.Required = append(schema.Required, fieldName)
+			}
+		}
+		return schema, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, err := inferSchemaFromType(t.Elem(), pending, defs)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: true}, nil
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}, nil
+	default:
+		return nil, fmt.Errorf("rlm: unsupported type %s for schema inference", t.Kind())
+	}
+}
+
 // inferSchemaFromValue recursively builds a JSONSchema from a Go value.
 func inferSchemaFromValue(value interface{}) *JSONSchema {
 	if value == nil {
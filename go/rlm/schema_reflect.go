@@ -0,0 +1,293 @@
+package rlm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeType is reflect.Type's handle on time.Time, checked in SchemaFromType
+// so an embedded/field time.Time schemas as an RFC 3339 string rather than
+// recursing into its unexported internal fields (which would otherwise
+// produce an empty "object" schema, since none of them are exported).
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaFromType builds a *JSONSchema for a Go type by walking it with
+// reflection, so callers don't have to hand-build a JSONSchema tree for
+// their own structs. For struct types it honors `json:"name,omitempty"` for
+// property naming and required-ness (a field is required unless its json
+// tag carries omitempty), and reads two additional tags per field:
+//
+//   - `validate:"min=0,max=1"` populates Minimum/Maximum on numeric fields,
+//     MinLength/MaxLength on strings, and MinItems/MaxItems on slices.
+//   - `jsonschema:"enum=pos|neg|neu,format=email,description=..."` populates
+//     Enum (pipe-separated, since comma already separates tag entries),
+//     Format, and Description.
+//
+// Unexported fields and fields tagged `json:"-"` are skipped. Pointer
+// fields are schema'd as their element type with Nullable set. Struct object
+// schemas always set AdditionalProperties: false, matching the
+// additionalProperties:false + required contract OpenAI's strict structured
+// -output mode expects.
+func SchemaFromType(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchemaFromType(t)
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: SchemaFromType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: SchemaFromType(t.Elem())}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// SchemaFor is the generic sibling of SchemaFromType, for when the caller
+// already has the type in hand at compile time: SchemaFor[Person]() instead
+// of SchemaFromType(reflect.TypeOf(Person{})).
+func SchemaFor[T any]() *JSONSchema {
+	var zero T
+	return SchemaFromType(reflect.TypeOf(zero))
+}
+
+// GenerateSchemaForType is SchemaFor's reflect.Value-driven sibling, for
+// callers that only have a value in hand (e.g. one decoded from config or
+// passed through an any parameter) rather than a compile-time type
+// parameter. Pass a zero value of the target type, e.g.
+// GenerateSchemaForType(Person{}).
+func GenerateSchemaForType(v any) (*JSONSchema, error) {
+	if v == nil {
+		return nil, fmt.Errorf("rlm: cannot generate a schema for a nil value")
+	}
+	return SchemaFromType(reflect.TypeOf(v)), nil
+}
+
+// Unmarshal validates rawJSON against schema and, if it passes, decodes it
+// into dest (which must be a non-nil pointer, per encoding/json). It's the
+// "I already have JSON in hand" counterpart to StructuredCompletionInto, for
+// validating/decoding a response obtained some other way (a cached result, a
+// webhook payload) against a schema built with SchemaFor/GenerateSchemaForType.
+func (schema *JSONSchema) Unmarshal(rawJSON []byte, dest any) error {
+	var value interface{}
+	if err := json.Unmarshal(rawJSON, &value); err != nil {
+		return fmt.Errorf("rlm: invalid JSON: %w", err)
+	}
+	if err := validateValue(value, schema); err != nil {
+		return fmt.Errorf("rlm: JSON does not match schema: %w", err)
+	}
+	return json.Unmarshal(rawJSON, dest)
+}
+
+// structSchemaFromType builds the "object" schema for a struct type,
+// recursing into SchemaFromType for each field's own type.
+func structSchemaFromType(t reflect.Type) *JSONSchema {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           make(map[string]*JSONSchema),
+		AdditionalProperties: false,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Check anonymous-struct flattening before the unexported-field skip:
+		// encoding/json amends the usual visibility rules for anonymous
+		// fields, promoting the exported fields of an embedded struct even
+		// when the embedded field itself is unexported (e.g. `lowerCaseType`
+		// has a non-empty PkgPath despite being eligible for flattening).
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embeddedType := field.Type
+			for embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct && embeddedType != timeType {
+				embedded := structSchemaFromType(embeddedType)
+				for name, propSchema := range embedded.Properties {
+					schema.Properties[name] = propSchema
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, omitempty := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldSchema := SchemaFromType(field.Type)
+		applyValidateTag(fieldSchema, field.Type, field.Tag.Get("validate"))
+		forceRequired := applyJSONSchemaTag(fieldSchema, field.Type, field.Tag.Get("jsonschema"))
+		if field.Type.Kind() == reflect.Ptr {
+			fieldSchema.Nullable = true
+		}
+
+		schema.Properties[name] = fieldSchema
+		if !omitempty || forceRequired {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// jsonFieldName reads a field's `json` tag the way encoding/json does,
+// returning the property name (falling back to the Go field name when the
+// tag is absent or names only options) and whether omitempty was set.
+func jsonFieldName(field reflect.StructField) (name string, omitempty bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+// applyValidateTag parses a `validate:"min=0,max=1"`-style tag and sets the
+// matching bound on schema, picking the numeric/string/array field based on
+// fieldType's underlying kind.
+func applyValidateTag(schema *JSONSchema, fieldType reflect.Type, tag string) {
+	if tag == "" {
+		return
+	}
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+
+		switch key {
+		case "min":
+			setMinBound(schema, fieldType, f)
+		case "max":
+			setMaxBound(schema, fieldType, f)
+		}
+	}
+}
+
+// applyJSONSchemaTag parses a
+// `jsonschema:"required,description=...,enum=a|b,min=0,max=10,format=email"`-
+// style tag and sets the matching JSONSchema fields, returning whether the
+// bare "required" keyword was present (the caller ORs this with the
+// json-tag-derived required-ness, so either tag can mark a field required).
+// min/max mirror applyValidateTag's bound-picking-by-kind behavior, for
+// callers who'd rather keep every constraint in one tag than split them
+// across `validate` and `jsonschema`. minLength/maxLength set MinLength/
+// MaxLength directly regardless of field kind, for the rare case a string
+// field's bound needs to be unambiguous in the tag itself.
+func applyJSONSchemaTag(schema *JSONSchema, fieldType reflect.Type, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			if key == "required" {
+				required = true
+			}
+			continue
+		}
+		switch key {
+		case "enum":
+			schema.Enum = strings.Split(value, "|")
+		case "format":
+			schema.Format = value
+		case "description":
+			schema.Description = value
+		case "pattern":
+			schema.Pattern = value
+		case "min":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				setMinBound(schema, fieldType, f)
+			}
+		case "max":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				setMaxBound(schema, fieldType, f)
+			}
+		case "minLength":
+			if n, err := strconv.Atoi(value); err == nil {
+				schema.MinLength = &n
+			}
+		case "maxLength":
+			if n, err := strconv.Atoi(value); err == nil {
+				schema.MaxLength = &n
+			}
+		}
+	}
+	return required
+}
+
+// setMinBound and setMaxBound pick the numeric/string/array bound to set
+// based on fieldType's kind, shared between applyValidateTag and
+// applyJSONSchemaTag so "min"/"max" behave identically in either tag.
+func setMinBound(schema *JSONSchema, fieldType reflect.Type, f float64) {
+	switch fieldType.Kind() {
+	case reflect.String:
+		n := int(f)
+		schema.MinLength = &n
+	case reflect.Slice, reflect.Array:
+		n := int(f)
+		schema.MinItems = &n
+	default:
+		schema.Minimum = &f
+	}
+}
+
+func setMaxBound(schema *JSONSchema, fieldType reflect.Type, f float64) {
+	switch fieldType.Kind() {
+	case reflect.String:
+		n := int(f)
+		schema.MaxLength = &n
+	case reflect.Slice, reflect.Array:
+		n := int(f)
+		schema.MaxItems = &n
+	default:
+		schema.Maximum = &f
+	}
+}
@@ -0,0 +1,275 @@
+package rlm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectTestAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type reflectTestPerson struct {
+	Name     string             `json:"name" validate:"min=1,max=100"`
+	Age      int                `json:"age" validate:"min=0,max=130"`
+	Email    string             `json:"email" jsonschema:"format=email,description=contact email"`
+	Mood     string             `json:"mood,omitempty" jsonschema:"enum=pos|neg|neu"`
+	Tags     []string           `json:"tags,omitempty" validate:"min=1,max=5"`
+	Address  reflectTestAddress `json:"address"`
+	Nickname *string            `json:"nickname,omitempty"`
+	secret   string             //nolint:unused // unexported, must be skipped
+	Ignored  string             `json:"-"`
+}
+
+func TestSchemaFromType_Person(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestPerson{}))
+
+	if schema.Type != "object" {
+		t.Fatalf("Type = %q, want object", schema.Type)
+	}
+
+	if _, ok := schema.Properties["secret"]; ok {
+		t.Error("unexported field should not appear in Properties")
+	}
+	if _, ok := schema.Properties["Ignored"]; ok {
+		t.Error(`field tagged json:"-" should not appear in Properties`)
+	}
+
+	wantRequired := map[string]bool{"name": true, "age": true, "email": true, "address": true}
+	gotRequired := map[string]bool{}
+	for _, r := range schema.Required {
+		gotRequired[r] = true
+	}
+	if len(gotRequired) != len(wantRequired) {
+		t.Fatalf("Required = %v, want exactly %v", schema.Required, wantRequired)
+	}
+	for name := range wantRequired {
+		if !gotRequired[name] {
+			t.Errorf("Required missing %q", name)
+		}
+	}
+	for _, optional := range []string{"mood", "tags", "nickname"} {
+		if gotRequired[optional] {
+			t.Errorf("%q has omitempty and should not be Required", optional)
+		}
+	}
+
+	name := schema.Properties["name"]
+	if name.Type != "string" {
+		t.Errorf("name.Type = %q, want string", name.Type)
+	}
+	if name.MinLength == nil || *name.MinLength != 1 {
+		t.Errorf("name.MinLength = %v, want 1", name.MinLength)
+	}
+	if name.MaxLength == nil || *name.MaxLength != 100 {
+		t.Errorf("name.MaxLength = %v, want 100", name.MaxLength)
+	}
+
+	age := schema.Properties["age"]
+	if age.Type != "integer" {
+		t.Errorf("age.Type = %q, want integer", age.Type)
+	}
+	if age.Minimum == nil || *age.Minimum != 0 {
+		t.Errorf("age.Minimum = %v, want 0", age.Minimum)
+	}
+	if age.Maximum == nil || *age.Maximum != 130 {
+		t.Errorf("age.Maximum = %v, want 130", age.Maximum)
+	}
+
+	email := schema.Properties["email"]
+	if email.Format != "email" {
+		t.Errorf("email.Format = %q, want email", email.Format)
+	}
+	if email.Description != "contact email" {
+		t.Errorf("email.Description = %q, want %q", email.Description, "contact email")
+	}
+
+	mood := schema.Properties["mood"]
+	if want := []string{"pos", "neg", "neu"}; !reflect.DeepEqual(mood.Enum, want) {
+		t.Errorf("mood.Enum = %v, want %v", mood.Enum, want)
+	}
+
+	tags := schema.Properties["tags"]
+	if tags.Type != "array" || tags.Items == nil || tags.Items.Type != "string" {
+		t.Errorf("tags schema = %+v, want array of string", tags)
+	}
+	if tags.MinItems == nil || *tags.MinItems != 1 || tags.MaxItems == nil || *tags.MaxItems != 5 {
+		t.Errorf("tags Min/MaxItems = %v/%v, want 1/5", tags.MinItems, tags.MaxItems)
+	}
+
+	address := schema.Properties["address"]
+	if address.Type != "object" {
+		t.Fatalf("address.Type = %q, want object", address.Type)
+	}
+	if _, ok := address.Properties["city"]; !ok {
+		t.Error("address schema missing nested 'city' property")
+	}
+
+	nickname := schema.Properties["nickname"]
+	if !nickname.Nullable {
+		t.Error("pointer field should be Nullable")
+	}
+	if nickname.Type != "string" {
+		t.Errorf("nickname.Type = %q, want string", nickname.Type)
+	}
+}
+
+func TestSchemaFor_MatchesSchemaFromType(t *testing.T) {
+	byType := SchemaFromType(reflect.TypeOf(reflectTestAddress{}))
+	byGeneric := SchemaFor[reflectTestAddress]()
+
+	if !reflect.DeepEqual(byType, byGeneric) {
+		t.Errorf("SchemaFor[T]() = %+v, want %+v (same as SchemaFromType)", byGeneric, byType)
+	}
+}
+
+func TestSchemaFromType_NoJSONTagUsesFieldName(t *testing.T) {
+	type Untagged struct {
+		Value string
+	}
+
+	schema := SchemaFromType(reflect.TypeOf(Untagged{}))
+	if _, ok := schema.Properties["Value"]; !ok {
+		t.Errorf("expected property named after the Go field when no json tag is present, got %v", schema.Properties)
+	}
+}
+
+func TestGenerateSchemaForType_MatchesSchemaFromType(t *testing.T) {
+	byType := SchemaFromType(reflect.TypeOf(reflectTestAddress{}))
+	byValue, err := GenerateSchemaForType(reflectTestAddress{})
+	if err != nil {
+		t.Fatalf("GenerateSchemaForType() error = %v", err)
+	}
+	if !reflect.DeepEqual(byType, byValue) {
+		t.Errorf("GenerateSchemaForType() = %+v, want %+v (same as SchemaFromType)", byValue, byType)
+	}
+}
+
+func TestGenerateSchemaForType_NilErrors(t *testing.T) {
+	if _, err := GenerateSchemaForType(nil); err == nil {
+		t.Error("GenerateSchemaForType(nil) expected an error")
+	}
+}
+
+type reflectTestTaggedOnlyInJSONSchema struct {
+	Title string `json:"title,omitempty" jsonschema:"required,min=1,max=50"`
+}
+
+type reflectTestExplicitLengthKeys struct {
+	Slug string `json:"slug" jsonschema:"minLength=3,maxLength=20"`
+}
+
+func TestSchemaFromType_JSONSchemaTagRequiredAndBounds(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestTaggedOnlyInJSONSchema{}))
+
+	if len(schema.Required) != 1 || schema.Required[0] != "title" {
+		t.Errorf("Required = %v, want [title] from the bare jsonschema:\"required\" keyword despite omitempty", schema.Required)
+	}
+
+	title := schema.Properties["title"]
+	if title.MinLength == nil || *title.MinLength != 1 {
+		t.Errorf("title.MinLength = %v, want 1", title.MinLength)
+	}
+	if title.MaxLength == nil || *title.MaxLength != 50 {
+		t.Errorf("title.MaxLength = %v, want 50", title.MaxLength)
+	}
+}
+
+func TestSchemaFromType_ExplicitMinMaxLengthKeys(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestExplicitLengthKeys{}))
+
+	slug := schema.Properties["slug"]
+	if slug.MinLength == nil || *slug.MinLength != 3 {
+		t.Errorf("slug.MinLength = %v, want 3", slug.MinLength)
+	}
+	if slug.MaxLength == nil || *slug.MaxLength != 20 {
+		t.Errorf("slug.MaxLength = %v, want 20", slug.MaxLength)
+	}
+}
+
+type reflectTestEvent struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestSchemaFromType_TimeFieldSchemasAsDateTimeString(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestEvent{}))
+
+	createdAt := schema.Properties["created_at"]
+	if createdAt == nil || createdAt.Type != "string" || createdAt.Format != "date-time" {
+		t.Errorf("created_at schema = %+v, want {Type: string, Format: date-time}", createdAt)
+	}
+}
+
+type reflectTestTagged struct {
+	Counts map[string]int `json:"counts"`
+}
+
+func TestSchemaFromType_MapSchemasValueType(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestTagged{}))
+
+	counts := schema.Properties["counts"]
+	if counts.Type != "object" {
+		t.Fatalf("counts.Type = %q, want object", counts.Type)
+	}
+	valueSchema, ok := counts.AdditionalProperties.(*JSONSchema)
+	if !ok || valueSchema.Type != "integer" {
+		t.Errorf("counts.AdditionalProperties = %v, want a *JSONSchema with Type integer", counts.AdditionalProperties)
+	}
+}
+
+type reflectTestBase struct {
+	ID string `json:"id"`
+}
+
+type reflectTestEmbedding struct {
+	reflectTestBase
+	Name string `json:"name"`
+}
+
+func TestSchemaFromType_FlattensAnonymousEmbeddedStruct(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestEmbedding{}))
+
+	if _, ok := schema.Properties["id"]; !ok {
+		t.Errorf("Properties = %v, want the embedded struct's \"id\" field flattened in", schema.Properties)
+	}
+	if _, ok := schema.Properties["name"]; !ok {
+		t.Errorf("Properties = %v, want \"name\"", schema.Properties)
+	}
+	if _, ok := schema.Properties["reflectTestBase"]; ok {
+		t.Error("Properties should not contain the embedded struct's type name as a nested property")
+	}
+}
+
+func TestSchemaFromType_StructDisallowsAdditionalProperties(t *testing.T) {
+	schema := SchemaFromType(reflect.TypeOf(reflectTestPerson{}))
+
+	if schema.AdditionalProperties != false {
+		t.Errorf("AdditionalProperties = %v, want false to match OpenAI's strict structured-output contract", schema.AdditionalProperties)
+	}
+}
+
+func TestJSONSchema_Unmarshal(t *testing.T) {
+	schema := SchemaFor[reflectTestAddress]()
+
+	var dest reflectTestAddress
+	err := schema.Unmarshal([]byte(`{"city": "Boston", "zip": "02101"}`), &dest)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if dest.City != "Boston" || dest.Zip != "02101" {
+		t.Errorf("Unmarshal() dest = %+v, want City=Boston Zip=02101", dest)
+	}
+}
+
+func TestJSONSchema_UnmarshalRejectsSchemaViolation(t *testing.T) {
+	schema := SchemaFor[reflectTestAddress]()
+
+	var dest reflectTestAddress
+	err := schema.Unmarshal([]byte(`{"zip": "02101"}`), &dest)
+	if err == nil {
+		t.Fatal("Unmarshal() expected an error for a missing required field")
+	}
+}
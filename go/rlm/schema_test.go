@@ -2,6 +2,7 @@ package rlm
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -200,6 +201,36 @@ func TestJSONSchemaConversion(t *testing.T) {
 	}
 }
 
+func TestJSONSchemaConversion_ExampleAndDiscriminator(t *testing.T) {
+	original := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"name": {Type: "string", Example: "Ada Lovelace"},
+		},
+		Discriminator: &Discriminator{
+			PropertyName: "petType",
+			Mapping:      map[string]string{"cat": "Cat"},
+		},
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var parsed JSONSchema
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if parsed.Properties["name"].Example != "Ada Lovelace" {
+		t.Errorf("Example = %v, want %q", parsed.Properties["name"].Example, "Ada Lovelace")
+	}
+	if parsed.Discriminator == nil || parsed.Discriminator.PropertyName != "petType" {
+		t.Errorf("Discriminator = %+v, want propertyName petType", parsed.Discriminator)
+	}
+}
+
 func TestInferSchemaFromJSON(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -341,3 +372,195 @@ func TestSchemaJSONRoundTrip(t *testing.T) {
 		t.Errorf("expected 2 required, got %d", len(parsed.Required))
 	}
 }
+
+func TestValidatorBackends(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"name": {Type: "string"},
+		},
+		Required: []string{"name"},
+	}
+	valid := map[string]interface{}{"name": "Alice"}
+	invalid := map[string]interface{}{}
+
+	for _, backend := range []SchemaValidatorBackend{DefaultValidatorBackend, GoogleValidatorBackend} {
+		if err := backend.Validate(valid, schema); err != nil {
+			t.Errorf("%T: expected valid data to pass, got %v", backend, err)
+		}
+		if err := backend.Validate(invalid, schema); err == nil {
+			t.Errorf("%T: expected missing required field to fail", backend)
+		}
+	}
+}
+
+func TestParseAndValidateJSON_CustomBackend(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"name": {Type: "string"},
+		},
+		Required: []string{"name"},
+	}
+
+	if _, err := parseAndValidateJSON(`{"name": "Alice"}`, schema, GoogleValidatorBackend); err != nil {
+		t.Errorf("expected valid JSON to pass with GoogleValidatorBackend: %v", err)
+	}
+	if _, err := parseAndValidateJSON(`{}`, schema, GoogleValidatorBackend); err == nil {
+		t.Error("expected missing required field to fail with GoogleValidatorBackend")
+	}
+}
+
+type inferSchemaAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type inferSchemaPerson struct {
+	Name    string              `json:"name" jsonschema:"minLength=1,description=full name"`
+	Age     int                 `json:"age,omitempty" jsonschema:"min=0,max=130"`
+	Email   string              `json:"email" jsonschema:"format=email"`
+	Address inferSchemaAddress  `json:"address"`
+	Manager *inferSchemaPerson  `json:"manager,omitempty"`
+	Reports []inferSchemaPerson `json:"reports,omitempty"`
+}
+
+func TestInferSchemaFromType_Struct(t *testing.T) {
+	schema, err := InferSchemaFromType(reflect.TypeOf(inferSchemaPerson{}))
+	if err != nil {
+		t.Fatalf("InferSchemaFromType() error = %v", err)
+	}
+
+	if schema.Type != "object" {
+		t.Fatalf("Type = %q, want object", schema.Type)
+	}
+
+	wantRequired := map[string]bool{"name": true, "email": true, "address": true}
+	gotRequired := map[string]bool{}
+	for _, r := range schema.Required {
+		gotRequired[r] = true
+	}
+	if len(gotRequired) != len(wantRequired) {
+		t.Fatalf("Required = %v, want exactly %v", schema.Required, wantRequired)
+	}
+
+	email := schema.Properties["email"]
+	if email == nil || email.Format != "email" {
+		t.Errorf("Properties[email].Format = %+v, want email", email)
+	}
+
+	address := schema.Properties["address"]
+	if address == nil || address.Type != "object" || address.Properties["city"] == nil {
+		t.Errorf("Properties[address] = %+v, want a nested object schema with a city property", address)
+	}
+
+	manager := schema.Properties["manager"]
+	if manager == nil || manager.Ref != "#/$defs/inferSchemaPerson" {
+		t.Errorf("Properties[manager].Ref = %q, want #/$defs/inferSchemaPerson (cycle back to the root type)", manager.Ref)
+	}
+	if schema.Definitions["inferSchemaPerson"] == nil {
+		t.Error("Definitions[inferSchemaPerson] missing even though manager cycles back to it")
+	}
+
+	reports := schema.Properties["reports"]
+	if reports == nil || reports.Type != "array" || reports.Items == nil || reports.Items.Ref != "#/$defs/inferSchemaPerson" {
+		t.Errorf("Properties[reports] = %+v, want an array whose items ref back to #/$defs/inferSchemaPerson", reports)
+	}
+}
+
+func TestInferSchemaFromValue(t *testing.T) {
+	schema, err := InferSchemaFromValue(inferSchemaAddress{})
+	if err != nil {
+		t.Fatalf("InferSchemaFromValue() error = %v", err)
+	}
+	if schema.Type != "object" || schema.Properties["city"] == nil {
+		t.Errorf("InferSchemaFromValue() = %+v, want an object schema with a city property", schema)
+	}
+
+	if _, err := InferSchemaFromValue(nil); err == nil {
+		t.Error("InferSchemaFromValue(nil) expected an error")
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	schema, err := GenerateSchema(inferSchemaAddress{})
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+	if schema.Type != "object" || schema.Properties["city"] == nil {
+		t.Errorf("GenerateSchema() = %+v, want an object schema with a city property", schema)
+	}
+}
+
+func TestMustGenerateSchema(t *testing.T) {
+	schema := MustGenerateSchema(inferSchemaAddress{})
+	if schema.Type != "object" {
+		t.Errorf("MustGenerateSchema() = %+v, want an object schema", schema)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGenerateSchema(nil) expected a panic")
+		}
+	}()
+	MustGenerateSchema(nil)
+}
+
+func TestSchemaValidator_DefaultFormats(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"timeout": {Type: "string", Format: "duration"},
+			"port":    {Type: "string", Format: "ports"},
+			"version": {Type: "string", Format: "semver"},
+		},
+		Required: []string{"timeout", "port", "version"},
+	}
+
+	validator, err := NewSchemaValidator(schema)
+	if err != nil {
+		t.Fatalf("NewSchemaValidator() error = %v", err)
+	}
+
+	valid := map[string]interface{}{
+		"timeout": "30s",
+		"port":    "8080:80/tcp",
+		"version": "1.2.3-rc.1",
+	}
+	if err := validator.Validate(valid); err != nil {
+		t.Errorf("Validate() of conforming data = %v, want nil", err)
+	}
+
+	invalid := map[string]interface{}{
+		"timeout": "not-a-duration",
+		"port":    "not-a-port",
+		"version": "not-a-version",
+	}
+	if err := validator.Validate(invalid); err == nil {
+		t.Error("Validate() expected an error for data failing all three format checks")
+	}
+}
+
+func TestSchemaValidator_RegisterFormat(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"code": {Type: "string", Format: "even-length"}},
+		Required:   []string{"code"},
+	}
+
+	validator, err := NewSchemaValidator(schema)
+	if err != nil {
+		t.Fatalf("NewSchemaValidator() error = %v", err)
+	}
+	validator.RegisterFormat("even-length", func(v any) bool {
+		s, ok := v.(string)
+		return ok && len(s)%2 == 0
+	})
+
+	if err := validator.Validate(map[string]interface{}{"code": "abcd"}); err != nil {
+		t.Errorf("Validate() of even-length code = %v, want nil", err)
+	}
+	if err := validator.Validate(map[string]interface{}{"code": "abc"}); err == nil {
+		t.Error("Validate() expected an error for an odd-length code")
+	}
+}
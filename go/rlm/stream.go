@@ -0,0 +1,419 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Chunk is one piece of a streamed completion, delivered on the channel
+// CompletionStream returns. Depth identifies which recursion level produced
+// it: 0 for the top-level call, incrementing for each nested
+// recursive_llm_stream() call, so a UI can indent or label nested "thinking"
+// as it arrives. Done marks the last chunk for a given Depth's answer (the
+// channel itself only closes once the top-level call finishes). Err is set
+// on the terminal chunk if the completion failed; no further chunks follow it.
+type Chunk struct {
+	Depth   int
+	Content string
+	Done    bool
+	Err     error
+}
+
+// StreamChunk is one token delta (or terminal error/usage report) from a
+// StreamingBackend, as delivered on the channel ChatStream returns.
+type StreamChunk struct {
+	Delta string
+	Usage *TokenUsage // set on the chunk carrying final usage, nil otherwise
+	Err   error
+}
+
+// StreamingBackend is implemented by backends that can stream token deltas
+// as they're generated instead of returning only the finished completion.
+// RLM.CompletionStream type-asserts r.backend against this and falls back to
+// a single buffered Chat call — delivered as one delta — for backends that
+// don't implement it.
+type StreamingBackend interface {
+	ChatStream(ctx stdcontext.Context, request ChatRequest) (<-chan StreamChunk, error)
+}
+
+// finalOpen matches the opening of a FINAL(...) literal and captures which
+// quote style was used, so finalDetector knows what closing delimiter to
+// watch for.
+var finalOpen = regexp.MustCompile(`FINAL\s*\(\s*("""|'''|"|')`)
+
+// finalDetector incrementally scans an accumulating LLM response for a
+// FINAL(...) literal, so CompletionStream can start forwarding the answer's
+// text to the caller as soon as the opening quote is unambiguous, rather
+// than waiting for the whole response. It never commits a match that could
+// still be extended by the next delta: the trailing len(quote)-1 bytes of
+// the buffer are always withheld, since they could be the start of a
+// closing delimiter split across a chunk boundary (e.g. `FIN` + `AL("foo")`,
+// or a closing `""` + `"`).
+type finalDetector struct {
+	buf     strings.Builder
+	quote   string // closing delimiter once an opening is found, else ""
+	content int    // buf offset where the FINAL(...) literal's content begins
+	emitted int    // content-relative offset already returned by Feed
+	closed  bool   // the closing delimiter has been found
+}
+
+// Feed appends delta to the detector's buffer and returns whatever new
+// answer text can now be safely emitted, plus whether the FINAL() literal
+// has closed. Once closed is true, further Feed calls are no-ops.
+func (d *finalDetector) Feed(delta string) (emit string, closed bool) {
+	if d.closed {
+		return "", true
+	}
+	d.buf.WriteString(delta)
+	full := d.buf.String()
+
+	if d.quote == "" {
+		match := finalOpen.FindStringSubmatchIndex(full)
+		if match == nil {
+			return "", false
+		}
+		quote := full[match[2]:match[3]]
+		// A lone " or ' (or "") at the end of the buffer may still grow
+		// into """ or ''' with the next delta, so wait for more input.
+		if len(quote) == 1 && strings.HasPrefix(strings.Repeat(quote, 2), full[match[3]:]) {
+			return "", false
+		}
+		d.quote = quote
+		d.content = match[1]
+	}
+
+	body := full[d.content:]
+	if idx := strings.Index(body, d.quote); idx >= 0 {
+		d.closed = true
+		if idx > d.emitted {
+			emit = body[d.emitted:idx]
+		}
+		d.emitted = idx + len(d.quote)
+		return emit, true
+	}
+
+	safe := len(body) - (len(d.quote) - 1)
+	if safe > d.emitted {
+		emit = body[d.emitted:safe]
+		d.emitted = safe
+	}
+	return emit, false
+}
+
+// CompletionStream streams token deltas from the backend as they arrive,
+// using a background context. Prefer CompletionStreamContext when a
+// caller-supplied deadline or cancellation signal is available.
+func (r *RLM) CompletionStream(query string, context string) (<-chan Chunk, error) {
+	return r.CompletionStreamContext(stdcontext.Background(), query, context)
+}
+
+// CompletionStreamContext runs the same recursion loop as CompletionContext,
+// but streams the FINAL() answer to the returned channel as it's produced
+// instead of returning it only once parsing completes.
+//
+// Non-final iterations (REPL execution, tool calls) are not streamed: their
+// LLM call is drained in full before the REPL/tool dispatch that today's
+// CompletionContext already does, since there is nothing a caller can act on
+// until that round-trip produces code or a tool call. Once an iteration's
+// response opens a FINAL( literal, the detector's output is forwarded to the
+// channel directly, chunk by chunk, until the closing quote is seen.
+//
+// Structured-schema validation retries (see Config.Structured) are not
+// supported in streaming mode: by the time validation could fail, the answer
+// has already been streamed to the caller, so there is nothing to retry.
+func (r *RLM) CompletionStreamContext(ctx stdcontext.Context, query string, context string) (<-chan Chunk, error) {
+	ctx = r.observer.StartTrace(ctx, "rlm.completion_stream", map[string]string{
+		"model":          r.model,
+		"query_length":   fmt.Sprintf("%d", len(query)),
+		"context_length": fmt.Sprintf("%d", len(context)),
+		"depth":          fmt.Sprintf("%d", r.currentDepth),
+	})
+
+	if query != "" && context == "" {
+		context = query
+		query = ""
+	}
+
+	if r.currentDepth >= r.maxDepth {
+		r.observer.EndTrace(ctx)
+		return nil, NewMaxDepthError(r.maxDepth)
+	}
+
+	if r.metaAgent != nil && r.currentDepth == 0 {
+		optimized, err := r.metaAgent.OptimizeQuery(ctx, query, context)
+		if err == nil && optimized != "" {
+			r.observer.Debug("rlm", "Using meta-agent optimized query")
+			query = optimized
+		}
+	}
+
+	r.stats.Depth = r.currentDepth
+	replEnv := r.buildREPLEnv(ctx, query, context)
+	systemPrompt := BuildSystemPrompt(len(context), r.currentDepth, query, r.useMetacognitive)
+	if r.toolRegistry != nil {
+		systemPrompt += buildToolsPrompt(r.toolRegistry.List())
+	}
+
+	out := make(chan Chunk)
+	go func() {
+		defer r.observer.EndTrace(ctx)
+		defer close(out)
+		r.runStream(ctx, query, systemPrompt, replEnv, out)
+	}()
+
+	return out, nil
+}
+
+// runStream drives the completion loop for CompletionStreamContext, sending
+// Chunks to out as the FINAL() answer is produced. It does not send on out
+// after returning; the caller closes out.
+func (r *RLM) runStream(ctx stdcontext.Context, query string, systemPrompt string, replEnv map[string]interface{}, out chan<- Chunk) {
+	replEnv["recursive_llm_stream"] = r.buildRecursiveStreamFn(ctx, out)
+
+	messages := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: query},
+	}
+	llmExtra := r.grammarExtra()
+
+	for iteration := 0; iteration < r.maxIterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			out <- Chunk{Depth: r.currentDepth, Done: true, Err: err}
+			return
+		}
+
+		if r.maxTokens > 0 && r.stats.TotalTokens >= r.maxTokens {
+			out <- Chunk{Depth: r.currentDepth, Done: true, Err: NewMaxTokensError(r.maxTokens, r.stats.TotalTokens)}
+			return
+		}
+
+		r.stats.Iterations = iteration + 1
+		r.metrics.OnIteration(r.currentDepth, iteration+1)
+		r.observer.RecordIteration(r.currentDepth)
+
+		response, detector, err := r.streamLLM(ctx, messages, llmExtra, out, iteration)
+		if err != nil {
+			out <- Chunk{Depth: r.currentDepth, Done: true, Err: err}
+			return
+		}
+
+		if detector.closed {
+			r.metrics.OnFinal(r.currentDepth, 0)
+			return
+		}
+
+		if IsFinal(response) {
+			if answer, ok := ParseResponse(response, replEnv); ok {
+				out <- Chunk{Depth: r.currentDepth, Content: answer, Done: true}
+				r.metrics.OnFinal(r.currentDepth, 0)
+				return
+			}
+		}
+
+		if r.toolRegistry != nil {
+			if call, ok := ParseToolCall(response); ok {
+				r.stats.ToolCalls++
+				r.observer.RecordToolCall(call.Name)
+				r.observer.Debug("rlm", "Tool call: %s(%s)", call.Name, truncateStr(string(call.Args), 200))
+
+				toolResult, err := r.toolRegistry.Invoke(ctx, call.Name, call.Args)
+				if err != nil {
+					r.observer.Error("rlm", "Tool call %s failed: %v", call.Name, err)
+					toolResult = fmt.Sprintf("Error: %s", err.Error())
+				}
+
+				messages = append(messages, Message{Role: "assistant", Content: response})
+				messages = append(messages, Message{Role: "user", Content: toolResult})
+				continue
+			}
+		}
+
+		replStart := time.Now()
+		execResult, err := r.executeREPL(ctx, response, replEnv)
+		r.metrics.OnREPLExec(r.currentDepth, err == nil, time.Since(replStart))
+		if err != nil {
+			r.observer.Debug("rlm", "REPL execution error: %v", err)
+			execResult = fmt.Sprintf("Error: %s", err.Error())
+		} else {
+			r.observer.Debug("rlm", "REPL output: %s", truncateStr(execResult, 200))
+		}
+
+		messages = append(messages, Message{Role: "assistant", Content: response})
+		messages = append(messages, Message{Role: "user", Content: execResult})
+	}
+
+	out <- Chunk{Depth: r.currentDepth, Done: true, Err: NewMaxIterationsError(r.maxIterations)}
+}
+
+// streamLLM issues one LLM call for the streaming loop, preferring the
+// backend's native streaming when r.backend implements StreamingBackend.
+// Every delta is fed through a finalDetector; whatever it emits is forwarded
+// to out immediately. Non-streaming backends are drained in full and fed to
+// the detector as a single delta, so the rest of the loop doesn't need to
+// know which path produced response.
+func (r *RLM) streamLLM(ctx stdcontext.Context, messages []Message, extra map[string]interface{}, out chan<- Chunk, iteration int) (response string, detector *finalDetector, err error) {
+	detector = &finalDetector{}
+
+	streamer, ok := r.backend.(StreamingBackend)
+	if !ok {
+		response, err = r.callLLM(ctx, messages, extra, iteration)
+		if err != nil {
+			return "", detector, err
+		}
+		emit, closed := detector.Feed(response)
+		if emit != "" || closed {
+			out <- Chunk{Depth: r.currentDepth, Content: emit, Done: closed}
+		}
+		return response, detector, nil
+	}
+
+	r.stats.LlmCalls++
+	model := r.model
+	if r.currentDepth > 0 {
+		model = r.recursiveModel
+	}
+	r.observer.Debug("llm", "Streaming %s with %d messages", model, len(messages))
+
+	params := r.extraParams
+	if len(extra) > 0 {
+		params = make(map[string]interface{}, len(r.extraParams)+len(extra))
+		for k, v := range r.extraParams {
+			params[k] = v
+		}
+		for k, v := range extra {
+			params[k] = v
+		}
+	}
+
+	request := ChatRequest{
+		Model:       model,
+		Messages:    messages,
+		APIBase:     r.apiBase,
+		APIKey:      r.apiKey,
+		Timeout:     r.timeoutSeconds,
+		ExtraParams: params,
+	}
+
+	start := time.Now()
+
+	// Cancel the in-flight HTTP stream as soon as the FINAL() literal
+	// closes, so a backend that keeps generating past it doesn't keep the
+	// connection open for text nobody will see.
+	streamCtx, cancel := stdcontext.WithCancel(ctx)
+	defer cancel()
+
+	info := LLMCallInfo{
+		Model:        model,
+		MessageCount: len(messages),
+		Temperature:  extractTemperature(params),
+	}
+	if r.observer.config.CaptureContent {
+		info.Prompt = serializeMessages(messages)
+	}
+
+	deltas, err := streamer.ChatStream(streamCtx, request)
+	if err != nil {
+		r.observer.LLMCall(info, time.Since(start), err)
+		return "", detector, err
+	}
+
+	var full strings.Builder
+	var usage TokenUsage
+	for sc := range deltas {
+		if sc.Err != nil {
+			r.observer.LLMCall(info, time.Since(start), sc.Err)
+			return "", detector, sc.Err
+		}
+		if sc.Usage != nil {
+			usage = *sc.Usage
+		}
+		if sc.Delta == "" {
+			continue
+		}
+		full.WriteString(sc.Delta)
+		emit, closed := detector.Feed(sc.Delta)
+		if emit != "" || closed {
+			out <- Chunk{Depth: r.currentDepth, Content: emit, Done: closed}
+		}
+		if closed {
+			break
+		}
+	}
+
+	duration := time.Since(start)
+	response = full.String()
+	if usage.TotalTokens == 0 {
+		usage = estimateUsage(messages, response)
+	}
+	r.stats.PromptTokens += usage.PromptTokens
+	r.stats.CompletionTokens += usage.CompletionTokens
+	r.stats.TotalTokens += usage.TotalTokens
+	r.metrics.OnLLMCall(r.currentDepth, model, usage.PromptTokens, usage.CompletionTokens, duration)
+	info.PromptTokens = usage.PromptTokens
+	info.CompletionTokens = usage.CompletionTokens
+	info.Completion = response
+	r.observer.LLMCall(info, duration, nil)
+
+	return response, detector, nil
+}
+
+// buildRecursiveStreamFn returns the recursive_llm_stream() REPL global: it
+// runs a sub-RLM with CompletionStreamContext and forwards every chunk it
+// produces to out as-is (the sub-RLM already stamps its own currentDepth on
+// each Chunk), so a UI watching out sees nested "thinking" arrive under its
+// own Depth. It returns the sub-call's FINAL() answer, same as recursive_llm().
+func (r *RLM) buildRecursiveStreamFn(ctx stdcontext.Context, out chan<- Chunk) func(string, string) string {
+	return func(subQuery string, subContext string) string {
+		if r.currentDepth+1 >= r.maxDepth {
+			msg := fmt.Sprintf("Max recursion depth (%d) reached", r.maxDepth)
+			out <- Chunk{Depth: r.currentDepth + 1, Content: msg, Done: true}
+			return msg
+		}
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Sprintf("Error: %s", err.Error())
+		}
+
+		r.observer.Debug("rlm", "Recursive stream call at depth %d: %s", r.currentDepth+1, truncateStr(subQuery, 100))
+		r.metrics.OnRecurse(r.currentDepth)
+
+		subConfig := Config{
+			RecursiveModel:   r.recursiveModel,
+			APIBase:          r.apiBase,
+			APIKey:           r.apiKey,
+			MaxDepth:         r.maxDepth,
+			MaxIterations:    r.maxIterations,
+			MaxTokens:        r.maxTokens,
+			TimeoutSeconds:   r.timeoutSeconds,
+			UseMetacognitive: r.useMetacognitive,
+			ExtraParams:      r.extraParams,
+			Backend:          r.backend,
+			MetricsObserver:  r.metrics,
+			Retry:            r.retry,
+			DetailedStats:    r.detailedStats,
+			Sandbox:          r.sandbox,
+		}
+
+		subRLM := New(r.recursiveModel, subConfig)
+		subRLM.currentDepth = r.currentDepth + 1
+		subRLM.observer = r.observer
+
+		subChunks, err := subRLM.CompletionStreamContext(ctx, subQuery, subContext)
+		if err != nil {
+			return fmt.Sprintf("Error: %s", err.Error())
+		}
+
+		var answer strings.Builder
+		for chunk := range subChunks {
+			out <- chunk
+			if chunk.Err == nil && chunk.Depth == subRLM.currentDepth {
+				answer.WriteString(chunk.Content)
+			}
+		}
+		r.mergeSubStats(subRLM.stats)
+		return answer.String()
+	}
+}
@@ -0,0 +1,96 @@
+package rlm
+
+import "testing"
+
+func TestFinalDetectorWholeResponse(t *testing.T) {
+	d := &finalDetector{}
+	emit, closed := d.Feed(`FINAL("The answer is 42")`)
+	if !closed {
+		t.Fatal("Feed() closed = false, want true")
+	}
+	if emit != "The answer is 42" {
+		t.Errorf("Feed() emit = %q, want %q", emit, "The answer is 42")
+	}
+}
+
+func TestFinalDetectorSplitAcrossChunks(t *testing.T) {
+	d := &finalDetector{}
+	var got string
+
+	for _, delta := range []string{`FIN`, `AL("The answer `, `is 42")`} {
+		emit, closed := d.Feed(delta)
+		got += emit
+		if closed && delta != `is 42")` {
+			t.Fatalf("Feed(%q) closed early", delta)
+		}
+	}
+
+	if got != "The answer is 42" {
+		t.Errorf("accumulated emit = %q, want %q", got, "The answer is 42")
+	}
+}
+
+func TestFinalDetectorWithholdsPartialClosingDelimiter(t *testing.T) {
+	d := &finalDetector{}
+
+	// The trailing 2 chars of "Hello" can't be emitted yet: they could be
+	// the start of the closing `"""`.
+	emit, closed := d.Feed(`FINAL("""Hello`)
+	if closed {
+		t.Fatal("Feed() closed true before the closing delimiter arrived")
+	}
+	if emit != "Hel" {
+		t.Errorf("Feed() emit = %q, want %q", emit, "Hel")
+	}
+
+	emit, closed = d.Feed(` world""`)
+	if closed {
+		t.Fatal("Feed() closed true before the closing delimiter arrived")
+	}
+	if emit != "lo world" {
+		t.Errorf("Feed() emit = %q, want %q", emit, "lo world")
+	}
+
+	emit, closed = d.Feed(`"`)
+	if !closed {
+		t.Fatal("Feed() closed = false, want true")
+	}
+	if emit != "" {
+		t.Errorf("Feed() emit = %q, want empty after the closing delimiter completes", emit)
+	}
+}
+
+func TestFinalDetectorIgnoresNonFinalText(t *testing.T) {
+	d := &finalDetector{}
+	emit, closed := d.Feed(`x = 1 + 1\nprint(x)`)
+	if closed {
+		t.Error("Feed() closed = true for a response with no FINAL(")
+	}
+	if emit != "" {
+		t.Errorf("Feed() emit = %q, want empty", emit)
+	}
+}
+
+func TestFinalDetectorIgnoresFinalVar(t *testing.T) {
+	d := &finalDetector{}
+	emit, closed := d.Feed(`FINAL_VAR(result)`)
+	if closed {
+		t.Error("Feed() closed = true for FINAL_VAR, which has no streamable content")
+	}
+	if emit != "" {
+		t.Errorf("Feed() emit = %q, want empty for FINAL_VAR", emit)
+	}
+}
+
+func TestFinalDetectorFeedAfterCloseIsNoop(t *testing.T) {
+	d := &finalDetector{}
+	d.Feed(`FINAL("done")`)
+
+	emit, closed := d.Feed("more text")
+	if !closed {
+		t.Error("Feed() closed = false after the literal already closed")
+	}
+	if emit != "" {
+		t.Errorf("Feed() emit = %q, want empty once closed", emit)
+	}
+}
@@ -0,0 +1,97 @@
+package rlm
+
+import "fmt"
+
+// StreamingValidator incrementally parses and validates JSON against a
+// schema as it arrives from a token stream, surfacing constraint violations
+// (wrong enum value, type mismatch, a required field still missing once its
+// parent object closes) the moment they become determinable rather than only
+// once the whole response has been read. It wraps a partialJSONParser the
+// same way StructuredCompletionStream's onValue callback does, but is built
+// for callers driving their own stream (e.g. piping a StreamingBackend's
+// ChatStream deltas) rather than going through a full Completion call.
+type StreamingValidator struct {
+	schema    *JSONSchema
+	validator SchemaValidatorBackend
+	parser    *partialJSONParser
+
+	violations []ValidationError
+}
+
+// NewStreamingValidator builds a StreamingValidator that checks incoming
+// JSON against config.Schema as it streams in, using config.ValidatorBackend
+// (or DefaultValidatorBackend) for both the incremental per-field checks and
+// Finish's terminal validation.
+func NewStreamingValidator(config *StructuredConfig) (*StreamingValidator, error) {
+	if config == nil || config.Schema == nil {
+		return nil, fmt.Errorf("structured config and schema are required")
+	}
+
+	sv := &StreamingValidator{
+		schema:    config.Schema,
+		validator: validatorFor(config),
+	}
+	sv.parser = newPartialJSONParser(sv.onValue)
+	return sv, nil
+}
+
+// Write implements io.Writer so a StreamingValidator can be handed directly
+// to anything that copies raw token deltas into it, e.g. io.Copy from a
+// streaming response body.
+func (sv *StreamingValidator) Write(p []byte) (int, error) {
+	sv.parser.Feed(string(p))
+	return len(p), nil
+}
+
+// Feed appends delta to the accumulated buffer. Equivalent to Write, without
+// the io.Writer error-return ceremony for callers that don't need it.
+func (sv *StreamingValidator) Feed(delta string) {
+	sv.parser.Feed(delta)
+}
+
+// onValue is the partialJSONParser's per-field callback: it resolves the
+// sub-schema at path and records any violation found in that value alone,
+// without waiting for the rest of the document to close. Required-field
+// violations for an object are only determinable once that object's closing
+// "}" has actually been seen, which walkSchema (called from here on each
+// completed object value) already checks.
+func (sv *StreamingValidator) onValue(path string, value interface{}) {
+	sub := schemaAtPath(sv.schema, path)
+	if sub == nil {
+		return
+	}
+	result := &ValidationResult{}
+	walkSchema(path, value, sub, sv.schema, result)
+	sv.violations = append(sv.violations, result.Errors...)
+}
+
+// Violations returns every constraint violation detected so far, in the
+// order they were discovered, so a UI can surface them immediately instead
+// of waiting for Finish.
+func (sv *StreamingValidator) Violations() []ValidationError {
+	return sv.violations
+}
+
+// Partial returns a best-effort snapshot of everything parsed so far,
+// tolerating truncation exactly as partialJSONParser.Snapshot does. The
+// result may still change as more input arrives and has not been checked
+// against required fields.
+func (sv *StreamingValidator) Partial() map[string]interface{} {
+	return sv.parser.Snapshot()
+}
+
+// Finish performs the final schema check - including required fields, which
+// can only be confirmed once the whole document is in - against everything
+// fed so far, and returns the validated result. The result is still returned
+// alongside a non-nil error so a caller can inspect what was parsed even
+// when validation fails.
+func (sv *StreamingValidator) Finish() (map[string]interface{}, error) {
+	result := sv.parser.Snapshot()
+	if result == nil {
+		return nil, fmt.Errorf("streamed input is not a complete JSON object")
+	}
+	if err := sv.validator.Validate(result, sv.schema); err != nil {
+		return result, err
+	}
+	return result, nil
+}
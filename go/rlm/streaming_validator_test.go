@@ -0,0 +1,88 @@
+package rlm
+
+import "testing"
+
+func TestStreamingValidator_PartialReturnsBestEffortSnapshot(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "number"}},
+		Required:   []string{"name", "age"},
+	}
+	sv, err := NewStreamingValidator(&StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("NewStreamingValidator() error = %v", err)
+	}
+
+	sv.Feed(`{"name": "Ada"`)
+
+	partial := sv.Partial()
+	if partial["name"] != "Ada" {
+		t.Errorf("Partial()[name] = %v, want Ada", partial["name"])
+	}
+}
+
+func TestStreamingValidator_SurfacesEnumViolationBeforeDocumentCloses(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"status": {Type: "string", Enum: []string{"open", "closed"}},
+		},
+		Required: []string{"status"},
+	}
+	sv, err := NewStreamingValidator(&StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("NewStreamingValidator() error = %v", err)
+	}
+
+	sv.Feed(`{"status": "bogus"`) // document not closed yet
+
+	if len(sv.Violations()) == 0 {
+		t.Error("expected the bad enum value to be flagged as soon as the field completed, before the document closed")
+	}
+}
+
+func TestStreamingValidator_FinishValidatesCompleteDocument(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+	sv, err := NewStreamingValidator(&StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("NewStreamingValidator() error = %v", err)
+	}
+
+	sv.Feed(`{"name": "Ada"}`)
+
+	result, err := sv.Finish()
+	if err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if result["name"] != "Ada" {
+		t.Errorf("Finish() result = %v, want name=Ada", result)
+	}
+}
+
+func TestStreamingValidator_FinishReportsMissingRequiredField(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "number"}},
+		Required:   []string{"name", "age"},
+	}
+	sv, err := NewStreamingValidator(&StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("NewStreamingValidator() error = %v", err)
+	}
+
+	sv.Feed(`{"name": "Ada"}`)
+
+	if _, err := sv.Finish(); err == nil {
+		t.Error("Finish() expected an error for a missing required field")
+	}
+}
+
+func TestNewStreamingValidator_RequiresSchema(t *testing.T) {
+	if _, err := NewStreamingValidator(&StructuredConfig{}); err == nil {
+		t.Error("expected an error when config.Schema is nil")
+	}
+}
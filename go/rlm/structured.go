@@ -1,19 +1,34 @@
 package rlm
 
 import (
+	stdcontext "context"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
-// StructuredCompletion executes a structured completion with schema validation
+// StructuredCompletion executes a structured completion with schema
+// validation, bound to a background context. Prefer StructuredCompletionContext
+// when a caller-supplied deadline or cancellation signal is available - an
+// LLM-authored structured extraction can recurse via recursive_llm just like
+// an ordinary completion, and inherits the same need to cascade deadlines.
 func (r *RLM) StructuredCompletion(query string, context string, config *StructuredConfig) (map[string]interface{}, RLMStats, error) {
-	ctx := r.observer.StartSpan("rlm.structured_completion", map[string]string{
+	return r.StructuredCompletionContext(stdcontext.Background(), query, context, config)
+}
+
+// StructuredCompletionContext is StructuredCompletion's context-aware
+// sibling: callCtx bounds the LLM calls it makes (directly, or via its
+// parallel sub-task fan-out) and is passed through to
+// structuredCompletionDirect/Parallel so a canceled or expired callCtx
+// aborts in-flight sub-tasks instead of letting them run to completion.
+func (r *RLM) StructuredCompletionContext(callCtx stdcontext.Context, query string, context string, config *StructuredConfig) (map[string]interface{}, RLMStats, error) {
+	callCtx, span := r.observer.StartSpan(callCtx, "rlm.structured_completion", map[string]string{
 		"query_length":   fmt.Sprintf("%d", len(query)),
 		"context_length": fmt.Sprintf("%d", len(context)),
 	})
-	defer r.observer.EndSpan(ctx)
+	defer span.End()
 
 	if config == nil || config.Schema == nil {
 		return nil, RLMStats{}, fmt.Errorf("structured config and schema are required")
@@ -28,43 +43,81 @@ func (r *RLM) StructuredCompletion(query string, context string, config *Structu
 
 	// Apply meta-agent optimization for structured queries if enabled
 	if r.metaAgent != nil {
-		optimized, err := r.metaAgent.OptimizeForStructured(query, context, config.Schema)
+		optimized, err := r.metaAgent.OptimizeForStructured(callCtx, query, context, config.Schema)
 		if err == nil && optimized != "" {
 			r.observer.Debug("structured", "Using meta-agent optimized query for structured extraction")
 			query = optimized
 		}
 	}
 
-	// Create schema validator using Google's jsonschema-go for enhanced validation
-	validator, validatorErr := NewSchemaValidator(config.Schema)
-	if validatorErr != nil {
-		r.observer.Debug("structured", "Schema validator creation info: %v (using fallback)", validatorErr)
-	}
-	_ = validator // Available for enhanced validation in parseAndValidateJSON
-
 	// Decompose schema into sub-tasks
-	subTasks := decomposeSchema(config.Schema)
+	subTasks := decomposeSchemaWith(config)
 	r.observer.Debug("structured", "Schema decomposed into %d subtasks", len(subTasks))
 
 	// If simple schema or parallel disabled, use direct method
 	if len(subTasks) <= 2 || !config.ParallelExecution {
 		r.observer.Debug("structured", "Using direct completion method")
-		return r.structuredCompletionDirect(query, context, config)
+		return r.structuredCompletionDirectContext(callCtx, query, context, config)
 	}
 
 	// Execute with parallel goroutines, with fallback to direct
 	r.observer.Debug("structured", "Using parallel completion with %d subtasks", len(subTasks))
-	result, stats, err := r.structuredCompletionParallel(query, context, config, subTasks)
+	result, stats, err := r.structuredCompletionParallel(callCtx, query, context, config, subTasks)
 	if err != nil {
 		// Fallback to direct (single-call) method when parallel fails
 		r.observer.Debug("structured", "Parallel execution failed (%v), falling back to direct method", err)
-		return r.structuredCompletionDirect(query, context, config)
+		return r.structuredCompletionDirectContext(callCtx, query, context, config)
 	}
 	return result, stats, nil
 }
 
-// structuredCompletionDirect performs a single structured completion
+// StructuredCompletionJSON is StructuredCompletion's schema-only sibling: it
+// builds a StructuredConfig from schema alone (default MaxRetries, no
+// response_format/tools overrides) and marshals the validated result back to
+// raw JSON bytes, for callers that want to forward the response as-is (e.g.
+// writing it directly to an HTTP response body) instead of decoding the map
+// StructuredCompletion returns. Bound to a background context; prefer
+// StructuredCompletionJSONContext when a caller-supplied deadline or
+// cancellation signal is available.
+func (r *RLM) StructuredCompletionJSON(query string, context string, schema *JSONSchema) (json.RawMessage, RLMStats, error) {
+	return r.StructuredCompletionJSONContext(stdcontext.Background(), query, context, schema)
+}
+
+// StructuredCompletionJSONContext is StructuredCompletionJSON's context-aware
+// sibling.
+func (r *RLM) StructuredCompletionJSONContext(ctx stdcontext.Context, query string, context string, schema *JSONSchema) (json.RawMessage, RLMStats, error) {
+	result, stats, err := r.StructuredCompletionContext(ctx, query, context, &StructuredConfig{Schema: schema})
+	if err != nil {
+		return nil, stats, err
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, stats, err
+	}
+	return raw, stats, nil
+}
+
+// validatorFor returns config.ValidatorBackend, or DefaultValidatorBackend if
+// the caller didn't set one.
+func validatorFor(config *StructuredConfig) SchemaValidatorBackend {
+	if config.ValidatorBackend != nil {
+		return config.ValidatorBackend
+	}
+	return DefaultValidatorBackend
+}
+
+// structuredCompletionDirect performs a single structured completion, bound
+// to a background context. Prefer structuredCompletionDirectContext when a
+// caller-supplied ctx is available.
 func (r *RLM) structuredCompletionDirect(query string, context string, config *StructuredConfig) (map[string]interface{}, RLMStats, error) {
+	return r.structuredCompletionDirectContext(stdcontext.Background(), query, context, config)
+}
+
+// structuredCompletionDirectContext is structuredCompletionDirect's
+// context-aware sibling: ctx bounds the LLM call and is passed through to
+// applyEnforcement/repairField so a repair round-trip honors the same
+// deadline.
+func (r *RLM) structuredCompletionDirectContext(ctx stdcontext.Context, query string, context string, config *StructuredConfig) (map[string]interface{}, RLMStats, error) {
 	schemaJSON, _ := json.Marshal(config.Schema)
 
 	// Build comprehensive prompt with context and schema
@@ -76,20 +129,20 @@ func (r *RLM) structuredCompletionDirect(query string, context string, config *S
 
 	prompt := fmt.Sprintf(
 		"You are a data extraction assistant. Extract information from the context and return it as JSON.\n\n"+
-		"Context:\n%s\n\n"+
-		"Task: %s\n\n"+
-		"Required JSON Schema:\n%s%s\n\n"+
-		"%s"+
-		"CRITICAL INSTRUCTIONS:\n"+
-		"1. Return ONLY valid JSON - no explanations, no markdown, no code blocks\n"+
-		"2. The JSON must match the schema EXACTLY\n"+
-		"3. Include ALL required fields (see list above)\n"+
-		"4. Use correct data types (strings in quotes, numbers without quotes, arrays in [], objects in {})\n"+
-		"5. For arrays, return actual JSON arrays [] not objects\n"+
-		"6. For enum fields, use ONLY the EXACT values listed - do not paraphrase or substitute\n"+
-		"7. For nested objects, ensure ALL required fields within those objects are included\n"+
-		"8. Start your response directly with { or [ depending on the schema\n\n"+
-		"JSON Response:",
+			"Context:\n%s\n\n"+
+			"Task: %s\n\n"+
+			"Required JSON Schema:\n%s%s\n\n"+
+			"%s"+
+			"CRITICAL INSTRUCTIONS:\n"+
+			"1. Return ONLY valid JSON - no explanations, no markdown, no code blocks\n"+
+			"2. The JSON must match the schema EXACTLY\n"+
+			"3. Include ALL required fields (see list above)\n"+
+			"4. Use correct data types (strings in quotes, numbers without quotes, arrays in [], objects in {})\n"+
+			"5. For arrays, return actual JSON arrays [] not objects\n"+
+			"6. For enum fields, use ONLY the EXACT values listed - do not paraphrase or substitute\n"+
+			"7. For nested objects, ensure ALL required fields within those objects are included\n"+
+			"8. Start your response directly with { or [ depending on the schema\n\n"+
+			"JSON Response:",
 		context, query, string(schemaJSON), requiredFieldsHint, constraints,
 	)
 
@@ -102,8 +155,33 @@ func (r *RLM) structuredCompletionDirect(query string, context string, config *S
 		{Role: "user", Content: prompt},
 	}
 
-	for attempt := 0; attempt < config.MaxRetries; attempt++ {
-		result, err := r.callLLM(messages)
+	llmExtra, responseMode, constrained := r.resolveStructuredOutput(config)
+	if len(llmExtra) == 0 {
+		llmExtra, constrained = r.structuredGrammarExtra(config.Schema, config.GrammarField)
+		responseMode = ""
+		if constrained {
+			responseMode = ProviderModeLlamaCppGrammar
+		}
+	}
+	stats.ResponseFormatMode = string(responseMode)
+
+	maxRetries := config.MaxRetries
+	if constrained {
+		// A provider that guarantees schema-conformant output (a native
+		// response_format/responseSchema surface, or a ConstrainedDecoder
+		// backend) can't emit output that fails validation, so the
+		// parse-validate-retry loop this method exists for is unnecessary
+		// overhead - one call is enough.
+		maxRetries = 1
+	}
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			lastErr = err
+			break
+		}
+
+		result, err := r.callLLM(ctx, messages, llmExtra, attempt)
 		stats.LlmCalls++
 		stats.Iterations++
 
@@ -112,10 +190,27 @@ func (r *RLM) structuredCompletionDirect(query string, context string, config *S
 			continue
 		}
 
-		parsed, err := parseAndValidateJSON(result, config.Schema)
+		if len(config.EnforcementActions) > 0 && config.Schema.Type == "object" {
+			parsed, retryFeedback, done, enforceErr := r.applyEnforcement(ctx, result, context, config, &stats)
+			if done {
+				stats.ParsingRetries = attempt
+				stats.NativeStructured = constrained && attempt == 0
+				return parsed, stats, nil
+			}
+			lastErr = enforceErr
+			if attempt < maxRetries-1 {
+				messages = append(messages,
+					Message{Role: "assistant", Content: result},
+					Message{Role: "user", Content: retryFeedback},
+				)
+			}
+			continue
+		}
+
+		parsed, err := parseAndValidateJSON(result, config.Schema, validatorFor(config))
 		if err != nil {
 			lastErr = err
-			if attempt < config.MaxRetries-1 {
+			if attempt < maxRetries-1 {
 				// Build detailed validation feedback similar to Instructor
 				validationFeedback := buildValidationFeedback(err, config.Schema, result)
 
@@ -129,101 +224,266 @@ func (r *RLM) structuredCompletionDirect(query string, context string, config *S
 		}
 
 		stats.ParsingRetries = attempt
+		stats.NativeStructured = constrained && attempt == 0
 		return parsed, stats, nil
 	}
 
-	return nil, stats, fmt.Errorf("failed to get valid structured output after %d attempts: %v", config.MaxRetries, lastErr)
+	return nil, stats, fmt.Errorf("failed to get valid structured output after %d attempts: %v", maxRetries, lastErr)
 }
 
-// structuredCompletionParallel executes sub-tasks in parallel
-func (r *RLM) structuredCompletionParallel(query string, context string, config *StructuredConfig, subTasks []SubTask) (map[string]interface{}, RLMStats, error) {
+// structuredCompletionParallel executes sub-tasks honoring SubTask.Dependencies:
+// tasks are scheduled in topological waves, with goroutine fan-out within
+// each wave, so a task only runs once every task it depends on has resolved.
+// Tasks with no dependencies (the common case today, since decomposeSchema
+// produces a flat field list) all land in the first wave and run together.
+func (r *RLM) structuredCompletionParallel(ctx stdcontext.Context, query string, context string, config *StructuredConfig, subTasks []SubTask) (map[string]interface{}, RLMStats, error) {
 	results := make(map[string]interface{})
+	resultsByTaskID := make(map[string]interface{})
 	var resultsMutex sync.Mutex
 
-	var wg sync.WaitGroup
-	errors := make([]error, len(subTasks))
-
 	totalStats := RLMStats{}
 	var statsMutex sync.Mutex
 
-	for i, task := range subTasks {
-		wg.Add(1)
-		go func(idx int, t SubTask) {
-			defer wg.Done()
+	byID := make(map[string]SubTask, len(subTasks))
+	for _, t := range subTasks {
+		byID[t.ID] = t
+	}
 
-			fieldName := strings.TrimPrefix(t.ID, "field_")
+	if cycle := detectCycle(subTasks); cycle != nil {
+		return nil, totalStats, fmt.Errorf("structured schema decomposition has a dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
 
-			// Wrap the sub-schema in an object wrapper so the LLM always
-			// returns a JSON object with a predictable key. This eliminates
-			// ambiguity for non-object field types (string, number, array, etc.)
-			wrappedSchema := wrapFieldSchema(fieldName, t.Schema)
+	done := make(map[string]bool, len(subTasks))
+	var taskErrors []string
 
-			taskQuery := fmt.Sprintf("%s\n\nSpecific focus: %s", query, t.Query)
-			taskConfig := &StructuredConfig{
-				Schema:            wrappedSchema,
-				ParallelExecution: false, // Disable nested parallelization
-				MaxRetries:        config.MaxRetries,
+	for len(done) < len(subTasks) && len(taskErrors) == 0 {
+		var wave []SubTask
+		for _, t := range subTasks {
+			if done[t.ID] {
+				continue
 			}
-
-			result, stats, err := r.structuredCompletionDirect(taskQuery, context, taskConfig)
-			if err != nil {
-				errors[idx] = fmt.Errorf("task %s failed: %w", t.ID, err)
-				return
+			if dependenciesSatisfied(t.Dependencies, done) {
+				wave = append(wave, t)
 			}
+		}
 
-			resultsMutex.Lock()
-			// Extract the field value from the wrapper object
-			if val, ok := result[fieldName]; ok {
-				results[fieldName] = val
-			} else {
-				// Fallback: if the LLM didn't use the wrapper key, try __value__ or the result itself
-				if val, ok := result["__value__"]; ok {
-					results[fieldName] = val
+		if len(wave) == 0 {
+			return nil, totalStats, fmt.Errorf("structured decomposition has an unsatisfiable or circular dependency among: %v", pendingTaskIDs(subTasks, done))
+		}
+
+		var wg sync.WaitGroup
+		waveErrors := make([]error, len(wave))
+
+		concurrency := config.MaxConcurrency
+		if concurrency <= 0 || concurrency > len(wave) {
+			concurrency = len(wave)
+		}
+		sem := make(chan struct{}, concurrency)
+
+		// waveCtx is canceled as soon as any task in this wave fails, so its
+		// still-running siblings abort their in-flight LLM calls instead of
+		// running to completion after the wave is already doomed.
+		waveCtx, cancelWave := stdcontext.WithCancel(ctx)
+
+		for i, task := range wave {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(idx int, t SubTask) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				fieldName := strings.TrimPrefix(t.ID, "field_")
+
+				// Wrap the sub-schema in an object wrapper so the LLM always
+				// returns a JSON object with a predictable key. This eliminates
+				// ambiguity for non-object field types (string, number, array, etc.)
+				wrappedSchema := wrapFieldSchema(fieldName, t.Schema)
+
+				taskQuery := fmt.Sprintf("%s\n\nSpecific focus: %s", query, t.Query)
+				if len(t.Dependencies) > 0 {
+					taskQuery += "\n\n" + dependencyHint(t.Dependencies, resultsByTaskID, &resultsMutex)
+				}
+
+				taskConfig := &StructuredConfig{
+					Schema:            wrappedSchema,
+					ParallelExecution: false, // Disable nested parallelization
+					MaxRetries:        config.MaxRetries,
+				}
+
+				start := time.Now()
+				result, stats, err := r.structuredCompletionDirectContext(waveCtx, taskQuery, context, taskConfig)
+				duration := time.Since(start)
+				if err != nil {
+					waveErrors[idx] = fmt.Errorf("task %s failed: %w", t.ID, err)
+					cancelWave()
+					return
+				}
+
+				var fieldValue interface{}
+				// Extract the field value from the wrapper object
+				if val, ok := result[fieldName]; ok {
+					fieldValue = val
+				} else if val, ok := result["__value__"]; ok {
+					// Fallback: if the LLM didn't use the wrapper key, try __value__ or the result itself
+					fieldValue = val
 				} else if len(result) == 1 {
 					// Single-key result, use whatever value is there
 					for _, v := range result {
-						results[fieldName] = v
+						fieldValue = v
 					}
 				} else {
 					// Use the entire result map as the field value (for object-typed fields)
-					results[fieldName] = result
+					fieldValue = result
 				}
-			}
-			resultsMutex.Unlock()
 
-			statsMutex.Lock()
-			totalStats.LlmCalls += stats.LlmCalls
-			totalStats.Iterations += stats.Iterations
-			if stats.Depth > totalStats.Depth {
-				totalStats.Depth = stats.Depth
-			}
-			totalStats.ParsingRetries += stats.ParsingRetries
-			statsMutex.Unlock()
-		}(i, task)
-	}
+				resultsMutex.Lock()
+				results[fieldName] = fieldValue
+				resultsByTaskID[t.ID] = fieldValue
+				resultsMutex.Unlock()
 
-	wg.Wait()
+				statsMutex.Lock()
+				totalStats.LlmCalls += stats.LlmCalls
+				totalStats.Iterations += stats.Iterations
+				if stats.Depth > totalStats.Depth {
+					totalStats.Depth = stats.Depth
+				}
+				totalStats.ParsingRetries += stats.ParsingRetries
+				totalStats.NodeStats = append(totalStats.NodeStats, NodeStat{
+					TaskID:     t.ID,
+					DurationMs: duration.Milliseconds(),
+					LlmCalls:   stats.LlmCalls,
+				})
+				statsMutex.Unlock()
+			}(i, task)
+		}
 
-	// Collect all errors
-	var allErrors []string
-	for _, err := range errors {
-		if err != nil {
-			allErrors = append(allErrors, err.Error())
+		wg.Wait()
+		cancelWave()
+
+		for i, t := range wave {
+			done[t.ID] = true
+			if waveErrors[i] != nil {
+				taskErrors = append(taskErrors, waveErrors[i].Error())
+			}
 		}
 	}
-	if len(allErrors) > 0 {
+
+	if len(taskErrors) > 0 {
 		return nil, totalStats, fmt.Errorf("parallel execution failed (%d/%d tasks): %s",
-			len(allErrors), len(subTasks), strings.Join(allErrors, "; "))
+			len(taskErrors), len(subTasks), strings.Join(taskErrors, "; "))
 	}
 
 	// Validate merged result against full schema
-	if err := validateAgainstSchema(results, config.Schema); err != nil {
+	if err := validatorFor(config).Validate(results, config.Schema); err != nil {
 		return nil, totalStats, fmt.Errorf("merged result validation failed: %w", err)
 	}
 
 	return results, totalStats, nil
 }
 
+// detectCycle runs a DFS over subTasks' Dependencies and returns the first
+// dependency cycle found as an ordered chain of task IDs (e.g.
+// ["field_a", "field_b", "field_a"]), or nil if the dependency graph is
+// acyclic. Checking this up front lets structuredCompletionParallel fail
+// fast with a precise cycle description instead of discovering the same
+// problem later as a stalled wave with no satisfiable tasks.
+func detectCycle(subTasks []SubTask) []string {
+	byID := make(map[string]SubTask, len(subTasks))
+	for _, t := range subTasks {
+		byID[t.ID] = t
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(subTasks))
+	var path []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == id {
+					return append(append([]string{}, path[i:]...), id)
+				}
+			}
+			return []string{id, id}
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range byID[id].Dependencies {
+			if _, ok := byID[dep]; !ok {
+				continue // dangling dependency; reported separately by dependenciesSatisfied
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, t := range subTasks {
+		if state[t.ID] == unvisited {
+			if cycle := visit(t.ID); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// dependenciesSatisfied reports whether every dependency ID is already done.
+func dependenciesSatisfied(dependencies []string, done map[string]bool) bool {
+	for _, dep := range dependencies {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// pendingTaskIDs lists the IDs of tasks that have not yet completed, for
+// inclusion in a dependency-cycle error message.
+func pendingTaskIDs(subTasks []SubTask, done map[string]bool) []string {
+	var pending []string
+	for _, t := range subTasks {
+		if !done[t.ID] {
+			pending = append(pending, t.ID)
+		}
+	}
+	return pending
+}
+
+// dependencyHint summarizes the already-resolved values of a task's
+// dependencies so the LLM can reference them instead of re-extracting them.
+func dependencyHint(dependencies []string, resultsByTaskID map[string]interface{}, mu *sync.Mutex) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("Already-extracted values this field may depend on:\n")
+	for _, dep := range dependencies {
+		value, ok := resultsByTaskID[dep]
+		if !ok {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", strings.TrimPrefix(dep, "field_"), encoded)
+	}
+	return b.String()
+}
+
 // wrapFieldSchema wraps a field's schema inside an object schema with a single
 // key matching the field name. This ensures the LLM always returns a JSON object
 // with a predictable structure, avoiding ambiguity for non-object fields.
@@ -241,6 +501,38 @@ func wrapFieldSchema(fieldName string, schema *JSONSchema) *JSONSchema {
 	}
 }
 
+// decomposeSchemaWith decomposes config.Schema using config.Decomposer if
+// the caller supplied one, falling back to decomposeSchema otherwise, then
+// overlays config.Dependencies onto the resulting sub-tasks.
+func decomposeSchemaWith(config *StructuredConfig) []SubTask {
+	var subTasks []SubTask
+	if config.Decomposer != nil {
+		subTasks = config.Decomposer.Decompose(config.Schema)
+	} else {
+		subTasks = decomposeSchema(config.Schema)
+	}
+	return applyProgrammaticDependencies(subTasks, config.Dependencies)
+}
+
+// applyProgrammaticDependencies merges config.Dependencies (keyed by
+// top-level field name) onto the matching "field_<name>" sub-task, appending
+// to whatever schema-derived dependencies decomposeSchema already assigned
+// it rather than replacing them.
+func applyProgrammaticDependencies(subTasks []SubTask, dependencies map[string][]string) []SubTask {
+	if len(dependencies) == 0 {
+		return subTasks
+	}
+	for i, t := range subTasks {
+		fieldName := strings.TrimPrefix(t.ID, "field_")
+		extra, ok := dependencies[fieldName]
+		if !ok {
+			continue
+		}
+		subTasks[i].Dependencies = append(append([]string{}, t.Dependencies...), dependsOnTaskIDs(extra)...)
+	}
+	return subTasks
+}
+
 // decomposeSchema breaks down a schema into independent sub-tasks
 func decomposeSchema(schema *JSONSchema) []SubTask {
 	var subTasks []SubTask
@@ -251,13 +543,36 @@ func decomposeSchema(schema *JSONSchema) []SubTask {
 
 	for fieldName, fieldSchema := range schema.Properties {
 		taskID := fmt.Sprintf("field_%s", fieldName)
-		query := generateFieldQuery(fieldName, fieldSchema)
+
+		if discriminator, ok := detectDiscriminator(fieldSchema.OneOf); ok {
+			// oneOf fields are a two-step extraction: first pin down which
+			// variant applies, then extract the full shape with that choice
+			// surfaced as a dependency hint, so the second call can commit to
+			// the matching branch instead of guessing across all of them.
+			discriminatorTaskID := taskID + "_discriminator"
+			subTasks = append(subTasks, SubTask{
+				ID:           discriminatorTaskID,
+				Query:        generateDiscriminatorQuery(fieldName, discriminator, fieldSchema.OneOf),
+				Schema:       &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{discriminator: {Type: "string"}}, Required: []string{discriminator}},
+				Dependencies: []string{},
+				Path:         []string{fieldName, discriminator},
+			})
+
+			subTasks = append(subTasks, SubTask{
+				ID:           taskID,
+				Query:        generateFieldQuery(fieldName, fieldSchema),
+				Schema:       fieldSchema,
+				Dependencies: []string{discriminatorTaskID},
+				Path:         []string{fieldName},
+			})
+			continue
+		}
 
 		subTasks = append(subTasks, SubTask{
 			ID:           taskID,
-			Query:        query,
+			Query:        generateFieldQuery(fieldName, fieldSchema),
 			Schema:       fieldSchema,
-			Dependencies: []string{},
+			Dependencies: dependsOnTaskIDs(fieldSchema.DependsOn),
 			Path:         []string{fieldName},
 		})
 	}
@@ -265,6 +580,19 @@ func decomposeSchema(schema *JSONSchema) []SubTask {
 	return subTasks
 }
 
+// dependsOnTaskIDs maps the sibling property names in a JSONSchema.DependsOn
+// declaration to the sub-task IDs decomposeSchema assigns them.
+func dependsOnTaskIDs(dependsOn []string) []string {
+	if len(dependsOn) == 0 {
+		return []string{}
+	}
+	ids := make([]string, len(dependsOn))
+	for i, name := range dependsOn {
+		ids[i] = fmt.Sprintf("field_%s", name)
+	}
+	return ids
+}
+
 // generateSchemaConstraints creates human-readable constraint descriptions
 func generateSchemaConstraints(schema *JSONSchema) string {
 	var constraints []string
@@ -366,6 +694,11 @@ func generateFieldQuery(fieldName string, schema *JSONSchema) string {
 	// Start with field name
 	queryParts = append(queryParts, fmt.Sprintf("Extract the '%s' field from the conversation.", fieldName))
 
+	if len(schema.OneOf) > 0 {
+		queryParts = append(queryParts, buildOneOfDescription(fieldName, schema.OneOf))
+		return strings.Join(queryParts, " ")
+	}
+
 	// Add type-specific instructions
 	switch schema.Type {
 	case "object":
@@ -436,18 +769,113 @@ func generateFieldQuery(fieldName string, schema *JSONSchema) string {
 	return strings.Join(queryParts, " ")
 }
 
+// detectDiscriminator finds a property shared by every branch of a oneOf
+// whose value is a single-element enum (the common way to model an OpenAPI-
+// style discriminator without a dedicated "const" keyword), and returns its
+// name. It reports false if the branches don't share such a property.
+func detectDiscriminator(branches []*JSONSchema) (string, bool) {
+	if len(branches) < 2 {
+		return "", false
+	}
+
+	var shared map[string]bool
+	for _, branch := range branches {
+		if branch == nil || branch.Properties == nil {
+			return "", false
+		}
+
+		tagged := make(map[string]bool)
+		for name, propSchema := range branch.Properties {
+			if len(propSchema.Enum) == 1 {
+				tagged[name] = true
+			}
+		}
+
+		if shared == nil {
+			shared = tagged
+			continue
+		}
+		for name := range shared {
+			if !tagged[name] {
+				delete(shared, name)
+			}
+		}
+	}
+
+	for name := range shared {
+		return name, true
+	}
+	return "", false
+}
+
+// generateDiscriminatorQuery builds a focused query for the first step of a
+// oneOf extraction: pinning down which variant's discriminator tag applies,
+// before the full shape is extracted.
+func generateDiscriminatorQuery(fieldName, discriminator string, branches []*JSONSchema) string {
+	tags := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		if propSchema, ok := branch.Properties[discriminator]; ok && len(propSchema.Enum) == 1 {
+			tags = append(tags, propSchema.Enum[0])
+		}
+	}
+
+	return fmt.Sprintf(
+		"Before extracting the '%s' field, determine which variant applies based on its '%s' value. "+
+			"Return a JSON object like {\"%s\": \"value\"} where value is EXACTLY one of: %s.",
+		fieldName, discriminator, discriminator, strings.Join(tags, ", "),
+	)
+}
+
+// buildOneOfDescription lists the alternative shapes of a oneOf field so the
+// LLM can pick the one that matches, tagging each shape with its
+// discriminator value when one can be detected.
+func buildOneOfDescription(fieldName string, branches []*JSONSchema) string {
+	discriminator, hasDiscriminator := detectDiscriminator(branches)
+
+	shapes := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		fields := make([]string, 0, len(branch.Required))
+		for _, req := range branch.Required {
+			if propSchema, ok := branch.Properties[req]; ok {
+				fields = append(fields, fmt.Sprintf("'%s' (%s)", req, propSchema.Type))
+			}
+		}
+
+		if hasDiscriminator {
+			if propSchema, ok := branch.Properties[discriminator]; ok && len(propSchema.Enum) == 1 {
+				shapes = append(shapes, fmt.Sprintf("  - %s=%q: %s", discriminator, propSchema.Enum[0], strings.Join(fields, ", ")))
+				continue
+			}
+		}
+		shapes = append(shapes, fmt.Sprintf("  - %s", strings.Join(fields, ", ")))
+	}
+
+	return fmt.Sprintf(
+		"Return a JSON object with the key '%s' matching EXACTLY ONE of these alternative shapes:\n%s",
+		fieldName, strings.Join(shapes, "\n"),
+	)
+}
+
 // parseAndValidateJSON extracts JSON from response and validates against schema
-func parseAndValidateJSON(result string, schema *JSONSchema) (map[string]interface{}, error) {
-	// Remove markdown code blocks if present
-	result = strings.TrimSpace(result)
-	if strings.HasPrefix(result, "```") {
-		// Extract content between ``` markers
-		lines := strings.Split(result, "\n")
-		if len(lines) > 2 {
-			// Remove first line (```json or ```) and last line (```)
-			result = strings.Join(lines[1:len(lines)-1], "\n")
-			result = strings.TrimSpace(result)
+func parseAndValidateJSON(result string, schema *JSONSchema, backend ...SchemaValidatorBackend) (map[string]interface{}, error) {
+	validator := DefaultValidatorBackend
+	if len(backend) > 0 && backend[0] != nil {
+		validator = backend[0]
+	}
+	result = stripJSONFences(result)
+
+	// A root-level oneOf has no single type of its own: try each branch in
+	// turn and commit to the first one whose validation succeeds.
+	if len(schema.OneOf) > 0 {
+		var branchErrors []string
+		for _, branch := range schema.OneOf {
+			if parsed, err := parseAndValidateJSON(result, branch, validator); err == nil {
+				return parsed, nil
+			} else {
+				branchErrors = append(branchErrors, err.Error())
+			}
 		}
+		return nil, fmt.Errorf("no oneOf branch matched: %s", strings.Join(branchErrors, "; "))
 	}
 
 	// For non-object schemas (arrays, primitives), handle special cases
@@ -503,7 +931,7 @@ func parseAndValidateJSON(result string, schema *JSONSchema) (map[string]interfa
 			}
 
 			// Validate the unwrapped value
-			if err := validateValue(value, schema); err != nil {
+			if err := validator.Validate(value, schema); err != nil {
 				return nil, err
 			}
 
@@ -519,7 +947,7 @@ func parseAndValidateJSON(result string, schema *JSONSchema) (map[string]interfa
 
 	// First, try to parse the entire trimmed string
 	if err := json.Unmarshal([]byte(result), &parsed); err == nil {
-		if err := validateAgainstSchema(parsed, schema); err != nil {
+		if err := validator.Validate(parsed, schema); err != nil {
 			return nil, err
 		}
 		return parsed, nil
@@ -536,7 +964,7 @@ func parseAndValidateJSON(result string, schema *JSONSchema) (map[string]interfa
 	for _, candidate := range jsonCandidates {
 		var candidateMap map[string]interface{}
 		if err := json.Unmarshal([]byte(candidate), &candidateMap); err == nil {
-			if err := validateAgainstSchema(candidateMap, schema); err == nil {
+			if err := validator.Validate(candidateMap, schema); err == nil {
 				return candidateMap, nil
 			}
 		}
@@ -625,80 +1053,18 @@ func truncateForError(s string) string {
 	return s
 }
 
-// validateAgainstSchema validates data against a JSON schema
-func validateAgainstSchema(data map[string]interface{}, schema *JSONSchema) error {
-	if schema.Type != "object" {
-		return nil // Only validate object types for now
-	}
-
-	// Check required fields
-	for _, required := range schema.Required {
-		if _, exists := data[required]; !exists {
-			return fmt.Errorf("missing required field: %s", required)
-		}
-	}
-
-	// Validate properties
-	if schema.Properties != nil {
-		for key, fieldSchema := range schema.Properties {
-			value, exists := data[key]
-			if !exists && contains(schema.Required, key) {
-				return fmt.Errorf("missing required field: %s", key)
-			}
-			if exists {
-				if err := validateValue(value, fieldSchema); err != nil {
-					return fmt.Errorf("field %s: %w", key, err)
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
-// validateValue validates a value against a schema
-func validateValue(value interface{}, schema *JSONSchema) error {
-	if value == nil && schema.Nullable {
-		return nil
-	}
-
-	switch schema.Type {
-	case "string":
-		if _, ok := value.(string); !ok {
-			return fmt.Errorf("expected string, got %T", value)
-		}
-	case "number", "integer":
-		switch value.(type) {
-		case float64, float32, int, int32, int64:
-			return nil
-		default:
-			return fmt.Errorf("expected number, got %T", value)
-		}
-	case "boolean":
-		if _, ok := value.(bool); !ok {
-			return fmt.Errorf("expected boolean, got %T", value)
-		}
-	case "array":
-		arr, ok := value.([]interface{})
-		if !ok {
-			return fmt.Errorf("expected array, got %T", value)
-		}
-		if schema.Items != nil {
-			for i, item := range arr {
-				if err := validateValue(item, schema.Items); err != nil {
-					return fmt.Errorf("array item %d: %w", i, err)
-				}
-			}
-		}
-	case "object":
-		obj, ok := value.(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("expected object, got %T", value)
+// stripJSONFences trims whitespace and, if result is wrapped in a markdown
+// code block (```json ... ``` or ``` ... ```), removes the fence lines.
+func stripJSONFences(result string) string {
+	result = strings.TrimSpace(result)
+	if strings.HasPrefix(result, "```") {
+		lines := strings.Split(result, "\n")
+		if len(lines) > 2 {
+			result = strings.Join(lines[1:len(lines)-1], "\n")
+			result = strings.TrimSpace(result)
 		}
-		return validateAgainstSchema(obj, schema)
 	}
-
-	return nil
+	return result
 }
 
 func contains(arr []string, item string) bool {
@@ -712,6 +1078,28 @@ func contains(arr []string, item string) bool {
 
 // buildExampleJSON creates an example JSON structure for nested objects
 func buildExampleJSON(schema *JSONSchema) string {
+	return buildExampleJSONRec(schema, schema, map[*JSONSchema]bool{})
+}
+
+// buildExampleJSONRec resolves schema.Ref against root before building the
+// example, so a $ref-based field gets a real example instead of an empty
+// object. seen tracks visited schema nodes by pointer identity (the same
+// node is always the same Definitions entry) so a genuinely recursive
+// schema - a tree or AST referencing itself - terminates instead of
+// recursing forever.
+func buildExampleJSONRec(schema *JSONSchema, root *JSONSchema, seen map[*JSONSchema]bool) string {
+	if schema.Ref != "" {
+		resolved := resolveRef(schema.Ref, root)
+		if resolved == nil {
+			return ""
+		}
+		schema = resolved
+	}
+	if seen[schema] {
+		return ""
+	}
+	seen[schema] = true
+
 	if schema.Type != "object" || schema.Properties == nil {
 		return ""
 	}
@@ -731,35 +1119,31 @@ func buildExampleJSON(schema *JSONSchema) string {
 			continue
 		}
 
-		switch fieldSchema.Type {
+		resolvedField := fieldSchema
+		if resolvedField.Ref != "" {
+			if r := resolveRef(resolvedField.Ref, root); r != nil {
+				resolvedField = r
+			}
+		}
+
+		switch resolvedField.Type {
 		case "string":
-			if len(fieldSchema.Enum) > 0 {
-				example[fieldName] = fieldSchema.Enum[0]
+			if len(resolvedField.Enum) > 0 {
+				example[fieldName] = resolvedField.Enum[0]
 			} else {
 				example[fieldName] = "example value"
 			}
 		case "number":
-			// Use sensible defaults for common field names
-			if strings.Contains(strings.ToLower(fieldName), "score") || strings.Contains(strings.ToLower(fieldName), "sentiment") {
-				example[fieldName] = 3
-			} else if strings.Contains(strings.ToLower(fieldName), "confidence") {
-				example[fieldName] = 0.8
-			} else {
-				example[fieldName] = 0
-			}
+			example[fieldName] = 0
 		case "integer":
-			if strings.Contains(strings.ToLower(fieldName), "score") || strings.Contains(strings.ToLower(fieldName), "sentiment") {
-				example[fieldName] = 3
-			} else {
-				example[fieldName] = 0
-			}
+			example[fieldName] = 0
 		case "boolean":
 			example[fieldName] = true
 		case "array":
 			example[fieldName] = []interface{}{}
 		case "object":
 			// Recursively build nested object
-			nestedExample := buildExampleJSON(fieldSchema)
+			nestedExample := buildExampleJSONRec(fieldSchema, root, seen)
 			if nestedExample != "" {
 				var nested map[string]interface{}
 				if err := json.Unmarshal([]byte(nestedExample), &nested); err == nil {
@@ -784,7 +1168,11 @@ func buildExampleJSON(schema *JSONSchema) string {
 	return string(jsonBytes)
 }
 
-// buildValidationFeedback creates detailed feedback for LLM retry attempts
+// buildValidationFeedback creates detailed feedback for LLM retry attempts.
+// When validationErr is a *ValidationResult (the case for every validation
+// failure produced by this package), it lists each violation with its
+// JSON-pointer path so the model gets precise per-field feedback instead of
+// a single combined message.
 func buildValidationFeedback(validationErr error, schema *JSONSchema, previousResponse string) string {
 	errMsg := validationErr.Error()
 
@@ -792,46 +1180,37 @@ func buildValidationFeedback(validationErr error, schema *JSONSchema, previousRe
 	feedback.WriteString("VALIDATION ERROR - Your previous response was invalid.\n\n")
 	feedback.WriteString(fmt.Sprintf("ERROR: %s\n\n", errMsg))
 
-	// Extract what field caused the issue
-	if strings.Contains(errMsg, "missing required field:") {
-		// Parse out the field name
-		fieldName := strings.TrimPrefix(errMsg, "missing required field: ")
-		fieldName = strings.TrimSpace(fieldName)
-
-		feedback.WriteString("SPECIFIC ISSUE:\n")
-		feedback.WriteString(fmt.Sprintf("The field '%s' is REQUIRED but was not provided.\n\n", fieldName))
-
-		// Find the schema for this field and provide details
-		if schema.Type == "object" && schema.Properties != nil {
-			if fieldSchema, exists := schema.Properties[fieldName]; exists {
-				feedback.WriteString("FIELD REQUIREMENTS:\n")
-				feedback.WriteString(fmt.Sprintf("- Field name: '%s'\n", fieldName))
-				feedback.WriteString(fmt.Sprintf("- Type: %s\n", fieldSchema.Type))
-
-				if fieldSchema.Type == "object" && len(fieldSchema.Required) > 0 {
-					feedback.WriteString(fmt.Sprintf("- This is an object with required fields: %s\n", strings.Join(fieldSchema.Required, ", ")))
-
-					if fieldSchema.Properties != nil {
-						feedback.WriteString("\nNESTED FIELD DETAILS:\n")
-						for nestedField, nestedSchema := range fieldSchema.Properties {
-							isRequired := contains(fieldSchema.Required, nestedField)
-							requiredMark := ""
-							if isRequired {
-								requiredMark = " [REQUIRED]"
-							}
-							feedback.WriteString(fmt.Sprintf("  - %s: %s%s\n", nestedField, nestedSchema.Type, requiredMark))
+	if result, ok := validationErr.(*ValidationResult); ok {
+		feedback.WriteString("SPECIFIC ISSUES:\n")
+		for _, e := range result.Errors {
+			feedback.WriteString(fmt.Sprintf("- %s (%s): %s\n", e.Path, e.Keyword, e.Message))
+			if hint := didYouMeanHint(e, schema); hint != "" {
+				feedback.WriteString(fmt.Sprintf("  Did you mean '%s'?\n", hint))
+			}
+			if e.Keyword == "required" {
+				// e.Path is already the missing field's own pointer (see
+				// walkObject), so it resolves directly - no need to append
+				// e.Expected again.
+				if fieldSchema := schemaAtPath(schema, e.Path); fieldSchema != nil {
+					if fieldSchema.Ref != "" {
+						if resolved := resolveRef(fieldSchema.Ref, schema); resolved != nil {
+							fieldSchema = resolved
 						}
 					}
-				}
-
-				if fieldSchema.Type == "array" && fieldSchema.Items != nil {
-					feedback.WriteString(fmt.Sprintf("- This is an array of: %s\n", fieldSchema.Items.Type))
+					if fieldSchema.Type == "object" && len(fieldSchema.Required) > 0 {
+						feedback.WriteString(fmt.Sprintf("  '%s' is an object with required fields: %s\n", e.Expected, strings.Join(fieldSchema.Required, ", ")))
+					} else if fieldSchema.Type == "array" && fieldSchema.Items != nil {
+						feedback.WriteString(fmt.Sprintf("  '%s' is an array of: %s\n", e.Expected, fieldSchema.Items.Type))
+					}
 				}
 			}
 		}
-	} else if strings.Contains(errMsg, "expected") {
+		feedback.WriteString("\n")
+	} else {
+		// Not a *ValidationResult - e.g. the response wasn't valid JSON at
+		// all, so there was nothing to walk against the schema.
 		feedback.WriteString("SPECIFIC ISSUE:\n")
-		feedback.WriteString("Type mismatch - you provided the wrong data type.\n\n")
+		feedback.WriteString(fmt.Sprintf("%s\n\n", errMsg))
 	}
 
 	// Show a snippet of what they provided
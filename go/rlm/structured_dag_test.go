@@ -0,0 +1,226 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"testing"
+)
+
+func TestDecomposeSchema_DependsOn(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"entities": {Type: "array", Items: &JSONSchema{Type: "string"}},
+			"relationships": {
+				Type:      "array",
+				Items:     &JSONSchema{Type: "string"},
+				DependsOn: []string{"entities"},
+			},
+		},
+		Required: []string{"entities", "relationships"},
+	}
+
+	subTasks := decomposeSchema(schema)
+
+	var relationships *SubTask
+	for i := range subTasks {
+		if subTasks[i].ID == "field_relationships" {
+			relationships = &subTasks[i]
+		}
+	}
+	if relationships == nil {
+		t.Fatal("expected a field_relationships subtask")
+	}
+	if len(relationships.Dependencies) != 1 || relationships.Dependencies[0] != "field_entities" {
+		t.Errorf("relationships.Dependencies = %v, want [field_entities]", relationships.Dependencies)
+	}
+}
+
+func TestDetectCycle_NoCycle(t *testing.T) {
+	subTasks := []SubTask{
+		{ID: "field_a", Dependencies: []string{}},
+		{ID: "field_b", Dependencies: []string{"field_a"}},
+		{ID: "field_c", Dependencies: []string{"field_a", "field_b"}},
+	}
+	if cycle := detectCycle(subTasks); cycle != nil {
+		t.Errorf("detectCycle() = %v, want nil for an acyclic graph", cycle)
+	}
+}
+
+func TestDetectCycle_DirectCycle(t *testing.T) {
+	subTasks := []SubTask{
+		{ID: "field_a", Dependencies: []string{"field_b"}},
+		{ID: "field_b", Dependencies: []string{"field_a"}},
+	}
+	cycle := detectCycle(subTasks)
+	if cycle == nil {
+		t.Fatal("detectCycle() = nil, want a cycle to be detected")
+	}
+	if cycle[0] != cycle[len(cycle)-1] {
+		t.Errorf("detectCycle() = %v, want a chain that starts and ends on the same task", cycle)
+	}
+}
+
+func TestDetectCycle_SelfDependency(t *testing.T) {
+	subTasks := []SubTask{
+		{ID: "field_a", Dependencies: []string{"field_a"}},
+	}
+	if cycle := detectCycle(subTasks); cycle == nil {
+		t.Error("detectCycle() = nil, want a self-dependency to be reported as a cycle")
+	}
+}
+
+func TestStructuredCompletionParallel_DependencyCycleErrors(t *testing.T) {
+	// structuredCompletionParallel is exercised directly (rather than via
+	// StructuredCompletion) because StructuredCompletion falls back to the
+	// direct, non-parallel path on any parallel-execution error, which would
+	// mask the cycle-specific error this test checks for.
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"a": "x"}`},
+	})
+
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"a": {Type: "string", DependsOn: []string{"b"}},
+			"b": {Type: "string", DependsOn: []string{"a"}},
+			"c": {Type: "string"},
+		},
+		Required: []string{"a", "b", "c"},
+	}
+	config := &StructuredConfig{Schema: schema, ParallelExecution: true}
+
+	_, _, err := engine.structuredCompletionParallel(stdcontext.Background(), "extract", "context", config, decomposeSchema(schema))
+	if err == nil {
+		t.Fatal("structuredCompletionParallel() expected a dependency cycle error")
+	}
+}
+
+func TestDecomposeSchemaWith_ProgrammaticDependencies(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"document_type": {Type: "string"},
+			"line_items":    {Type: "array", Items: &JSONSchema{Type: "string"}},
+		},
+		Required: []string{"document_type", "line_items"},
+	}
+	config := &StructuredConfig{
+		Schema:       schema,
+		Dependencies: map[string][]string{"line_items": {"document_type"}},
+	}
+
+	subTasks := decomposeSchemaWith(config)
+
+	var lineItems *SubTask
+	for i := range subTasks {
+		if subTasks[i].ID == "field_line_items" {
+			lineItems = &subTasks[i]
+		}
+	}
+	if lineItems == nil {
+		t.Fatal("expected a field_line_items subtask")
+	}
+	if len(lineItems.Dependencies) != 1 || lineItems.Dependencies[0] != "field_document_type" {
+		t.Errorf("line_items.Dependencies = %v, want [field_document_type]", lineItems.Dependencies)
+	}
+}
+
+func TestDecomposeSchemaWith_ProgrammaticDependenciesMergeWithSchemaDependsOn(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"a": {Type: "string"},
+			"b": {Type: "string"},
+			"c": {Type: "string", DependsOn: []string{"a"}},
+		},
+		Required: []string{"a", "b", "c"},
+	}
+	config := &StructuredConfig{
+		Schema:       schema,
+		Dependencies: map[string][]string{"c": {"b"}},
+	}
+
+	subTasks := decomposeSchemaWith(config)
+
+	var c *SubTask
+	for i := range subTasks {
+		if subTasks[i].ID == "field_c" {
+			c = &subTasks[i]
+		}
+	}
+	if c == nil {
+		t.Fatal("expected a field_c subtask")
+	}
+	want := map[string]bool{"field_a": true, "field_b": true}
+	if len(c.Dependencies) != len(want) {
+		t.Fatalf("c.Dependencies = %v, want %v", c.Dependencies, want)
+	}
+	for _, dep := range c.Dependencies {
+		if !want[dep] {
+			t.Errorf("c.Dependencies = %v, unexpected dependency %q", c.Dependencies, dep)
+		}
+	}
+}
+
+func TestStructuredCompletionParallel_AbortsRemainingTasksOnFirstError(t *testing.T) {
+	// erroringBackend errors on every call, so the first sub-task to run
+	// should cancel the shared wave context before its siblings finish.
+	engine := New("gpt-4o", Config{
+		Backend: erroringBackend{},
+	})
+
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"a": {Type: "string"},
+			"b": {Type: "string"},
+			"c": {Type: "string"},
+		},
+		Required: []string{"a", "b", "c"},
+	}
+	config := &StructuredConfig{Schema: schema, ParallelExecution: true, MaxRetries: 1}
+
+	_, _, err := engine.structuredCompletionParallel(stdcontext.Background(), "extract", "context", config, decomposeSchema(schema))
+	if err == nil {
+		t.Fatal("structuredCompletionParallel() expected an error when every sub-task fails")
+	}
+}
+
+// countingDecomposer wraps the default decomposition, recording whether it
+// was consulted, to verify StructuredConfig.Decomposer is honored.
+type countingDecomposer struct {
+	calls *int
+}
+
+func (d countingDecomposer) Decompose(schema *JSONSchema) []SubTask {
+	*d.calls++
+	return decomposeSchema(schema)
+}
+
+func TestStructuredCompletion_UsesConfiguredDecomposer(t *testing.T) {
+	calls := 0
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"a": "x", "b": "y"}`},
+	})
+
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"a": {Type: "string"},
+			"b": {Type: "string"},
+		},
+		Required: []string{"a", "b"},
+	}
+
+	_, _, err := engine.StructuredCompletion("extract", "context", &StructuredConfig{
+		Schema:            schema,
+		ParallelExecution: true,
+		Decomposer:        countingDecomposer{calls: &calls},
+	})
+	if err != nil {
+		t.Fatalf("StructuredCompletion() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("custom Decomposer.Decompose called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,106 @@
+package rlm
+
+import "testing"
+
+// constrainedBackend is a fake Backend that also implements
+// ConstrainedDecoder, so structuredGrammarExtra's type assertion succeeds
+// without needing a real GBNF-aware server.
+type constrainedBackend struct {
+	constantBackend
+	grammar    string
+	grammarErr error
+	calls      int
+}
+
+func (b *constrainedBackend) BuildGrammar(schema *JSONSchema) (string, error) {
+	b.calls++
+	return b.grammar, b.grammarErr
+}
+
+func TestStructuredGrammarExtra_PrefersConstrainedDecoder(t *testing.T) {
+	backend := &constrainedBackend{constantBackend: constantBackend{content: `{"a": "x"}`}, grammar: "root ::= string"}
+	engine := New("gpt-4o", Config{Backend: backend})
+
+	extra, constrained := engine.structuredGrammarExtra(&JSONSchema{Type: "string"}, "")
+	if !constrained {
+		t.Fatal("structuredGrammarExtra() constrained = false, want true for a working ConstrainedDecoder")
+	}
+	if extra["grammar"] != "root ::= string" {
+		t.Errorf(`structuredGrammarExtra() grammar = %v, want "root ::= string"`, extra["grammar"])
+	}
+	if backend.calls != 1 {
+		t.Errorf("BuildGrammar called %d times, want 1", backend.calls)
+	}
+}
+
+func TestStructuredGrammarExtra_FallsBackOnDecoderError(t *testing.T) {
+	backend := &constrainedBackend{
+		constantBackend: constantBackend{content: `{"a": "x"}`},
+		grammarErr:      errInvalidGrammar,
+	}
+	engine := New("gpt-4o", Config{Backend: backend})
+
+	extra, constrained := engine.structuredGrammarExtra(&JSONSchema{Type: "string"}, "")
+	if constrained {
+		t.Error("structuredGrammarExtra() constrained = true, want false when BuildGrammar errors")
+	}
+	if extra["grammar"] == "" {
+		t.Error("structuredGrammarExtra() should still fall back to the generic GBNF conversion")
+	}
+}
+
+func TestStructuredGrammarExtra_NoDecoderFallsBackToGenericConversion(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+
+	extra, constrained := engine.structuredGrammarExtra(&JSONSchema{Type: "string"}, "")
+	if constrained {
+		t.Error("structuredGrammarExtra() constrained = true, want false for a plain Backend")
+	}
+	if extra["grammar"] == "" {
+		t.Error("structuredGrammarExtra() should build a grammar via the generic conversion")
+	}
+}
+
+func TestStructuredCompletionDirect_SkipsRetriesWhenConstrained(t *testing.T) {
+	backend := &constrainedBackend{
+		constantBackend: constantBackend{content: `{"name": "Ada"}`},
+		grammar:         "root ::= object",
+	}
+	engine := New("gpt-4o", Config{Backend: backend})
+
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+
+	result, _, err := engine.structuredCompletionDirect("extract", "context", &StructuredConfig{Schema: schema, MaxRetries: 5})
+	if err != nil {
+		t.Fatalf("structuredCompletionDirect() error = %v", err)
+	}
+	if result["name"] != "Ada" {
+		t.Errorf(`structuredCompletionDirect() result = %v, want name="Ada"`, result)
+	}
+	if backend.calls != 1 {
+		t.Errorf("BuildGrammar called %d times, want 1", backend.calls)
+	}
+}
+
+func TestStructuredGrammarExtra_CustomFieldName(t *testing.T) {
+	backend := &constrainedBackend{constantBackend: constantBackend{content: `{"a": "x"}`}, grammar: "root ::= string"}
+	engine := New("gpt-4o", Config{Backend: backend})
+
+	extra, _ := engine.structuredGrammarExtra(&JSONSchema{Type: "string"}, "gbnf")
+	if extra["gbnf"] != "root ::= string" {
+		t.Errorf(`structuredGrammarExtra() gbnf = %v, want "root ::= string"`, extra["gbnf"])
+	}
+	if _, ok := extra["grammar"]; ok {
+		t.Error("structuredGrammarExtra() should not also set the default \"grammar\" key")
+	}
+}
+
+var errInvalidGrammar = &grammarBuildError{"schema has no representable grammar"}
+
+type grammarBuildError struct{ msg string }
+
+func (e *grammarBuildError) Error() string { return e.msg }
@@ -0,0 +1,58 @@
+package rlm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// StructuredCompletionInto runs r.StructuredCompletion and unmarshals the
+// validated result map directly into T, so callers building their schema
+// with SchemaFor[T] don't also have to hand-unmarshal the
+// map[string]interface{} StructuredCompletion returns. T's JSON encoding
+// must match config.Schema.
+//
+// Generic functions can't be declared as methods in Go, so this takes r as
+// an ordinary parameter rather than hanging off *RLM like StructuredCompletion.
+func StructuredCompletionInto[T any](r *RLM, query string, context string, config *StructuredConfig) (T, RLMStats, error) {
+	var result T
+
+	raw, stats, err := r.StructuredCompletion(query, context, config)
+	if err != nil {
+		return result, stats, err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return result, stats, fmt.Errorf("failed to marshal structured result: %w", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, stats, fmt.Errorf("failed to unmarshal structured result into %T: %w", result, err)
+	}
+
+	return result, stats, nil
+}
+
+// StructuredCompletionTyped is StructuredCompletionInto's schema-inferring
+// sibling: instead of requiring a *StructuredConfig with a hand-built or
+// SchemaFor[T]-generated Schema, it derives one from T itself via
+// InferSchemaFromType, so callers with a plain result struct don't have to
+// build a config at all. Use StructuredCompletionInto directly when the
+// schema needs options InferSchemaFromType doesn't infer (ParallelExecution,
+// MaxRetries, a custom ValidatorBackend, ...).
+func StructuredCompletionTyped[T any](r *RLM, query string, context string) (T, RLMStats, error) {
+	var zero T
+
+	schema, err := InferSchemaFromType(reflect.TypeOf(zero))
+	if err != nil {
+		return zero, RLMStats{}, fmt.Errorf("failed to infer schema for %T: %w", zero, err)
+	}
+
+	return StructuredCompletionInto[T](r, query, context, &StructuredConfig{Schema: schema})
+}
+
+// StructuredCompletionAs is StructuredCompletionTyped under the name callers
+// coming from GenerateSchema/MustGenerateSchema are more likely to look for.
+func StructuredCompletionAs[T any](r *RLM, query string, context string) (T, RLMStats, error) {
+	return StructuredCompletionTyped[T](r, query, context)
+}
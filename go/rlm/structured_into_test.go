@@ -0,0 +1,147 @@
+package rlm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// constantBackend always returns the same content, regardless of request -
+// enough to drive structuredCompletionDirect's single-call path.
+type constantBackend struct {
+	content string
+}
+
+func (b constantBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	return ChatResponse{Content: b.content}, nil
+}
+
+// erroringBackend always fails, for exercising error-propagation paths that
+// don't care what the failure was.
+type erroringBackend struct{}
+
+func (erroringBackend) Chat(ctx context.Context, request ChatRequest) (ChatResponse, error) {
+	return ChatResponse{}, fmt.Errorf("erroringBackend: simulated failure")
+}
+
+func TestStructuredCompletionInto_UnmarshalsIntoStruct(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"name": "Alice", "age": 30}`},
+	})
+
+	result, _, err := StructuredCompletionInto[Person](engine, "extract person", "Alice is 30", &StructuredConfig{
+		Schema: SchemaFor[Person](),
+	})
+	if err != nil {
+		t.Fatalf("StructuredCompletionInto() error = %v", err)
+	}
+	if result.Name != "Alice" || result.Age != 30 {
+		t.Errorf("StructuredCompletionInto() = %+v, want {Alice 30}", result)
+	}
+}
+
+func TestStructuredCompletionInto_PropagatesValidationError(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	engine := New("gpt-4o", Config{
+		// Missing the required "age" field on every attempt.
+		Backend: constantBackend{content: `{"name": "Alice"}`},
+	})
+
+	_, _, err := StructuredCompletionInto[Person](engine, "extract person", "Alice", &StructuredConfig{
+		Schema:     SchemaFor[Person](),
+		MaxRetries: 1,
+	})
+	if err == nil {
+		t.Fatal("StructuredCompletionInto() expected an error for a response missing a required field")
+	}
+}
+
+func TestStructuredCompletionTyped_InfersSchemaAndUnmarshals(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"name": "Alice", "age": 30}`},
+	})
+
+	result, _, err := StructuredCompletionTyped[Person](engine, "extract person", "Alice is 30")
+	if err != nil {
+		t.Fatalf("StructuredCompletionTyped() error = %v", err)
+	}
+	if result.Name != "Alice" || result.Age != 30 {
+		t.Errorf("StructuredCompletionTyped() = %+v, want {Alice 30}", result)
+	}
+}
+
+func TestStructuredCompletionJSON_ReturnsRawValidatedBytes(t *testing.T) {
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"name": "Alice", "age": 30}`},
+	})
+
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "integer"}},
+		Required:   []string{"name", "age"},
+	}
+
+	raw, _, err := engine.StructuredCompletionJSON("extract person", "Alice is 30", schema)
+	if err != nil {
+		t.Fatalf("StructuredCompletionJSON() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("StructuredCompletionJSON() returned invalid JSON: %v", err)
+	}
+	if decoded["name"] != "Alice" {
+		t.Errorf("decoded[\"name\"] = %v, want Alice", decoded["name"])
+	}
+}
+
+func TestStructuredCompletionJSON_PropagatesValidationError(t *testing.T) {
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"name": "Alice"}`},
+	})
+
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "integer"}},
+		Required:   []string{"name", "age"},
+	}
+
+	_, _, err := engine.StructuredCompletionJSON("extract person", "Alice", schema)
+	if err == nil {
+		t.Fatal("StructuredCompletionJSON() expected an error for a response missing a required field")
+	}
+}
+
+func TestStructuredCompletionAs_InfersSchemaAndUnmarshals(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"name": "Alice", "age": 30}`},
+	})
+
+	result, _, err := StructuredCompletionAs[Person](engine, "extract person", "Alice is 30")
+	if err != nil {
+		t.Fatalf("StructuredCompletionAs() error = %v", err)
+	}
+	if result.Name != "Alice" || result.Age != 30 {
+		t.Errorf("StructuredCompletionAs() = %+v, want {Alice 30}", result)
+	}
+}
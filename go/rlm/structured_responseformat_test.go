@@ -0,0 +1,243 @@
+package rlm
+
+import "testing"
+
+func TestStructuredResponseFormatExtra_Disabled(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+
+	extra, mode, guaranteed := engine.structuredResponseFormatExtra(&StructuredConfig{Schema: &JSONSchema{Type: "string"}})
+	if extra != nil || mode != "" || guaranteed {
+		t.Errorf("structuredResponseFormatExtra() = %v, %q, %v, want nil, \"\", false when UseResponseFormat is unset", extra, mode, guaranteed)
+	}
+}
+
+func TestStructuredResponseFormatExtra_OpenAIDefault(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{"a": {Type: "string"}}}
+
+	extra, mode, guaranteed := engine.structuredResponseFormatExtra(&StructuredConfig{Schema: schema, UseResponseFormat: true, Strict: true})
+	if mode != ProviderModeOpenAI {
+		t.Errorf("structuredResponseFormatExtra() mode = %q, want %q", mode, ProviderModeOpenAI)
+	}
+	if !guaranteed {
+		t.Error("structuredResponseFormatExtra() guaranteed = false, want true for Strict OpenAI mode")
+	}
+	rf, ok := extra["response_format"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response_format = %v, want a map", extra["response_format"])
+	}
+	if rf["type"] != "json_schema" {
+		t.Errorf(`response_format["type"] = %v, want "json_schema"`, rf["type"])
+	}
+	js, ok := rf["json_schema"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("json_schema = %v, want a map", rf["json_schema"])
+	}
+	if js["name"] != "response" {
+		t.Errorf(`json_schema["name"] = %v, want default "response"`, js["name"])
+	}
+	if js["strict"] != true {
+		t.Errorf(`json_schema["strict"] = %v, want true`, js["strict"])
+	}
+}
+
+func TestStructuredResponseFormatExtra_Gemini(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+	schema := &JSONSchema{Type: "object"}
+
+	extra, mode, guaranteed := engine.structuredResponseFormatExtra(&StructuredConfig{Schema: schema, UseResponseFormat: true, ProviderMode: ProviderModeGemini})
+	if mode != ProviderModeGemini || !guaranteed {
+		t.Errorf("structuredResponseFormatExtra() = mode %q guaranteed %v, want %q true", mode, guaranteed, ProviderModeGemini)
+	}
+	gc, ok := extra["generationConfig"].(map[string]interface{})
+	if !ok || gc["responseMimeType"] != "application/json" {
+		t.Errorf("generationConfig = %v, want responseMimeType application/json", extra["generationConfig"])
+	}
+}
+
+func TestStructuredResponseFormatExtra_AnthropicToolUse(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+	schema := &JSONSchema{Type: "object"}
+
+	extra, mode, guaranteed := engine.structuredResponseFormatExtra(&StructuredConfig{
+		Schema:            schema,
+		UseResponseFormat: true,
+		ProviderMode:      ProviderModeAnthropicToolUse,
+		SchemaName:        "extract_person",
+	})
+	if mode != ProviderModeAnthropicToolUse || !guaranteed {
+		t.Errorf("structuredResponseFormatExtra() = mode %q guaranteed %v, want %q true", mode, guaranteed, ProviderModeAnthropicToolUse)
+	}
+	tools, ok := extra["tools"].([]map[string]interface{})
+	if !ok || len(tools) != 1 || tools[0]["name"] != "extract_person" {
+		t.Errorf("tools = %v, want one tool named extract_person", extra["tools"])
+	}
+	choice, ok := extra["tool_choice"].(map[string]interface{})
+	if !ok || choice["name"] != "extract_person" {
+		t.Errorf("tool_choice = %v, want forcing extract_person", extra["tool_choice"])
+	}
+}
+
+func TestStructuredResponseFormatExtra_LlamaCppGrammarDelegates(t *testing.T) {
+	backend := &constrainedBackend{constantBackend: constantBackend{content: `{"a": "x"}`}, grammar: "root ::= string"}
+	engine := New("gpt-4o", Config{Backend: backend})
+
+	extra, mode, guaranteed := engine.structuredResponseFormatExtra(&StructuredConfig{
+		Schema:            &JSONSchema{Type: "string"},
+		UseResponseFormat: true,
+		ProviderMode:      ProviderModeLlamaCppGrammar,
+	})
+	if mode != ProviderModeLlamaCppGrammar || !guaranteed {
+		t.Errorf("structuredResponseFormatExtra() = mode %q guaranteed %v, want %q true", mode, guaranteed, ProviderModeLlamaCppGrammar)
+	}
+	if extra["grammar"] != "root ::= string" {
+		t.Errorf(`extra["grammar"] = %v, want "root ::= string"`, extra["grammar"])
+	}
+}
+
+// capabilityBackend is a constantBackend that also advertises a fixed
+// StructuredCapability, for exercising ModeAuto's LLMProvider probing.
+type capabilityBackend struct {
+	constantBackend
+	capability StructuredCapability
+	probes     *int
+}
+
+func (b capabilityBackend) SupportsStructuredOutput() StructuredCapability {
+	if b.probes != nil {
+		*b.probes++
+	}
+	return b.capability
+}
+
+func TestResolveStructuredOutput_ModePromptDisablesNativeRouting(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{"a": {Type: "string"}}}
+
+	extra, mode, guaranteed := engine.resolveStructuredOutput(&StructuredConfig{
+		Schema:            schema,
+		Mode:              ModePrompt,
+		UseResponseFormat: true, // ModePrompt must override this
+	})
+	if extra != nil || mode != ProviderModeNone || guaranteed {
+		t.Errorf("resolveStructuredOutput() = %v, %q, %v, want nil, %q, false for ModePrompt", extra, mode, guaranteed, ProviderModeNone)
+	}
+}
+
+func TestResolveStructuredOutput_ModeNativeJSON(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+
+	extra, mode, guaranteed := engine.resolveStructuredOutput(&StructuredConfig{
+		Schema: &JSONSchema{Type: "object"},
+		Mode:   ModeNativeJSON,
+	})
+	if mode != ProviderModeOpenAI || guaranteed {
+		t.Errorf("resolveStructuredOutput() = mode %q guaranteed %v, want %q false (valid JSON isn't schema-guaranteed)", mode, guaranteed, ProviderModeOpenAI)
+	}
+	rf, ok := extra["response_format"].(map[string]interface{})
+	if !ok || rf["type"] != "json_object" {
+		t.Errorf(`response_format = %v, want {"type": "json_object"}`, extra["response_format"])
+	}
+}
+
+func TestResolveStructuredOutput_ModeNativeSchema(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{"a": {Type: "string"}}}
+
+	extra, mode, guaranteed := engine.resolveStructuredOutput(&StructuredConfig{
+		Schema: schema,
+		Mode:   ModeNativeSchema,
+	})
+	if mode != ProviderModeOpenAI || !guaranteed {
+		t.Errorf("resolveStructuredOutput() = mode %q guaranteed %v, want %q true", mode, guaranteed, ProviderModeOpenAI)
+	}
+	if _, ok := extra["response_format"]; !ok {
+		t.Errorf("expected a response_format key, got %v", extra)
+	}
+}
+
+func TestResolveStructuredOutput_ModeAutoPicksSchemaCapability(t *testing.T) {
+	probes := 0
+	engine := New("gpt-4o", Config{
+		Backend: capabilityBackend{
+			constantBackend: constantBackend{content: `{"a": "x"}`},
+			capability:      StructuredCapability{Schema: true, JSON: true},
+			probes:          &probes,
+		},
+	})
+	schema := &JSONSchema{Type: "object"}
+
+	_, mode, guaranteed := engine.resolveStructuredOutput(&StructuredConfig{Schema: schema, Mode: ModeAuto})
+	if mode != ProviderModeOpenAI || !guaranteed {
+		t.Errorf("resolveStructuredOutput() = mode %q guaranteed %v, want %q true when the backend supports Schema", mode, guaranteed, ProviderModeOpenAI)
+	}
+
+	// A second ModeAuto call against the same backend should hit the
+	// cache rather than probing SupportsStructuredOutput again.
+	engine.resolveStructuredOutput(&StructuredConfig{Schema: schema, Mode: ModeAuto})
+	if probes != 1 {
+		t.Errorf("SupportsStructuredOutput called %d times, want 1 (subsequent calls should be cached)", probes)
+	}
+}
+
+func TestResolveStructuredOutput_ModeAutoUsesCapabilityProviderMode(t *testing.T) {
+	engine := New("claude-3-opus", Config{
+		Backend: capabilityBackend{
+			constantBackend: constantBackend{content: `{"a": "x"}`},
+			capability:      StructuredCapability{Schema: true, ProviderMode: ProviderModeAnthropicToolUse},
+		},
+	})
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{"a": {Type: "string"}}}
+
+	extra, mode, guaranteed := engine.resolveStructuredOutput(&StructuredConfig{Schema: schema, Mode: ModeAuto})
+	if mode != ProviderModeAnthropicToolUse || !guaranteed {
+		t.Errorf("resolveStructuredOutput() = mode %q guaranteed %v, want %q true (capability's own ProviderMode, not the OpenAI default)", mode, guaranteed, ProviderModeAnthropicToolUse)
+	}
+	if _, ok := extra["tools"]; !ok {
+		t.Errorf("extra = %v, want an Anthropic tool-use payload", extra)
+	}
+}
+
+func TestResolveStructuredOutput_ModeAutoFallsBackToPromptWithoutCapability(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"a": "x"}`}})
+
+	extra, mode, guaranteed := engine.resolveStructuredOutput(&StructuredConfig{
+		Schema: &JSONSchema{Type: "object"},
+		Mode:   ModeAuto,
+	})
+	if extra != nil || mode != ProviderModeNone || guaranteed {
+		t.Errorf("resolveStructuredOutput() = %v, %q, %v, want nil, %q, false when the backend advertises no capability", extra, mode, guaranteed, ProviderModeNone)
+	}
+}
+
+func TestStructuredCompletionDirect_SkipsRetriesWithResponseFormat(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{"name": "Ada"}`}})
+
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+
+	result, stats, err := engine.structuredCompletionDirect("extract", "context", &StructuredConfig{
+		Schema:            schema,
+		MaxRetries:        5,
+		UseResponseFormat: true,
+		Strict:            true,
+	})
+	if err != nil {
+		t.Fatalf("structuredCompletionDirect() error = %v", err)
+	}
+	if result["name"] != "Ada" {
+		t.Errorf(`structuredCompletionDirect() result = %v, want name="Ada"`, result)
+	}
+	if stats.ResponseFormatMode != string(ProviderModeOpenAI) {
+		t.Errorf("stats.ResponseFormatMode = %q, want %q", stats.ResponseFormatMode, ProviderModeOpenAI)
+	}
+	if stats.LlmCalls != 1 {
+		t.Errorf("LlmCalls = %d, want 1 (no retries when the provider guarantees conformant JSON)", stats.LlmCalls)
+	}
+	if !stats.NativeStructured {
+		t.Error("stats.NativeStructured = false, want true when the provider guarantees conformant JSON")
+	}
+}
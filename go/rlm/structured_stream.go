@@ -0,0 +1,455 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PartialResult is one update delivered by StructuredCompletionPartialStream:
+// either a single field becoming complete (Path/Value set, Done false) or
+// the terminal update once streaming finishes (Done true, with Result and
+// Stats set, and Err set if the overall completion failed). A channel
+// consumer should render each non-Done update as it arrives and use the
+// Done update as the signal to stop reading.
+type PartialResult struct {
+	Path  string
+	Value interface{}
+
+	Done   bool
+	Result map[string]interface{}
+	Stats  RLMStats
+	Err    error
+}
+
+// StructuredCompletionPartialStream is StructuredCompletionStream's
+// channel-based sibling, for callers that would rather range over a channel
+// than supply a callback. It runs StructuredCompletionStream in a goroutine,
+// forwarding each onValue call as a PartialResult and closing the channel
+// after a final Done result carrying the completion's overall outcome.
+func (r *RLM) StructuredCompletionPartialStream(query string, context string, config *StructuredConfig) (<-chan PartialResult, error) {
+	if config == nil || config.Schema == nil {
+		return nil, fmt.Errorf("structured config and schema are required")
+	}
+
+	out := make(chan PartialResult)
+	go func() {
+		defer close(out)
+		result, stats, err := r.StructuredCompletionStream(query, context, config, func(path string, value interface{}) {
+			out <- PartialResult{Path: path, Value: value}
+		})
+		out <- PartialResult{Done: true, Result: result, Stats: stats, Err: err}
+	}()
+	return out, nil
+}
+
+// StructuredCompletionStream is StructuredCompletion's progressive sibling:
+// it issues the same prompts and retry logic, but as the LLM's JSON output
+// arrives it calls onValue for each top-level (or nested) field as soon as
+// that field's value is unambiguously complete, rather than only returning
+// once the whole response has been parsed. The final return value is still
+// the result validated against the same schema - onValue is purely a
+// progress channel for callers that want to render results as they resolve
+// or short-circuit once they have what they need. Unlike StructuredCompletion,
+// the streaming path applies only a ConstrainedDecoder grammar: it does not
+// route through UseResponseFormat/Mode native structured output and does not
+// run EnforcementActions.
+func (r *RLM) StructuredCompletionStream(query string, context string, config *StructuredConfig, onValue func(path string, value interface{})) (map[string]interface{}, RLMStats, error) {
+	// This entry point predates context plumbing and has no caller-supplied
+	// ctx to parent on; its span is still a root-less child of whatever the
+	// tracer's ambient context is.
+	ctx, span := r.observer.StartSpan(stdcontext.Background(), "rlm.structured_completion_stream", map[string]string{
+		"query_length":   fmt.Sprintf("%d", len(query)),
+		"context_length": fmt.Sprintf("%d", len(context)),
+	})
+	defer span.End()
+
+	if config == nil || config.Schema == nil {
+		return nil, RLMStats{}, fmt.Errorf("structured config and schema are required")
+	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+
+	if r.metaAgent != nil {
+		optimized, err := r.metaAgent.OptimizeForStructured(ctx, query, context, config.Schema)
+		if err == nil && optimized != "" {
+			r.observer.Debug("structured", "Using meta-agent optimized query for streamed structured extraction")
+			query = optimized
+		}
+	}
+
+	subTasks := decomposeSchemaWith(config)
+	r.observer.Debug("structured", "Schema decomposed into %d subtasks for streaming", len(subTasks))
+
+	if len(subTasks) <= 2 || !config.ParallelExecution {
+		return r.structuredCompletionDirectStream(query, context, config, onValue)
+	}
+
+	result, stats, err := r.structuredCompletionParallelStream(query, context, config, subTasks, onValue)
+	if err != nil {
+		r.observer.Debug("structured", "Parallel streaming execution failed (%v), falling back to direct method", err)
+		return r.structuredCompletionDirectStream(query, context, config, onValue)
+	}
+	return result, stats, nil
+}
+
+// structuredCompletionDirectStream mirrors structuredCompletionDirect's
+// prompt and retry loop (without its UseResponseFormat/Mode routing and
+// EnforcementActions; only structuredGrammarExtra's grammar is applied),
+// but drives the LLM call through
+// streamRawLLM so a partialJSONParser can fire onValue as each field
+// completes. The authoritative result still comes from parseAndValidateJSON
+// run on the full accumulated text, so retry/validation-feedback behavior is
+// unchanged; onValue is fired once more per top-level field of that final
+// result, in case the model's output couldn't be parsed incrementally (for
+// example if it was wrapped in a markdown fence).
+func (r *RLM) structuredCompletionDirectStream(query string, context string, config *StructuredConfig, onValue func(path string, value interface{})) (map[string]interface{}, RLMStats, error) {
+	return r.structuredCompletionDirectStreamContext(stdcontext.Background(), query, context, config, onValue)
+}
+
+// structuredCompletionDirectStreamContext is structuredCompletionDirectStream
+// with a caller-supplied ctx threaded into streamRawLLM, so a caller that
+// cancels ctx mid-stream (e.g. structuredCompletionParallelStream's
+// fieldStabilizationTracker, once a field's value has stopped changing)
+// gets back the best-effort partial parse accumulated so far instead of an
+// error - cancellation here is an optimization, not a failure.
+func (r *RLM) structuredCompletionDirectStreamContext(ctx stdcontext.Context, query string, context string, config *StructuredConfig, onValue func(path string, value interface{})) (map[string]interface{}, RLMStats, error) {
+	prompt := buildStructuredPrompt(query, context, config.Schema)
+
+	var lastErr error
+	stats := RLMStats{Depth: r.currentDepth}
+
+	messages := []Message{
+		{Role: "system", Content: "You are a data extraction assistant. Respond only with valid JSON objects."},
+		{Role: "user", Content: prompt},
+	}
+
+	llmExtra, constrained := r.structuredGrammarExtra(config.Schema, config.GrammarField)
+	maxRetries := config.MaxRetries
+	if constrained {
+		maxRetries = 1
+	}
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		parser := newPartialJSONParser(onValue)
+		result, err := r.streamRawLLM(ctx, messages, llmExtra, parser.Feed, attempt)
+		stats.LlmCalls++
+		stats.Iterations++
+
+		if err != nil {
+			if ctx.Err() != nil {
+				if snapshot := parser.Snapshot(); snapshot != nil {
+					stats.ParsingRetries = attempt
+					return snapshot, stats, nil
+				}
+			}
+			lastErr = err
+			continue
+		}
+
+		parsed, err := parseAndValidateJSON(result, config.Schema, validatorFor(config))
+		if err != nil {
+			lastErr = err
+			if attempt < maxRetries-1 {
+				validationFeedback := buildValidationFeedback(err, config.Schema, result)
+				messages = append(messages,
+					Message{Role: "assistant", Content: result},
+					Message{Role: "user", Content: validationFeedback},
+				)
+			}
+			continue
+		}
+
+		for field, value := range parsed {
+			parser.report(childPath("", field), value)
+		}
+
+		stats.ParsingRetries = attempt
+		return parsed, stats, nil
+	}
+
+	return nil, stats, fmt.Errorf("failed to get valid structured output after %d attempts: %v", maxRetries, lastErr)
+}
+
+// structuredCompletionParallelStream follows structuredCompletionParallel's
+// wave scheduler, with one addition: as soon as a task's field value is
+// resolved, onValue is called with it. Unlike structuredCompletionParallel,
+// a failing task does not cancel the other tasks of its wave. Streaming here
+// happens at field granularity rather than token granularity - each task
+// resolves through a single buffered structuredCompletionDirect call, or,
+// when StabilizationWindow > 0, through structuredCompletionDirectStreamContext
+// with a fieldStabilizationTracker - since decomposeSchema already produces
+// one subtask per field and that's the natural unit callers care about in
+// the parallel path.
+func (r *RLM) structuredCompletionParallelStream(query string, context string, config *StructuredConfig, subTasks []SubTask, onValue func(path string, value interface{})) (map[string]interface{}, RLMStats, error) {
+	results := make(map[string]interface{})
+	resultsByTaskID := make(map[string]interface{})
+	var resultsMutex sync.Mutex
+
+	totalStats := RLMStats{}
+	var statsMutex sync.Mutex
+
+	byID := make(map[string]SubTask, len(subTasks))
+	for _, t := range subTasks {
+		byID[t.ID] = t
+	}
+
+	if cycle := detectCycle(subTasks); cycle != nil {
+		return nil, totalStats, fmt.Errorf("structured schema decomposition has a dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	done := make(map[string]bool, len(subTasks))
+	var taskErrors []string
+
+	for len(done) < len(subTasks) && len(taskErrors) == 0 {
+		var wave []SubTask
+		for _, t := range subTasks {
+			if done[t.ID] {
+				continue
+			}
+			if dependenciesSatisfied(t.Dependencies, done) {
+				wave = append(wave, t)
+			}
+		}
+
+		if len(wave) == 0 {
+			return nil, totalStats, fmt.Errorf("structured decomposition has an unsatisfiable or circular dependency among: %v", pendingTaskIDs(subTasks, done))
+		}
+
+		var wg sync.WaitGroup
+		waveErrors := make([]error, len(wave))
+
+		concurrency := config.MaxConcurrency
+		if concurrency <= 0 || concurrency > len(wave) {
+			concurrency = len(wave)
+		}
+		sem := make(chan struct{}, concurrency)
+
+		for i, task := range wave {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(idx int, t SubTask) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				fieldName := strings.TrimPrefix(t.ID, "field_")
+				wrappedSchema := wrapFieldSchema(fieldName, t.Schema)
+
+				taskQuery := fmt.Sprintf("%s\n\nSpecific focus: %s", query, t.Query)
+				if len(t.Dependencies) > 0 {
+					taskQuery += "\n\n" + dependencyHint(t.Dependencies, resultsByTaskID, &resultsMutex)
+				}
+
+				taskConfig := &StructuredConfig{
+					Schema:            wrappedSchema,
+					ParallelExecution: false,
+					MaxRetries:        config.MaxRetries,
+				}
+
+				start := time.Now()
+				var result map[string]interface{}
+				var stats RLMStats
+				var err error
+				if config.StabilizationWindow > 0 {
+					taskCtx, cancelTask := stdcontext.WithCancel(stdcontext.Background())
+					tracker := newFieldStabilizationTracker(config.StabilizationWindow, cancelTask)
+					result, stats, err = r.structuredCompletionDirectStreamContext(taskCtx, taskQuery, context, taskConfig, tracker.onValue)
+					tracker.stop()
+					cancelTask()
+				} else {
+					result, stats, err = r.structuredCompletionDirect(taskQuery, context, taskConfig)
+				}
+				duration := time.Since(start)
+				if err != nil {
+					waveErrors[idx] = fmt.Errorf("task %s failed: %w", t.ID, err)
+					return
+				}
+
+				var fieldValue interface{}
+				if val, ok := result[fieldName]; ok {
+					fieldValue = val
+				} else if val, ok := result["__value__"]; ok {
+					fieldValue = val
+				} else if len(result) == 1 {
+					for _, v := range result {
+						fieldValue = v
+					}
+				} else {
+					fieldValue = result
+				}
+
+				resultsMutex.Lock()
+				results[fieldName] = fieldValue
+				resultsByTaskID[t.ID] = fieldValue
+				resultsMutex.Unlock()
+
+				onValue(pointerOrRoot(childPath("", fieldName)), fieldValue)
+
+				statsMutex.Lock()
+				totalStats.LlmCalls += stats.LlmCalls
+				totalStats.Iterations += stats.Iterations
+				if stats.Depth > totalStats.Depth {
+					totalStats.Depth = stats.Depth
+				}
+				totalStats.ParsingRetries += stats.ParsingRetries
+				totalStats.NodeStats = append(totalStats.NodeStats, NodeStat{
+					TaskID:     t.ID,
+					DurationMs: duration.Milliseconds(),
+					LlmCalls:   stats.LlmCalls,
+				})
+				statsMutex.Unlock()
+			}(i, task)
+		}
+
+		wg.Wait()
+
+		for i, t := range wave {
+			done[t.ID] = true
+			if waveErrors[i] != nil {
+				taskErrors = append(taskErrors, waveErrors[i].Error())
+			}
+		}
+	}
+
+	if len(taskErrors) > 0 {
+		return nil, totalStats, fmt.Errorf("parallel execution failed (%d/%d tasks): %s",
+			len(taskErrors), len(subTasks), strings.Join(taskErrors, "; "))
+	}
+
+	if err := validatorFor(config).Validate(results, config.Schema); err != nil {
+		return nil, totalStats, fmt.Errorf("merged result validation failed: %w", err)
+	}
+
+	return results, totalStats, nil
+}
+
+// buildStructuredPrompt builds the extraction prompt shared by
+// structuredCompletionDirect and structuredCompletionDirectStream.
+func buildStructuredPrompt(query string, context string, schema *JSONSchema) string {
+	schemaJSON, _ := json.Marshal(schema)
+
+	constraints := generateSchemaConstraints(schema)
+	requiredFieldsHint := ""
+	if schema.Type == "object" && len(schema.Required) > 0 {
+		requiredFieldsHint = fmt.Sprintf("\nREQUIRED FIELDS (you MUST include these): %s\n", strings.Join(schema.Required, ", "))
+	}
+
+	return fmt.Sprintf(
+		"You are a data extraction assistant. Extract information from the context and return it as JSON.\n\n"+
+			"Context:\n%s\n\n"+
+			"Task: %s\n\n"+
+			"Required JSON Schema:\n%s%s\n\n"+
+			"%s"+
+			"CRITICAL INSTRUCTIONS:\n"+
+			"1. Return ONLY valid JSON - no explanations, no markdown, no code blocks\n"+
+			"2. The JSON must match the schema EXACTLY\n"+
+			"3. Include ALL required fields (see list above)\n"+
+			"4. Use correct data types (strings in quotes, numbers without quotes, arrays in [], objects in {})\n"+
+			"5. For arrays, return actual JSON arrays [] not objects\n"+
+			"6. For enum fields, use ONLY the EXACT values listed - do not paraphrase or substitute\n"+
+			"7. For nested objects, ensure ALL required fields within those objects are included\n"+
+			"8. Start your response directly with { or [ depending on the schema\n\n"+
+			"JSON Response:",
+		context, query, schemaJSON, requiredFieldsHint, constraints,
+	)
+}
+
+// streamRawLLM issues one LLM call, forwarding every raw delta to onDelta as
+// it arrives when the backend implements StreamingBackend, and falling back
+// to a single buffered call (delivered as one delta) otherwise - the same
+// type-assertion pattern streamLLM uses, but without FINAL()-literal
+// detection, since a structured completion's entire response is the payload
+// rather than the tail end of a REPL transcript.
+func (r *RLM) streamRawLLM(ctx stdcontext.Context, messages []Message, extra map[string]interface{}, onDelta func(string), iteration int) (string, error) {
+	streamer, ok := r.backend.(StreamingBackend)
+	if !ok {
+		response, err := r.callLLM(ctx, messages, extra, iteration)
+		if err != nil {
+			return "", err
+		}
+		onDelta(response)
+		return response, nil
+	}
+
+	r.stats.LlmCalls++
+	model := r.model
+	if r.currentDepth > 0 {
+		model = r.recursiveModel
+	}
+	r.observer.Debug("llm", "Streaming %s with %d messages", model, len(messages))
+
+	params := r.extraParams
+	if len(extra) > 0 {
+		params = make(map[string]interface{}, len(r.extraParams)+len(extra))
+		for k, v := range r.extraParams {
+			params[k] = v
+		}
+		for k, v := range extra {
+			params[k] = v
+		}
+	}
+
+	request := ChatRequest{
+		Model:       model,
+		Messages:    messages,
+		APIBase:     r.apiBase,
+		APIKey:      r.apiKey,
+		Timeout:     r.timeoutSeconds,
+		ExtraParams: params,
+	}
+
+	start := time.Now()
+
+	info := LLMCallInfo{
+		Model:        model,
+		MessageCount: len(messages),
+		Temperature:  extractTemperature(params),
+	}
+	if r.observer.config.CaptureContent {
+		info.Prompt = serializeMessages(messages)
+	}
+
+	deltas, err := streamer.ChatStream(ctx, request)
+	if err != nil {
+		r.observer.LLMCall(info, time.Since(start), err)
+		return "", err
+	}
+
+	var full strings.Builder
+	var usage TokenUsage
+	for sc := range deltas {
+		if sc.Err != nil {
+			r.observer.LLMCall(info, time.Since(start), sc.Err)
+			return "", sc.Err
+		}
+		if sc.Usage != nil {
+			usage = *sc.Usage
+		}
+		if sc.Delta == "" {
+			continue
+		}
+		full.WriteString(sc.Delta)
+		onDelta(sc.Delta)
+	}
+
+	duration := time.Since(start)
+	response := full.String()
+	if usage.TotalTokens == 0 {
+		usage = estimateUsage(messages, response)
+	}
+	r.stats.PromptTokens += usage.PromptTokens
+	r.stats.CompletionTokens += usage.CompletionTokens
+	r.stats.TotalTokens += usage.TotalTokens
+	r.metrics.OnLLMCall(r.currentDepth, model, usage.PromptTokens, usage.CompletionTokens, duration)
+	info.PromptTokens = usage.PromptTokens
+	info.CompletionTokens = usage.CompletionTokens
+	info.Completion = response
+	r.observer.LLMCall(info, duration, nil)
+
+	return response, nil
+}
@@ -0,0 +1,48 @@
+package rlm
+
+import "testing"
+
+func TestStructuredCompletionPartialStream_DeliversFieldsThenDone(t *testing.T) {
+	engine := New("gpt-4o", Config{
+		Backend: constantBackend{content: `{"name": "Ada", "age": 36}`},
+	})
+
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "number"}},
+		Required:   []string{"name", "age"},
+	}
+
+	ch, err := engine.StructuredCompletionPartialStream("extract person", "Ada is 36", &StructuredConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("StructuredCompletionPartialStream() error = %v", err)
+	}
+
+	seen := map[string]interface{}{}
+	var final PartialResult
+	for update := range ch {
+		if update.Done {
+			final = update
+			continue
+		}
+		seen[update.Path] = update.Value
+	}
+
+	if seen["/name"] != "Ada" || seen["/age"] != float64(36) {
+		t.Errorf("field updates = %v, want /name=Ada and /age=36", seen)
+	}
+	if final.Err != nil {
+		t.Fatalf("final PartialResult.Err = %v, want nil", final.Err)
+	}
+	if final.Result["name"] != "Ada" || final.Result["age"] != float64(36) {
+		t.Errorf("final PartialResult.Result = %v, want {name: Ada, age: 36}", final.Result)
+	}
+}
+
+func TestStructuredCompletionPartialStream_RequiresSchema(t *testing.T) {
+	engine := New("gpt-4o", Config{Backend: constantBackend{content: `{}`}})
+
+	if _, err := engine.StructuredCompletionPartialStream("extract", "context", nil); err == nil {
+		t.Error("StructuredCompletionPartialStream() error = nil, want an error for a nil config")
+	}
+}
@@ -653,13 +653,13 @@ func TestBuildValidationFeedback_MissingField(t *testing.T) {
 		Required: []string{"name", "email"},
 	}
 
-	err := fmt.Errorf("missing required field: email")
+	err := validateAgainstSchema(map[string]interface{}{"name": "Alice"}, schema)
 	feedback := buildValidationFeedback(err, schema, `{"name": "Alice"}`)
 
 	if !strings.Contains(feedback, "email") {
 		t.Error("feedback should mention the missing field")
 	}
-	if !strings.Contains(feedback, "REQUIRED") {
+	if !strings.Contains(feedback, "required") {
 		t.Error("feedback should indicate field is required")
 	}
 	if !strings.Contains(feedback, "EXPECTED SCHEMA") {
@@ -667,6 +667,30 @@ func TestBuildValidationFeedback_MissingField(t *testing.T) {
 	}
 }
 
+func TestBuildValidationFeedback_MissingRefField(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"address": {Ref: "#/$defs/Address"},
+		},
+		Required: []string{"address"},
+		Definitions: map[string]*JSONSchema{
+			"Address": {
+				Type:       "object",
+				Properties: map[string]*JSONSchema{"city": {Type: "string"}},
+				Required:   []string{"city"},
+			},
+		},
+	}
+
+	err := validateAgainstSchema(map[string]interface{}{}, schema)
+	feedback := buildValidationFeedback(err, schema, `{}`)
+
+	if !strings.Contains(feedback, "object with required fields: city") {
+		t.Errorf("feedback should follow $ref and describe the resolved schema, got:\n%s", feedback)
+	}
+}
+
 func TestBuildValidationFeedback_TypeMismatch(t *testing.T) {
 	schema := &JSONSchema{
 		Type: "object",
@@ -676,11 +700,25 @@ func TestBuildValidationFeedback_TypeMismatch(t *testing.T) {
 		Required: []string{"count"},
 	}
 
-	err := fmt.Errorf("field count: expected number, got string")
+	err := validateAgainstSchema(map[string]interface{}{"count": "five"}, schema)
 	feedback := buildValidationFeedback(err, schema, `{"count": "five"}`)
 
-	if !strings.Contains(feedback, "Type mismatch") {
-		t.Error("feedback should mention type mismatch")
+	if !strings.Contains(feedback, "(type):") {
+		t.Error("feedback should mention the type keyword violation")
+	}
+	if !strings.Contains(feedback, "expected number, got string") {
+		t.Error("feedback should describe the expected and actual types")
+	}
+}
+
+func TestBuildValidationFeedback_NonValidationResultError(t *testing.T) {
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{"count": {Type: "number"}}}
+
+	err := fmt.Errorf("final answer is not valid JSON: unexpected end of input")
+	feedback := buildValidationFeedback(err, schema, `{"count": `)
+
+	if !strings.Contains(feedback, "not valid JSON") {
+		t.Errorf("feedback should surface the raw error when there's no ValidationResult to walk, got:\n%s", feedback)
 	}
 }
 
@@ -725,6 +763,56 @@ func TestBuildExampleJSON_WithEnum(t *testing.T) {
 	}
 }
 
+func TestBuildExampleJSON_ResolvesRef(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"address": {Ref: "#/$defs/Address"},
+		},
+		Required: []string{"address"},
+		Definitions: map[string]*JSONSchema{
+			"Address": {
+				Type:       "object",
+				Properties: map[string]*JSONSchema{"city": {Type: "string"}},
+				Required:   []string{"city"},
+			},
+		},
+	}
+
+	example := buildExampleJSON(schema)
+	if example == "" {
+		t.Fatal("expected a non-empty example for a $ref field")
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(example), &parsed); err != nil {
+		t.Fatalf("example should be valid JSON: %v", err)
+	}
+	address, ok := parsed["address"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected 'address' to resolve to an object, got %v", parsed["address"])
+	}
+	if _, ok := address["city"]; !ok {
+		t.Error("resolved 'address' example should include 'city'")
+	}
+}
+
+func TestBuildExampleJSON_SelfReferentialRefTerminates(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"value": {Type: "string"},
+			"child": {Ref: "#/$defs/Node"},
+		},
+		Required: []string{"value", "child"},
+	}
+	schema.Definitions = map[string]*JSONSchema{"Node": schema}
+
+	// Should terminate rather than recurse forever, even though Node
+	// references itself.
+	_ = buildExampleJSON(schema)
+}
+
 func TestBuildExampleJSON_NoRequiredFields(t *testing.T) {
 	schema := &JSONSchema{
 		Type: "object",
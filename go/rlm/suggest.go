@@ -0,0 +1,120 @@
+package rlm
+
+// levenshteinDistance returns the edit distance between a and b (insertions,
+// deletions, and substitutions each cost 1), using the standard
+// single-row dynamic-programming table.
+func levenshteinDistance(a, b string) int {
+	if a == b {
+		return 0
+	}
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 {
+		return len(br)
+	}
+	if len(br) == 0 {
+		return len(ar)
+	}
+
+	prev := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr := make([]int, len(br)+1)
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(curr[j-1]+1, prev[j]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// suggestField finds the candidate closest to name by Levenshtein distance,
+// returning it and true if the distance is within a small threshold
+// (at most 2 edits, or 40% of the shorter string's length, whichever is
+// larger) - close enough to plausibly be the model's typo rather than an
+// unrelated field. Used to turn "sentimentScore" into a "did you mean
+// 'sentiment_score'?" hint instead of a bare validation failure.
+func suggestField(name string, candidates []string) (string, bool) {
+	best := ""
+	bestDist := -1
+	for _, candidate := range candidates {
+		dist := levenshteinDistance(name, candidate)
+		if bestDist == -1 || dist < bestDist {
+			bestDist = dist
+			best = candidate
+		}
+	}
+	if bestDist == -1 {
+		return "", false
+	}
+
+	threshold := 2
+	shorter := len(name)
+	if len(best) < shorter {
+		shorter = len(best)
+	}
+	if fortyPercent := shorter * 2 / 5; fortyPercent > threshold {
+		threshold = fortyPercent
+	}
+
+	return best, bestDist <= threshold
+}
+
+// didYouMeanHint looks at a single validation error - a missing required
+// field ("required") or an unrecognized one ("unknownProperty") - and, if
+// the offending name is a close enough typo of one of the schema's known
+// property names at that path, returns the suggested name. Returns "" when
+// the keyword isn't one this applies to, the path's schema can't be found,
+// or no candidate is close enough.
+func didYouMeanHint(e ValidationError, root *JSONSchema) string {
+	var offending, objectPath string
+	switch e.Keyword {
+	case "required":
+		// e.Path is the missing field's own pointer (the object whose
+		// Properties we need to search is its parent).
+		offending = e.Expected
+		objectPath = parentPointer(e.Path)
+	case "unknownProperty":
+		offending = e.Got
+		objectPath = e.Path
+	default:
+		return ""
+	}
+
+	objectSchema := schemaAtPath(root, objectPath)
+	if objectSchema == nil || len(objectSchema.Properties) == 0 {
+		return ""
+	}
+
+	candidates := make([]string, 0, len(objectSchema.Properties))
+	for name := range objectSchema.Properties {
+		if name == offending {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+
+	suggestion, ok := suggestField(offending, candidates)
+	if !ok {
+		return ""
+	}
+	return suggestion
+}
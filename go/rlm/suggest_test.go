@@ -0,0 +1,61 @@
+package rlm
+
+import "testing"
+
+func TestLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"abc", "", 3},
+		{"sentiment_score", "sentimentScore", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := levenshteinDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshteinDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestField(t *testing.T) {
+	candidates := []string{"sentiment_score", "explanation", "tags"}
+
+	suggestion, ok := suggestField("sentimentScore", candidates)
+	if !ok || suggestion != "sentiment_score" {
+		t.Errorf("suggestField(%q) = (%q, %v), want (\"sentiment_score\", true)", "sentimentScore", suggestion, ok)
+	}
+
+	if _, ok := suggestField("completelyUnrelatedKey", candidates); ok {
+		t.Error("expected no suggestion for a name with no close candidate")
+	}
+}
+
+func TestDidYouMeanHint_UnknownProperty(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"sentiment_score": {Type: "number"}},
+	}
+
+	err := validateValue(map[string]interface{}{"sentimentScore": float64(1)}, schema)
+	feedback := buildValidationFeedback(err, schema, `{"sentimentScore": 1}`)
+
+	if !contains(splitLines(feedback), "  Did you mean 'sentiment_score'?") {
+		t.Errorf("expected a \"did you mean\" hint in feedback, got: %s", feedback)
+	}
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	start := 0
+	for i, c := range s {
+		if c == '\n' {
+			lines = append(lines, s[start:i])
+			start = i + 1
+		}
+	}
+	lines = append(lines, s[start:])
+	return lines
+}
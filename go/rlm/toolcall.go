@@ -0,0 +1,383 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ToolDefinition is the provider-wire description of a Tool: its name,
+// description, and JSON Schema parameters, as sent in ChatRequest.Tools.
+type ToolDefinition struct {
+	Name        string
+	Description string
+	Parameters  *JSONSchema
+}
+
+// Definitions converts every tool in the registry to a ToolDefinition for
+// use with ChatRequest.Tools - the provider-native alternative to the
+// buildToolsPrompt() prose description used by the TOOL() convention.
+func (tr *ToolRegistry) Definitions() []ToolDefinition {
+	tools := tr.List()
+	defs := make([]ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		defs = append(defs, ToolDefinition{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Parameters:  tool.JSONSchema(),
+		})
+	}
+	return defs
+}
+
+// ToolCall is a provider-native function call the model made, as returned in
+// ChatResponse.ToolCalls or round-tripped back via Message.ToolCalls. Its
+// JSON encoding matches the OpenAI wire format, where the call is nested
+// under a "function" object and Arguments is a JSON-encoded string rather
+// than a raw object.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+type toolCallWire struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func (tc ToolCall) MarshalJSON() ([]byte, error) {
+	wire := toolCallWire{ID: tc.ID, Type: "function"}
+	wire.Function.Name = tc.Name
+	wire.Function.Arguments = string(tc.Arguments)
+	return json.Marshal(wire)
+}
+
+func (tc *ToolCall) UnmarshalJSON(data []byte) error {
+	var wire toolCallWire
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	tc.ID = wire.ID
+	tc.Name = wire.Function.Name
+	tc.Arguments = json.RawMessage(wire.Function.Arguments)
+	return nil
+}
+
+// toolsWirePayload converts ToolDefinitions to the OpenAI "tools" request
+// field: a list of {"type": "function", "function": {name, description,
+// parameters}} objects.
+func toolsWirePayload(tools []ToolDefinition) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  t.Parameters,
+			},
+		})
+	}
+	return out
+}
+
+// ToolInvocation records one tool call ToolCallCompletion dispatched -
+// appended to RLMStats.ToolTrace in call order, so a caller can audit what
+// the model ran alongside the returned answer string.
+type ToolInvocation struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+	Result    string          `json:"result,omitempty"`
+	Err       string          `json:"err,omitempty"`
+}
+
+// ToolCallCompletion runs the RLM loop using provider-native function/tool
+// calling (the OpenAI tools/tool_choice wire format) instead of the JS REPL
+// plus FINAL() convention. The model is given r.toolRegistry's tools plus
+// four built-ins - search_context (to inspect the document without REPL
+// code), recursive_llm, repl_exec and final_answer (to end the loop) - each
+// added unless a tool of that name is already registered. Each returned tool call is
+// dispatched and fed back as a "tool" role message; a final_answer call (or
+// a response with no tool calls at all) ends the loop.
+//
+// This requires a backend whose wire format actually carries tool_calls
+// (openAIBackend does); other backends will simply never produce a tool
+// call and ToolCallCompletion will return their first plain-text response.
+func (r *RLM) ToolCallCompletion(ctx stdcontext.Context, query string, context string) (string, RLMStats, error) {
+	ctx = r.observer.StartTrace(ctx, "rlm.tool_call_completion", map[string]string{
+		"model": r.model,
+		"depth": fmt.Sprintf("%d", r.currentDepth),
+	})
+	defer r.observer.EndTrace(ctx)
+
+	if r.currentDepth >= r.maxDepth {
+		return "", r.stats, NewMaxDepthError(r.maxDepth)
+	}
+
+	if r.metaAgent != nil && r.currentDepth == 0 {
+		optimized, err := r.metaAgent.OptimizeQuery(ctx, query, context)
+		if err == nil && optimized != "" {
+			query = optimized
+		}
+	}
+
+	session := newToolCallSession(r, query, context)
+
+	systemPrompt := "You are a data analysis assistant with access to tools for searching a document " +
+		"and, where available, recursing into sub-queries. Call final_answer once you have the answer " +
+		"to the user's query; do not write prose explaining your plan."
+	messages := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: query},
+	}
+
+	r.stats.Depth = r.currentDepth
+
+	for iteration := 0; iteration < r.maxIterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return "", r.stats, err
+		}
+
+		r.stats.Iterations = iteration + 1
+		r.metrics.OnIteration(r.currentDepth, iteration+1)
+		r.observer.RecordIteration(r.currentDepth)
+
+		response, err := r.callLLMTools(ctx, messages, session.definitions)
+		if err != nil {
+			return "", r.stats, err
+		}
+
+		if len(response.ToolCalls) == 0 {
+			r.observer.Debug("rlm", "tool-call completion ended on a plain-text response (no tool calls)")
+			r.metrics.OnFinal(r.currentDepth, 0)
+			return response.Content, r.stats, nil
+		}
+
+		messages = append(messages, Message{Role: "assistant", Content: response.Content, ToolCalls: response.ToolCalls})
+
+		for _, call := range response.ToolCalls {
+			r.stats.ToolCalls++
+			r.observer.RecordToolCall(call.Name)
+
+			if call.Name == "final_answer" {
+				answer, err := session.invoke(ctx, call)
+				r.stats.ToolTrace = append(r.stats.ToolTrace, toolInvocationRecord(call, answer, err))
+				if err != nil {
+					return "", r.stats, fmt.Errorf("final_answer: %w", err)
+				}
+				r.metrics.OnFinal(r.currentDepth, 0)
+				return answer, r.stats, nil
+			}
+
+			result, err := session.invoke(ctx, call)
+			r.stats.ToolTrace = append(r.stats.ToolTrace, toolInvocationRecord(call, result, err))
+			if err != nil {
+				r.observer.Error("rlm", "tool call %s failed: %v", call.Name, err)
+				result = fmt.Sprintf("Error: %s", err.Error())
+			}
+			messages = append(messages, Message{Role: "tool", Content: result, ToolCallID: call.ID})
+		}
+	}
+
+	return "", r.stats, NewMaxIterationsError(r.maxIterations)
+}
+
+// callLLMTools is callLLM's counterpart for tool-calling mode: it returns
+// the full ChatResponse (so callers can see ToolCalls) instead of just the
+// completion text.
+func (r *RLM) callLLMTools(ctx stdcontext.Context, messages []Message, tools []ToolDefinition) (ChatResponse, error) {
+	r.stats.LlmCalls++
+	defaultModel := r.model
+	if r.currentDepth > 0 {
+		defaultModel = r.recursiveModel
+	}
+
+	request := ChatRequest{
+		Model:       defaultModel,
+		Messages:    messages,
+		APIBase:     r.apiBase,
+		APIKey:      r.apiKey,
+		Timeout:     r.timeoutSeconds,
+		ExtraParams: r.extraParams,
+		Tools:       tools,
+		ToolChoice:  "auto",
+		Retry:       r.retry,
+	}
+
+	response, err := r.backend.Chat(ctx, request)
+	if err != nil {
+		r.metrics.OnLLMCall(r.currentDepth, defaultModel, 0, 0, 0)
+		return ChatResponse{}, err
+	}
+
+	r.stats.PromptTokens += response.Usage.PromptTokens
+	r.stats.CompletionTokens += response.Usage.CompletionTokens
+	r.stats.TotalTokens += response.Usage.TotalTokens
+	r.metrics.OnLLMCall(r.currentDepth, defaultModel, response.Usage.PromptTokens, response.Usage.CompletionTokens, 0)
+
+	return response, nil
+}
+
+// toolCallSession merges an RLM's registered tools with the built-ins
+// ToolCallCompletion offers (search_context, recursive_llm, final_answer and
+// repl_exec, each unless the caller already registered one) for a single
+// completion call. It's built fresh per call rather than mutating
+// r.toolRegistry, since search_context is bound to that call's context and
+// recursion depth differs per sub-call.
+type toolCallSession struct {
+	tools       map[string]Tool
+	definitions []ToolDefinition
+}
+
+func newToolCallSession(r *RLM, query string, context string) *toolCallSession {
+	tools := map[string]Tool{}
+	if r.toolRegistry != nil {
+		for _, tool := range r.toolRegistry.List() {
+			tools[tool.Name()] = tool
+		}
+	}
+	if _, ok := tools["search_context"]; !ok {
+		tools["search_context"] = NewSearchContextTool(context)
+	}
+	if _, ok := tools["recursive_llm"]; !ok {
+		tools["recursive_llm"] = NewRecursiveLLMTool(r)
+	}
+	if _, ok := tools["final_answer"]; !ok {
+		tools["final_answer"] = NewFinalAnswerTool()
+	}
+	if _, ok := tools["repl_exec"]; !ok {
+		tools["repl_exec"] = NewREPLExecTool(r, query, context)
+	}
+
+	defs := make([]ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		defs = append(defs, ToolDefinition{Name: tool.Name(), Description: tool.Description(), Parameters: tool.JSONSchema()})
+	}
+
+	return &toolCallSession{tools: tools, definitions: defs}
+}
+
+// toolInvocationRecord builds the ToolTrace entry for a dispatched call,
+// capturing its error message (if any) rather than result when invoke
+// failed, so a trace reader doesn't have to cross-reference the "Error: ..."
+// string ToolCallCompletion feeds back to the model.
+func toolInvocationRecord(call ToolCall, result string, err error) ToolInvocation {
+	record := ToolInvocation{Name: call.Name, Arguments: call.Arguments}
+	if err != nil {
+		record.Err = err.Error()
+	} else {
+		record.Result = result
+	}
+	return record
+}
+
+func (s *toolCallSession) invoke(ctx stdcontext.Context, call ToolCall) (string, error) {
+	tool, ok := s.tools[call.Name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", call.Name)
+	}
+	return tool.Invoke(ctx, call.Arguments)
+}
+
+// searchContextTool is the built-in "search_context" tool: a case-insensitive
+// substring search over a completion's context, bound at construction time
+// since (unlike http_fetch or file_read) it has no meaning independent of a
+// specific call's document.
+type searchContextTool struct {
+	context string
+}
+
+// NewSearchContextTool creates the built-in "search_context" tool bound to a
+// single completion's context, so tool-calling mode can locate text in the
+// document the way REPL code would search the context global.
+func NewSearchContextTool(context string) Tool {
+	return &searchContextTool{context: context}
+}
+
+func (t *searchContextTool) Name() string { return "search_context" }
+
+func (t *searchContextTool) Description() string {
+	return "Search the document for lines containing a substring (case-insensitive) and return them with line numbers."
+}
+
+func (t *searchContextTool) JSONSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"query":       {Type: "string", Description: "Substring to search for"},
+			"max_matches": {Type: "integer", Description: "Maximum number of matching lines to return (default 10)"},
+		},
+		Required: []string{"query"},
+	}
+}
+
+func (t *searchContextTool) Invoke(_ stdcontext.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Query      string `json:"query"`
+		MaxMatches int    `json:"max_matches"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("search_context: invalid arguments: %w", err)
+	}
+	if params.Query == "" {
+		return "", fmt.Errorf("search_context: query is required")
+	}
+	maxMatches := params.MaxMatches
+	if maxMatches <= 0 {
+		maxMatches = 10
+	}
+
+	needle := strings.ToLower(params.Query)
+	var matches []string
+	for i, line := range strings.Split(t.context, "\n") {
+		if strings.Contains(strings.ToLower(line), needle) {
+			matches = append(matches, fmt.Sprintf("line %d: %s", i+1, line))
+			if len(matches) >= maxMatches {
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return "No matches found.", nil
+	}
+	return strings.Join(matches, "\n"), nil
+}
+
+// finalAnswerTool is the built-in "final_answer" tool: calling it ends a
+// ToolCallCompletion loop the way FINAL(...) ends the REPL loop. Its Invoke
+// is still exercised by toolCallSession.invoke for argument validation, even
+// though ToolCallCompletion special-cases the name to stop iterating.
+type finalAnswerTool struct{}
+
+// NewFinalAnswerTool creates the built-in "final_answer" tool.
+func NewFinalAnswerTool() Tool { return finalAnswerTool{} }
+
+func (finalAnswerTool) Name() string { return "final_answer" }
+
+func (finalAnswerTool) Description() string {
+	return "Call this with the final answer once you've finished analyzing the document. Ends the completion."
+}
+
+func (finalAnswerTool) JSONSchema() *JSONSchema {
+	return &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"answer": {Type: "string"}},
+		Required:   []string{"answer"},
+	}
+}
+
+func (finalAnswerTool) Invoke(_ stdcontext.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("final_answer: invalid arguments: %w", err)
+	}
+	return params.Answer, nil
+}
@@ -0,0 +1,135 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToolCallJSONRoundTrip(t *testing.T) {
+	call := ToolCall{ID: "call_1", Name: "search_context", Arguments: json.RawMessage(`{"query":"invoice"}`)}
+
+	encoded, err := json.Marshal(call)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(encoded), `"type":"function"`) {
+		t.Errorf("Marshal() = %s, want OpenAI-style function wrapper", encoded)
+	}
+	if !strings.Contains(string(encoded), `"arguments":"{\"query\":\"invoice\"}"`) {
+		t.Errorf("Marshal() = %s, want arguments encoded as a JSON string", encoded)
+	}
+
+	var decoded ToolCall
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.ID != call.ID || decoded.Name != call.Name || string(decoded.Arguments) != string(call.Arguments) {
+		t.Errorf("round trip = %+v, want %+v", decoded, call)
+	}
+}
+
+func TestToolsWirePayload(t *testing.T) {
+	defs := []ToolDefinition{
+		{Name: "search_context", Description: "search", Parameters: &JSONSchema{Type: "object"}},
+	}
+	payload := toolsWirePayload(defs)
+	if len(payload) != 1 {
+		t.Fatalf("toolsWirePayload() returned %d entries, want 1", len(payload))
+	}
+	if payload[0]["type"] != "function" {
+		t.Errorf(`payload[0]["type"] = %v, want "function"`, payload[0]["type"])
+	}
+	fn, ok := payload[0]["function"].(map[string]interface{})
+	if !ok || fn["name"] != "search_context" {
+		t.Errorf("payload[0][\"function\"] = %v, want name search_context", payload[0]["function"])
+	}
+}
+
+func TestToolRegistryDefinitions(t *testing.T) {
+	reg := NewToolRegistry()
+	reg.Register(echoTool{})
+
+	defs := reg.Definitions()
+	if len(defs) != 1 || defs[0].Name != "echo" {
+		t.Fatalf("Definitions() = %+v, want one definition named echo", defs)
+	}
+}
+
+func TestSearchContextTool(t *testing.T) {
+	tool := NewSearchContextTool("line one\nline two has INVOICE\nline three")
+
+	result, err := tool.Invoke(stdcontext.Background(), json.RawMessage(`{"query":"invoice"}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if !strings.Contains(result, "line 2") {
+		t.Errorf("Invoke() = %q, want it to report line 2", result)
+	}
+
+	noMatch, err := tool.Invoke(stdcontext.Background(), json.RawMessage(`{"query":"nonexistent"}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if noMatch != "No matches found." {
+		t.Errorf("Invoke() = %q, want no-match message", noMatch)
+	}
+
+	if _, err := tool.Invoke(stdcontext.Background(), json.RawMessage(`{}`)); err == nil {
+		t.Error("Invoke() with no query should return an error")
+	}
+}
+
+func TestFinalAnswerTool(t *testing.T) {
+	tool := NewFinalAnswerTool()
+
+	result, err := tool.Invoke(stdcontext.Background(), json.RawMessage(`{"answer":"42"}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if result != "42" {
+		t.Errorf("Invoke() = %q, want %q", result, "42")
+	}
+}
+
+func TestREPLExecTool(t *testing.T) {
+	r := New("gpt-4o", Config{})
+	tool := NewREPLExecTool(r, "what is 2+2?", "some context")
+
+	result, err := tool.Invoke(stdcontext.Background(), json.RawMessage(`{"code":"1 + 1"}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if result != "2" {
+		t.Errorf("Invoke() = %q, want %q", result, "2")
+	}
+
+	result, err = tool.Invoke(stdcontext.Background(), json.RawMessage(`{"code":"context"}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if result != "some context" {
+		t.Errorf("Invoke() = %q, want the bound context global %q", result, "some context")
+	}
+
+	if _, err := tool.Invoke(stdcontext.Background(), json.RawMessage(`{}`)); err == nil {
+		t.Error("Invoke() with no code should return an error")
+	}
+}
+
+func TestToolInvocationRecord(t *testing.T) {
+	call := ToolCall{Name: "search_context", Arguments: json.RawMessage(`{"query":"x"}`)}
+
+	ok := toolInvocationRecord(call, "found it", nil)
+	if ok.Result != "found it" || ok.Err != "" {
+		t.Errorf("toolInvocationRecord() = %+v, want Result set and Err empty", ok)
+	}
+
+	stubErr := errors.New("tool failed")
+	failed := toolInvocationRecord(call, "", stubErr)
+	if failed.Err != stubErr.Error() || failed.Result != "" {
+		t.Errorf("toolInvocationRecord() = %+v, want Err set and Result empty", failed)
+	}
+}
@@ -0,0 +1,348 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tool is a named, schema-described capability the model can invoke with
+// TOOL("name", {...}) instead of writing REPL code. Implementations should
+// be safe for concurrent use, since the same tool may be invoked from
+// recursive sub-completions running in parallel.
+type Tool interface {
+	Name() string
+	Description() string
+	JSONSchema() *JSONSchema
+	Invoke(ctx stdcontext.Context, args json.RawMessage) (string, error)
+}
+
+// ToolRegistry holds the set of tools available to an RLM instance.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+}
+
+// NewToolRegistry creates an empty tool registry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]Tool)}
+}
+
+// Register adds a tool to the registry, replacing any existing tool with the
+// same name.
+func (tr *ToolRegistry) Register(tool Tool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.tools[tool.Name()] = tool
+}
+
+// Get looks up a tool by name.
+func (tr *ToolRegistry) Get(name string) (Tool, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	tool, ok := tr.tools[name]
+	return tool, ok
+}
+
+// List returns all registered tools.
+func (tr *ToolRegistry) List() []Tool {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	tools := make([]Tool, 0, len(tr.tools))
+	for _, tool := range tr.tools {
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// Invoke dispatches args to the named tool, returning an error if the tool
+// is not registered.
+func (tr *ToolRegistry) Invoke(ctx stdcontext.Context, name string, args json.RawMessage) (string, error) {
+	tool, ok := tr.Get(name)
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", name)
+	}
+	return tool.Invoke(ctx, args)
+}
+
+// buildToolsPrompt describes the available tools and the TOOL() call syntax,
+// appended to the system prompt when a ToolRegistry is configured.
+func buildToolsPrompt(tools []Tool) string {
+	if len(tools) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\nYou also have tools available. Instead of writing REPL code, you may call a ")
+	b.WriteString("tool by writing, as your entire response:\n\nTOOL(\"tool_name\", {\"arg\": \"value\"})\n\n")
+	b.WriteString("The tool's result will be given back to you as the next message. Available tools:\n")
+	for _, tool := range tools {
+		schema, _ := json.Marshal(tool.JSONSchema())
+		b.WriteString(fmt.Sprintf("- %s: %s Arguments schema: %s\n", tool.Name(), tool.Description(), schema))
+	}
+	return b.String()
+}
+
+// httpFetchTool is a built-in tool that performs an HTTP GET and returns the
+// response body, truncated to a reasonable size for the model's context.
+type httpFetchTool struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewHTTPFetchTool creates the built-in "http_fetch" tool.
+func NewHTTPFetchTool() Tool {
+	return &httpFetchTool{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		maxBytes: 100_000,
+	}
+}
+
+func (t *httpFetchTool) Name() string { return "http_fetch" }
+
+func (t *httpFetchTool) Description() string {
+	return "Fetch the contents of a URL via HTTP GET and return the response body as text."
+}
+
+func (t *httpFetchTool) JSONSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"url": {Type: "string", Format: "uri"},
+		},
+		Required: []string{"url"},
+	}
+}
+
+func (t *httpFetchTool) Invoke(ctx stdcontext.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("http_fetch: invalid arguments: %w", err)
+	}
+	if params.URL == "" {
+		return "", fmt.Errorf("http_fetch: url is required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("http_fetch: %w", err)
+	}
+	injectTraceContext(ctx, req.Header)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("http_fetch: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBytes))
+	if err != nil {
+		return "", fmt.Errorf("http_fetch: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("http_fetch: %s returned status %d", params.URL, resp.StatusCode)
+	}
+
+	return string(body), nil
+}
+
+// fileReadTool is a built-in tool that reads a file from the local
+// filesystem and returns its contents as text.
+type fileReadTool struct {
+	maxBytes int64
+}
+
+// NewFileReadTool creates the built-in "file_read" tool.
+func NewFileReadTool() Tool {
+	return &fileReadTool{maxBytes: 100_000}
+}
+
+func (t *fileReadTool) Name() string { return "file_read" }
+
+func (t *fileReadTool) Description() string {
+	return "Read the contents of a local file and return it as text."
+}
+
+func (t *fileReadTool) JSONSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"path": {Type: "string"},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (t *fileReadTool) Invoke(_ stdcontext.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("file_read: invalid arguments: %w", err)
+	}
+	if params.Path == "" {
+		return "", fmt.Errorf("file_read: path is required")
+	}
+
+	f, err := os.Open(params.Path)
+	if err != nil {
+		return "", fmt.Errorf("file_read: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(f, t.maxBytes))
+	if err != nil {
+		return "", fmt.Errorf("file_read: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// replExecTool is a built-in tool that routes "repl_exec" calls through
+// REPLExecutor.Execute, so a model using native tool-calling can run
+// sandboxed JS (with the same context/query/re/recursive_llm globals the
+// REPL completion loop exposes) without the text-protocol EXEC(...)/code-fence
+// convention.
+type replExecTool struct {
+	rlm     *RLM
+	query   string
+	context string
+}
+
+// NewREPLExecTool wraps r's REPLExecutor as a Tool named "repl_exec", bound
+// to a single completion's query/context the way NewSearchContextTool is.
+func NewREPLExecTool(r *RLM, query string, context string) Tool {
+	return &replExecTool{rlm: r, query: query, context: context}
+}
+
+func (t *replExecTool) Name() string { return "repl_exec" }
+
+func (t *replExecTool) Description() string {
+	return "Execute JavaScript in the sandboxed REPL (with access to context, query, re, and recursive_llm) and return its output."
+}
+
+func (t *replExecTool) JSONSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"code": {Type: "string", Description: "JavaScript source to execute"},
+		},
+		Required: []string{"code"},
+	}
+}
+
+func (t *replExecTool) Invoke(ctx stdcontext.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("repl_exec: invalid arguments: %w", err)
+	}
+	if params.Code == "" {
+		return "", fmt.Errorf("repl_exec: code is required")
+	}
+
+	env := t.rlm.buildREPLEnv(ctx, t.query, t.context)
+	return t.rlm.repl.ExecuteContext(ctx, params.Code, env, ExecuteOptions{})
+}
+
+// recursiveLLMTool exposes recursive_llm() as a tool, so recursive calls made
+// via TOOL("recursive_llm", ...) are accounted for against the same maxDepth
+// and currentDepth bookkeeping as the REPL's recursive_llm() global.
+type recursiveLLMTool struct {
+	rlm *RLM
+}
+
+// NewRecursiveLLMTool wraps r's recursive completion as a Tool named
+// "recursive_llm", so depth accounting stays unified whether the model
+// recurses via the REPL global or via tool-calling.
+func NewRecursiveLLMTool(r *RLM) Tool {
+	return &recursiveLLMTool{rlm: r}
+}
+
+func (t *recursiveLLMTool) Name() string { return "recursive_llm" }
+
+func (t *recursiveLLMTool) Description() string {
+	return "Recursively process a sub-query against a sub-context using the RLM engine."
+}
+
+func (t *recursiveLLMTool) JSONSchema() *JSONSchema {
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"query":   {Type: "string"},
+			"context": {Type: "string"},
+			"schema":  {Type: "object", Description: "Optional JSON Schema the sub-task's answer must conform to. When set, the result is a validated JSON document instead of free-form text."},
+		},
+		Required: []string{"query", "context"},
+	}
+}
+
+func (t *recursiveLLMTool) Invoke(ctx stdcontext.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Query   string      `json:"query"`
+		Context string      `json:"context"`
+		Schema  *JSONSchema `json:"schema"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("recursive_llm: invalid arguments: %w", err)
+	}
+
+	r := t.rlm
+	if r.currentDepth+1 >= r.maxDepth {
+		return fmt.Sprintf("Max recursion depth (%d) reached", r.maxDepth), nil
+	}
+
+	r.metrics.OnRecurse(r.currentDepth)
+
+	subConfig := Config{
+		RecursiveModel:   r.recursiveModel,
+		APIBase:          r.apiBase,
+		APIKey:           r.apiKey,
+		MaxDepth:         r.maxDepth,
+		MaxIterations:    r.maxIterations,
+		MaxTokens:        r.maxTokens,
+		TimeoutSeconds:   r.timeoutSeconds,
+		UseMetacognitive: r.useMetacognitive,
+		ExtraParams:      r.extraParams,
+		ToolRegistry:     r.toolRegistry,
+		Backend:          r.backend,
+		MetricsObserver:  r.metrics,
+		Retry:            r.retry,
+		DetailedStats:    r.detailedStats,
+		Sandbox:          r.sandbox,
+	}
+
+	subRLM := New(r.recursiveModel, subConfig)
+	subRLM.currentDepth = r.currentDepth + 1
+	subRLM.observer = r.observer
+
+	if params.Schema != nil {
+		raw, subStats, err := subRLM.StructuredCompletionJSONContext(ctx, params.Query, params.Context, params.Schema)
+		r.mergeSubStats(subStats)
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	}
+
+	answer, subStats, err := subRLM.CompletionContext(ctx, params.Query, params.Context)
+	r.mergeSubStats(subStats)
+	if err != nil {
+		return "", err
+	}
+	return answer, nil
+}
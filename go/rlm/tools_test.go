@@ -0,0 +1,143 @@
+package rlm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseToolCall(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantName string
+		wantArgs string
+		wantOk   bool
+	}{
+		{
+			name:     "simple call",
+			response: `TOOL("http_fetch", {"url": "https://example.com"})`,
+			wantName: "http_fetch",
+			wantArgs: `{"url": "https://example.com"}`,
+			wantOk:   true,
+		},
+		{
+			name:     "no tool call",
+			response: `FINAL("answer")`,
+			wantOk:   false,
+		},
+		{
+			name:     "invalid json args",
+			response: `TOOL("x", {not json})`,
+			wantOk:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseToolCall(tt.response)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseToolCall() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("ParseToolCall() name = %q, want %q", got.Name, tt.wantName)
+			}
+			if string(got.Args) != tt.wantArgs {
+				t.Errorf("ParseToolCall() args = %q, want %q", got.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+type echoTool struct{}
+
+func (echoTool) Name() string            { return "echo" }
+func (echoTool) Description() string     { return "Echoes its input" }
+func (echoTool) JSONSchema() *JSONSchema { return &JSONSchema{Type: "object"} }
+func (echoTool) Invoke(_ stdcontext.Context, args json.RawMessage) (string, error) {
+	return string(args), nil
+}
+
+func TestRecursiveLLMTool_WithSchemaReturnsValidatedJSON(t *testing.T) {
+	engine := New("gpt-4o", Config{
+		Backend:       constantBackend{content: `{"name": "Alice", "age": 30}`},
+		MaxDepth:      5,
+		MaxIterations: 30,
+	})
+
+	tool := NewRecursiveLLMTool(engine)
+	args := json.RawMessage(`{
+		"query": "extract person",
+		"context": "Alice is 30",
+		"schema": {
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "age": {"type": "integer"}},
+			"required": ["name", "age"]
+		}
+	}`)
+
+	result, err := tool.Invoke(stdcontext.Background(), args)
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &decoded); err != nil {
+		t.Fatalf("Invoke() returned invalid JSON: %v", err)
+	}
+	if decoded["name"] != "Alice" {
+		t.Errorf("decoded[\"name\"] = %v, want Alice", decoded["name"])
+	}
+}
+
+func TestRecursiveLLMTool_WithoutSchemaReturnsPlainText(t *testing.T) {
+	engine := New("gpt-4o", Config{
+		Backend:       constantBackend{content: `FINAL("the answer")`},
+		MaxDepth:      5,
+		MaxIterations: 30,
+	})
+
+	tool := NewRecursiveLLMTool(engine)
+	args := json.RawMessage(`{"query": "q", "context": "c"}`)
+
+	result, err := tool.Invoke(stdcontext.Background(), args)
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if result != "the answer" {
+		t.Errorf("Invoke() = %q, want %q", result, "the answer")
+	}
+}
+
+func TestToolRegistry(t *testing.T) {
+	reg := NewToolRegistry()
+	reg.Register(echoTool{})
+
+	if _, ok := reg.Get("missing"); ok {
+		t.Fatalf("Get() found a tool that was never registered")
+	}
+
+	tool, ok := reg.Get("echo")
+	if !ok || tool.Name() != "echo" {
+		t.Fatalf("Get() = %v, %v, want echo tool", tool, ok)
+	}
+
+	result, err := reg.Invoke(stdcontext.Background(), "echo", json.RawMessage(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if result != `{"a":1}` {
+		t.Errorf("Invoke() = %q, want %q", result, `{"a":1}`)
+	}
+
+	if _, err := reg.Invoke(stdcontext.Background(), "missing", nil); err == nil {
+		t.Error("Invoke() with unknown tool should return an error")
+	}
+
+	if got := len(reg.List()); got != 1 {
+		t.Errorf("List() returned %d tools, want 1", got)
+	}
+}
@@ -1,42 +1,297 @@
 package rlm
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type RLMStats struct {
-	LlmCalls       int `json:"llm_calls"`
-	Iterations     int `json:"iterations"`
-	Depth          int `json:"depth"`
-	ParsingRetries int `json:"parsing_retries,omitempty"`
+	LlmCalls         int        `json:"llm_calls"`
+	Iterations       int        `json:"iterations"`
+	Depth            int        `json:"depth"`
+	ParsingRetries   int        `json:"parsing_retries,omitempty"`
+	ToolCalls        int        `json:"tool_calls,omitempty"`
+	PromptTokens     int        `json:"prompt_tokens,omitempty"`
+	CompletionTokens int        `json:"completion_tokens,omitempty"`
+	TotalTokens      int        `json:"total_tokens,omitempty"`
+	ByDepth          []RLMStats `json:"by_depth,omitempty"`   // Per-recursion-level breakdown, one entry per recursive_llm() call merged into this stats
+	NodeStats        []NodeStat `json:"node_stats,omitempty"` // Per-subtask timing, one entry per SubTask scheduled by structuredCompletionParallel(Stream)
+	// ToolTrace records every tool call ToolCallCompletion dispatched, in
+	// order, so callers can audit what the model ran even when the final
+	// answer came back through a tool rather than as prose.
+	ToolTrace []ToolInvocation `json:"tool_trace,omitempty"`
+	// EnforcementViolations records every Dryrun/Repair violation
+	// applyEnforcement resolved without a retry, one entry per violated
+	// field. Warn violations are logged as observer events instead, since
+	// (unlike Dryrun) they're meant to be visible in real time, not just
+	// in the final stats.
+	EnforcementViolations []EnforcementViolation `json:"enforcement_violations,omitempty"`
+	// ResponseFormatMode records which ProviderMode (if any)
+	// StructuredConfig.UseResponseFormat routed the schema through, so
+	// callers can see how structure was enforced on this call.
+	ResponseFormatMode string `json:"response_format_mode,omitempty"`
+	// NativeStructured reports whether this call's result came back
+	// schema-conformant on the first attempt via a provider-guaranteed
+	// surface (StructuredConfig.Mode/ProviderMode, or a ConstrainedDecoder
+	// grammar), skipping the parse-validate-retry loop entirely.
+	NativeStructured bool `json:"native_structured,omitempty"`
+	// Calls holds one CallRecord per LLM call made by CompletionContext's
+	// agentic loop, only populated when Config.DetailedStats is set.
+	Calls []CallRecord `json:"calls,omitempty"`
+	// PerDepth aggregates Calls by recursion depth (latency percentiles
+	// included), computed once CompletionContext's top-level call returns.
+	// Empty unless Config.DetailedStats is set.
+	PerDepth []DepthStats `json:"per_depth,omitempty"`
+}
+
+// CallRecord is one LLM call's outcome, recorded in RLMStats.Calls when
+// Config.DetailedStats is enabled.
+type CallRecord struct {
+	Depth            int    `json:"depth"`
+	Iteration        int    `json:"iteration"`
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	LatencyMs        int64  `json:"latency_ms"`
+	REPLExecs        int    `json:"repl_execs,omitempty"`
+	REPLErrors       int    `json:"repl_errors,omitempty"`
+	FinishReason     string `json:"finish_reason,omitempty"`
+	// Err is the call's error message, if any; empty on success.
+	Err string `json:"err,omitempty"`
+}
+
+// DepthStats is one recursion depth's slice of RLMStats.PerDepth: call
+// counts, token totals, and latency percentiles across every CallRecord at
+// that depth.
+type DepthStats struct {
+	Depth            int   `json:"depth"`
+	Calls            int   `json:"calls"`
+	PromptTokens     int   `json:"prompt_tokens"`
+	CompletionTokens int   `json:"completion_tokens"`
+	REPLErrors       int   `json:"repl_errors,omitempty"`
+	LatencyP50Ms     int64 `json:"latency_p50_ms"`
+	LatencyP95Ms     int64 `json:"latency_p95_ms"`
+}
+
+// depthStatsFromCalls aggregates calls into one DepthStats per distinct
+// Depth value, sorted by depth ascending.
+func depthStatsFromCalls(calls []CallRecord) []DepthStats {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	byDepth := map[int][]CallRecord{}
+	for _, c := range calls {
+		byDepth[c.Depth] = append(byDepth[c.Depth], c)
+	}
+
+	depths := make([]int, 0, len(byDepth))
+	for d := range byDepth {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+
+	result := make([]DepthStats, 0, len(depths))
+	for _, d := range depths {
+		group := byDepth[d]
+		stat := DepthStats{Depth: d, Calls: len(group)}
+
+		latencies := make([]int64, len(group))
+		for i, c := range group {
+			stat.PromptTokens += c.PromptTokens
+			stat.CompletionTokens += c.CompletionTokens
+			stat.REPLErrors += c.REPLErrors
+			latencies[i] = c.LatencyMs
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stat.LatencyP50Ms = percentile(latencies, 0.50)
+		stat.LatencyP95Ms = percentile(latencies, 0.95)
+
+		result = append(result, stat)
+	}
+	return result
+}
+
+// percentile returns the p-th percentile (0..1) of sorted: the element at
+// index int(p*len(sorted)), clamped to the last element. sorted must
+// already be sorted ascending.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p * float64(len(sorted)))
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// NodeStat reports how long a single SubTask took in a parallel structured
+// decomposition, so callers can see which fields (or dependency chains) are
+// the bottleneck.
+type NodeStat struct {
+	TaskID     string `json:"task_id"`
+	DurationMs int64  `json:"duration_ms"`
+	LlmCalls   int    `json:"llm_calls"`
 }
 
 type JSONSchema struct {
-	Type                 string                 `json:"type"`
-	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
-	Items                *JSONSchema            `json:"items,omitempty"`
-	Required             []string               `json:"required,omitempty"`
-	Enum                 []string               `json:"enum,omitempty"`
-	Nullable             bool                   `json:"nullable,omitempty"`
+	Type        string                 `json:"type"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Const       interface{}            `json:"const,omitempty"`
+	Nullable    bool                   `json:"nullable,omitempty"`
+	Description string                 `json:"description,omitempty"`
 	// Number constraints
-	Minimum              *float64               `json:"minimum,omitempty"`
-	Maximum              *float64               `json:"maximum,omitempty"`
-	MultipleOf           *float64               `json:"multipleOf,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
 	// String constraints
-	MinLength            *int                   `json:"minLength,omitempty"`
-	MaxLength            *int                   `json:"maxLength,omitempty"`
-	Pattern              string                 `json:"pattern,omitempty"`
-	Format               string                 `json:"format,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	Format    string `json:"format,omitempty"`
 	// Array constraints
-	MinItems             *int                   `json:"minItems,omitempty"`
-	MaxItems             *int                   `json:"maxItems,omitempty"`
-	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	MinItems    *int `json:"minItems,omitempty"`
+	MaxItems    *int `json:"maxItems,omitempty"`
+	UniqueItems bool `json:"uniqueItems,omitempty"`
+	// PrefixItems validates a tuple: the array's element at index i must
+	// satisfy PrefixItems[i], for as many positions as PrefixItems has
+	// entries. Items still validates any element beyond len(PrefixItems),
+	// the same role it plays for an ordinary (non-tuple) array.
+	PrefixItems []*JSONSchema `json:"prefixItems,omitempty"`
 	// Object constraints
-	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	MinProperties *int `json:"minProperties,omitempty"`
+	MaxProperties *int `json:"maxProperties,omitempty"`
+	// DependentRequired maps a property name to the sibling properties that
+	// must also be present whenever it is, e.g. {"creditCard": ["billingAddress"]}
+	// - distinct from JSONSchema.DependsOn, which governs structured-output
+	// sub-task scheduling rather than validation.
+	DependentRequired map[string][]string `json:"dependentRequired,omitempty"`
+	// AdditionalProperties is either a bool (allow/disallow any extra key) or
+	// a *JSONSchema every extra key's value must satisfy, matching Draft-07's
+	// overload of the keyword.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	// PatternProperties maps a regexp (as a string, since JSON Schema itself
+	// has no "regexp type") to the schema every matching key's value must
+	// satisfy, checked independently of Properties/AdditionalProperties.
+	PatternProperties map[string]*JSONSchema `json:"patternProperties,omitempty"`
 	// Union/Intersection
-	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
-	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+	AllOf []*JSONSchema `json:"allOf,omitempty"`
+	OneOf []*JSONSchema `json:"oneOf,omitempty"`
+	Not   *JSONSchema   `json:"not,omitempty"`
+	// $ref support: Ref points at a key in the root schema's Definitions,
+	// e.g. "#/definitions/PaymentEvent" or "#/$defs/PaymentEvent".
+	Definitions map[string]*JSONSchema `json:"definitions,omitempty"`
+	Ref         string                 `json:"$ref,omitempty"`
+	// DependsOn is a non-standard extension keyword: when set on a field's
+	// schema, decomposeSchema schedules that field's sub-task only after the
+	// named sibling fields' sub-tasks have resolved, and surfaces their
+	// values to it as extraction context. Names are sibling property names
+	// (e.g. "entities"), not task IDs.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Example carries a sample value for this schema, as lowered from an
+	// OpenAPI "example" keyword by SchemaFromOpenAPI. MetaAgent.OptimizeForStructured
+	// surfaces these as concrete extraction hints when present.
+	Example interface{} `json:"example,omitempty"`
+	// Discriminator carries an OpenAPI discriminator object lowered onto a
+	// OneOf/AnyOf schema by SchemaFromOpenAPI: which sibling property
+	// selects the branch, and an optional value-to-component-name mapping.
+	// It's metadata only - validate.go doesn't use it to pick a branch.
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
+	// Dialect records this schema's "$schema" declaration (e.g.
+	// "https://json-schema.org/draft/2020-12/schema"), round-tripped for
+	// callers that care which draft authored a document. walkSchema doesn't
+	// branch on it: this package validates against one superset of
+	// draft-07/2019-09/2020-12 plus the OpenAPI 3.1 additions
+	// (nullable/example/discriminator) already lowered onto first-class
+	// fields above, so every dialect is accepted uniformly rather than
+	// switched on.
+	Dialect string `json:"$schema,omitempty"`
+	// VendorExtensions holds any "x-"-prefixed keyword found on this schema
+	// that isn't one of the fields above, e.g. a domain-specific
+	// "x-llm-retry-hint". Populated by UnmarshalJSON. See RegisterKeyword
+	// (keywords.go) for how to evaluate one of these during validation.
+	VendorExtensions map[string]interface{} `json:"-"`
+}
+
+// jsonSchemaAlias lets UnmarshalJSON/MarshalJSON decode/encode every
+// declared JSONSchema field through encoding/json's normal struct handling
+// without recursing into themselves.
+type jsonSchemaAlias JSONSchema
+
+// UnmarshalJSON decodes the declared fields as usual, then makes a second
+// pass over the raw object collecting any "x-"-prefixed key into
+// VendorExtensions, so a vendor keyword survives a schema fetched over the
+// wire (e.g. from SchemaFromOpenAPI's source document) even though it has no
+// dedicated Go field.
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	var alias jsonSchemaAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*s = JSONSchema(alias)
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, val := range raw {
+		if !strings.HasPrefix(key, "x-") {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(val, &v); err != nil {
+			continue
+		}
+		if s.VendorExtensions == nil {
+			s.VendorExtensions = map[string]interface{}{}
+		}
+		s.VendorExtensions[key] = v
+	}
+	return nil
+}
+
+// MarshalJSON encodes the declared fields as usual, then merges
+// VendorExtensions' keys back in, so a vendor keyword round-trips through a
+// schema sent back out (e.g. embedded in a structured-completion prompt).
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	declared, err := json.Marshal(jsonSchemaAlias(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(s.VendorExtensions) == 0 {
+		return declared, nil
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(declared, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range s.VendorExtensions {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = encoded
+	}
+	return json.Marshal(merged)
+}
+
+// Discriminator is an OpenAPI 3 discriminator object, lowered onto
+// JSONSchema.Discriminator by SchemaFromOpenAPI.
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
 }
 
 type SubTask struct {
@@ -51,21 +306,181 @@ type StructuredConfig struct {
 	Schema            *JSONSchema
 	ParallelExecution bool
 	MaxRetries        int
+	// MaxConcurrency bounds how many SubTasks structuredCompletionParallel
+	// (and its streaming sibling) run at once within a single topological
+	// wave. 0 means unbounded - every task in the wave runs concurrently, as
+	// before this field existed.
+	MaxConcurrency int
+	// Decomposer overrides the default field-per-subtask decomposeSchema
+	// with caller-supplied logic, e.g. to group fields into coarser
+	// sub-tasks or to derive dependencies from something other than
+	// JSONSchema.DependsOn.
+	Decomposer SchemaDecomposer
+	// Dependencies is a programmatic alternative to JSONSchema.DependsOn,
+	// keyed by top-level field name (the same convention as
+	// EnforcementActions) and naming the sibling fields that must resolve
+	// first. Entries here are merged with (not a replacement for) any
+	// DependsOn declared on the field's own schema, for callers who'd rather
+	// keep scheduling concerns out of the schema itself.
+	Dependencies map[string][]string
+	// ValidatorBackend overrides DefaultValidatorBackend for this call, e.g.
+	// to validate with GoogleValidatorBackend instead of the package's own
+	// hand-rolled walker, or a caller-supplied SchemaValidatorBackend.
+	ValidatorBackend SchemaValidatorBackend
+	// EnforcementActions scopes how schema violations are handled, keyed by
+	// top-level field name (the same convention as JSONSchema.DependsOn),
+	// instead of retrying the whole document on any violation. A field
+	// absent from this map defaults to ActionDeny, the package's original
+	// all-or-nothing retry behavior. See Action's doc comment for what each
+	// value does.
+	EnforcementActions map[string]Action
+	// GrammarField overrides the extra param key the compiled GBNF grammar
+	// is sent under (see structuredGrammarExtra), for backends that expect
+	// their grammar constraint under something other than "grammar". Empty
+	// keeps the llama.cpp/LocalAI default.
+	GrammarField string
+	// UseResponseFormat switches structuredCompletionDirect from the
+	// parse-validate-retry loop to a provider's native structured-output
+	// surface (see ProviderMode and structuredResponseFormatExtra), which a
+	// provider like OpenAI guarantees returns schema-conformant JSON on the
+	// first attempt rather than merely being instructed to via the prompt.
+	UseResponseFormat bool
+	// SchemaName names the schema on providers whose structured-output wire
+	// format requires one - OpenAI's response_format.json_schema.name, or
+	// the synthetic tool name Anthropic sees under ProviderModeAnthropicToolUse.
+	// Defaults to "response".
+	SchemaName string
+	// Strict requests a provider's strictest structured-output guarantee
+	// where one exists (OpenAI's response_format.json_schema.strict).
+	Strict bool
+	// ProviderMode picks which provider-native surface UseResponseFormat
+	// routes the schema to; empty defaults to ProviderModeOpenAI.
+	ProviderMode ProviderMode
+	// Mode picks how structuredCompletionDirectContext gets schema-conformant
+	// JSON out of the backend. Empty falls back to the original
+	// UseResponseFormat/ProviderMode knobs above, so existing callers are
+	// unaffected; set it explicitly to opt into ModeNativeJSON/
+	// ModeNativeSchema/ModeAuto without also setting UseResponseFormat.
+	Mode StructuredMode
+	// StabilizationWindow is how long structuredCompletionParallelStream
+	// waits, after the last onValue report for a streaming sub-task's field,
+	// before assuming the field's value has stabilized and canceling that
+	// sub-task's in-flight LLM call early - useful for a long array field
+	// whose items stop growing well before the model emits its closing
+	// tokens. 0 disables early cancellation; sub-tasks always run to
+	// completion, as before this field existed.
+	StabilizationWindow time.Duration
+}
+
+// StructuredMode selects how a structured completion gets schema-conformant
+// JSON out of the backend.
+type StructuredMode string
+
+const (
+	// ModePrompt relies entirely on prompt engineering and the
+	// parse-validate-retry loop - no native structured-output surface is
+	// used, even if UseResponseFormat/ProviderMode are also set.
+	ModePrompt StructuredMode = "prompt"
+	// ModeNativeJSON requests a provider's bare "valid JSON" guarantee (no
+	// schema attached) where one exists, e.g. OpenAI's
+	// response_format: {type: "json_object"}. The retry loop stays active,
+	// since valid-JSON is a weaker guarantee than schema-conformant.
+	ModeNativeJSON StructuredMode = "native_json"
+	// ModeNativeSchema pushes the full *JSONSchema to the provider's native
+	// structured-output surface via structuredResponseFormatExtra (the same
+	// route UseResponseFormat/ProviderMode already take).
+	ModeNativeSchema StructuredMode = "native_schema"
+	// ModeAuto probes r.backend's LLMProvider capability once (caching the
+	// result per backend value) and picks the strongest mode it supports:
+	// ModeNativeSchema, then ModeNativeJSON, then ModePrompt.
+	ModeAuto StructuredMode = "auto"
+)
+
+// StructuredCapability describes how much native structured-output support a
+// backend's provider offers, as reported by LLMProvider.
+type StructuredCapability struct {
+	JSON   bool // supports a bare "return valid JSON, no schema" mode
+	Schema bool // supports pushing a full *JSONSchema for conformant output
+	// ProviderMode is which ProviderMode JSON/Schema above refer to - the
+	// mode ModeAuto should route to when config.ProviderMode isn't already
+	// set explicitly. Left empty by a backend with neither capability.
+	ProviderMode ProviderMode
+}
+
+// LLMProvider is an optional capability interface a Backend can implement
+// (alongside ConstrainedDecoder/StreamingBackend) to advertise native
+// structured-output support, so ModeAuto doesn't have to guess it from
+// ProviderMode alone.
+type LLMProvider interface {
+	SupportsStructuredOutput() StructuredCapability
+}
+
+// ProviderMode selects which provider-native surface a structured
+// completion's schema is routed to when StructuredConfig.UseResponseFormat
+// is set - "pass a JSON Schema, get conformant JSON back" is a different
+// wire field on every provider.
+type ProviderMode string
+
+const (
+	// ProviderModeOpenAI sends response_format: {type: "json_schema", ...}.
+	ProviderModeOpenAI ProviderMode = "openai"
+	// ProviderModeGemini sends generationConfig.responseSchema.
+	ProviderModeGemini ProviderMode = "gemini"
+	// ProviderModeAnthropicToolUse forces the model to call a synthetic
+	// tool whose input_schema is the target schema, since Anthropic has no
+	// dedicated structured-output field.
+	ProviderModeAnthropicToolUse ProviderMode = "anthropic_tool_use"
+	// ProviderModeLlamaCppGrammar delegates to the GBNF grammar path
+	// (structuredGrammarExtra) instead of a response_format-style field.
+	ProviderModeLlamaCppGrammar ProviderMode = "llamacpp_grammar"
+	// ProviderModeNone disables UseResponseFormat's routing entirely,
+	// falling back to the ordinary parse-validate-retry loop.
+	ProviderModeNone ProviderMode = "none"
+)
+
+// SchemaDecomposer splits a schema into the SubTasks StructuredCompletion's
+// parallel path executes. decomposeSchema is the default implementation;
+// set StructuredConfig.Decomposer to replace it.
+type SchemaDecomposer interface {
+	Decompose(schema *JSONSchema) []SubTask
 }
 
 type Config struct {
-	RecursiveModel    string
-	APIBase           string
-	APIKey            string
-	MaxDepth          int
-	MaxIterations     int
-	TimeoutSeconds    int
-	Parallel          bool // Enable parallel recursive calls with goroutines
-	UseMetacognitive  bool // Enable step-by-step reasoning guidance in prompts
-	Structured        *StructuredConfig
-	ExtraParams       map[string]interface{}
-	MetaAgent         *MetaAgentConfig
-	Observability     *ObservabilityConfig
+	RecursiveModel   string
+	APIBase          string
+	APIKey           string
+	MaxDepth         int
+	MaxIterations    int
+	MaxTokens        int // Token budget across a Completion call; 0 = unbounded
+	TimeoutSeconds   int // Per-backend-request HTTP timeout; 0 = backend default
+	TimeoutMs        int // Overall deadline for a CompletionContext/StructuredCompletionContext call, as a context.WithTimeout; 0 = no deadline
+	Parallel         bool // Enable parallel recursive calls with goroutines
+	UseMetacognitive bool // Enable step-by-step reasoning guidance in prompts
+	Structured       *StructuredConfig
+	ExtraParams      map[string]interface{}
+	MetaAgent        *MetaAgentConfig
+	Observability    *ObservabilityConfig
+	ToolRegistry     *ToolRegistry   // Tools the model may invoke via TOOL("name", {...}) instead of REPL code
+	Backend          Backend         // Explicit backend; overrides Provider and model-prefix inference
+	Provider         string          // "openai" (default), "anthropic", "gemini", "ollama", "grpc", or a RegisterProvider-registered name; overrides model-prefix inference
+	MetricsObserver  MetricsObserver // Lifecycle hooks for metrics backends; defaults to a no-op
+	// Retry configures backoff/retry behavior for every LLM call this RLM
+	// makes (including recursive_llm sub-calls, which inherit it along with
+	// the rest of the parent's backend). Nil (the default) preserves the
+	// original single-attempt behavior. See RetryPolicy's fields and
+	// withRetry for how it's applied per backend.
+	Retry *RetryPolicy
+	// DetailedStats enables per-call sampling (RLMStats.Calls) and the
+	// derived RLMStats.PerDepth breakdown in the main CompletionContext
+	// agentic loop. Off by default so cheap runs don't pay the bookkeeping
+	// cost; turn it on when you need per-depth latency percentiles or a
+	// call-by-call audit trail rather than just the running totals.
+	DetailedStats bool
+	// Sandbox, when non-nil, is applied to both the JS and Python REPL
+	// executors this RLM constructs (see NewSandboxedREPLExecutor and
+	// NewSandboxedPythonREPLExecutor). Nil (the default) preserves the
+	// original no-limits REPLExecutor behavior.
+	Sandbox *SandboxConfig
 }
 
 func ConfigFromMap(config map[string]interface{}) Config {
@@ -98,6 +513,12 @@ func ConfigFromMap(config map[string]interface{}) Config {
 		if v, ok := toInt(maConfig["max_optimize_len"]); ok {
 			ma.MaxOptimizeLen = v
 		}
+		if v, ok := toInt(maConfig["cache_ttl_seconds"]); ok {
+			ma.CacheTTL = time.Duration(v) * time.Second
+		}
+		if v, ok := toFloat(maConfig["similarity_threshold"]); ok {
+			ma.SimilarityThreshold = v
+		}
 		parsed.MetaAgent = ma
 	}
 
@@ -109,6 +530,16 @@ func ConfigFromMap(config map[string]interface{}) Config {
 			parsed.APIBase = toString(value)
 		case "api_key":
 			parsed.APIKey = toString(value)
+		case "provider":
+			parsed.Provider = toString(value)
+		case "backend":
+			// "backend" is the bridge-facing name for the same dispatch
+			// "provider" drives (resolveBackend, RegisterProvider): "http"
+			// selects the default openAIBackend, "grpc" or a
+			// RegisterProvider-registered name selects that Backend.
+			if name := toString(value); name != "http" {
+				parsed.Provider = name
+			}
 		case "max_depth":
 			if v, ok := toInt(value); ok {
 				parsed.MaxDepth = v
@@ -117,10 +548,18 @@ func ConfigFromMap(config map[string]interface{}) Config {
 			if v, ok := toInt(value); ok {
 				parsed.MaxIterations = v
 			}
+		case "max_tokens":
+			if v, ok := toInt(value); ok {
+				parsed.MaxTokens = v
+			}
 		case "timeout":
 			if v, ok := toInt(value); ok {
 				parsed.TimeoutSeconds = v
 			}
+		case "timeout_ms":
+			if v, ok := toInt(value); ok {
+				parsed.TimeoutMs = v
+			}
 		case "parallel":
 			if v, ok := value.(bool); ok {
 				parsed.Parallel = v
@@ -177,3 +616,23 @@ func toInt(value interface{}) (int, bool) {
 
 	return 0, false
 }
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			return parsed, true
+		}
+	}
+
+	return 0, false
+}
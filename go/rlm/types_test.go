@@ -0,0 +1,88 @@
+package rlm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDepthStatsFromCalls_AggregatesPerDepth(t *testing.T) {
+	calls := []CallRecord{
+		{Depth: 0, PromptTokens: 10, CompletionTokens: 5, LatencyMs: 100},
+		{Depth: 0, PromptTokens: 20, CompletionTokens: 5, LatencyMs: 200, REPLErrors: 1},
+		{Depth: 1, PromptTokens: 7, CompletionTokens: 3, LatencyMs: 50},
+	}
+
+	got := depthStatsFromCalls(calls)
+	if len(got) != 2 {
+		t.Fatalf("depthStatsFromCalls() returned %d entries, want 2", len(got))
+	}
+
+	if got[0].Depth != 0 || got[0].Calls != 2 || got[0].PromptTokens != 30 || got[0].CompletionTokens != 10 || got[0].REPLErrors != 1 {
+		t.Errorf("depthStatsFromCalls()[0] = %+v, want depth 0 totals", got[0])
+	}
+	if got[0].LatencyP50Ms != 200 || got[0].LatencyP95Ms != 200 {
+		t.Errorf("depthStatsFromCalls()[0] latencies = p50=%d p95=%d, want 200/200", got[0].LatencyP50Ms, got[0].LatencyP95Ms)
+	}
+
+	if got[1].Depth != 1 || got[1].Calls != 1 {
+		t.Errorf("depthStatsFromCalls()[1] = %+v, want depth 1 with 1 call", got[1])
+	}
+}
+
+func TestDepthStatsFromCalls_EmptyReturnsNil(t *testing.T) {
+	if got := depthStatsFromCalls(nil); got != nil {
+		t.Errorf("depthStatsFromCalls(nil) = %+v, want nil", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []int64{10, 20, 30, 40, 50}
+	if got := percentile(sorted, 0.50); got != 30 {
+		t.Errorf("percentile(0.50) = %d, want 30", got)
+	}
+	if got := percentile(sorted, 0.95); got != 50 {
+		t.Errorf("percentile(0.95) = %d, want 50", got)
+	}
+}
+
+func TestCompletionContext_DetailedStatsRecordsCalls(t *testing.T) {
+	r := New("gpt-4o", Config{
+		Backend:       constantBackend{content: `FINAL("done")`},
+		MaxDepth:      5,
+		MaxIterations: 5,
+		DetailedStats: true,
+	})
+
+	answer, stats, err := r.CompletionContext(context.Background(), "q", "")
+	if err != nil {
+		t.Fatalf("CompletionContext() error = %v", err)
+	}
+	if answer != "done" {
+		t.Fatalf("CompletionContext() answer = %q, want %q", answer, "done")
+	}
+
+	if len(stats.Calls) != 1 {
+		t.Fatalf("stats.Calls = %+v, want 1 entry", stats.Calls)
+	}
+	if stats.Calls[0].Model != "gpt-4o" {
+		t.Errorf("stats.Calls[0].Model = %q, want %q", stats.Calls[0].Model, "gpt-4o")
+	}
+	if len(stats.PerDepth) != 1 || stats.PerDepth[0].Calls != 1 {
+		t.Errorf("stats.PerDepth = %+v, want a single depth-0 entry with 1 call", stats.PerDepth)
+	}
+}
+
+func TestCompletionContext_WithoutDetailedStatsLeavesCallsEmpty(t *testing.T) {
+	r := New("gpt-4o", Config{
+		Backend:       constantBackend{content: `FINAL("done")`},
+		MaxDepth:      5,
+		MaxIterations: 5,
+	})
+
+	if _, _, err := r.CompletionContext(context.Background(), "q", ""); err != nil {
+		t.Fatalf("CompletionContext() error = %v", err)
+	}
+	if len(r.stats.Calls) != 0 || len(r.stats.PerDepth) != 0 {
+		t.Errorf("stats.Calls/PerDepth should stay empty without DetailedStats, got %+v / %+v", r.stats.Calls, r.stats.PerDepth)
+	}
+}
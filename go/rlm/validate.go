@@ -0,0 +1,731 @@
+package rlm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValidationError describes a single schema violation at a specific location
+// in the document, identified by a JSON-pointer path (e.g.
+// "/items/3/address/zip").
+type ValidationError struct {
+	Path     string
+	Keyword  string
+	Expected string
+	Got      string
+	Message  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationResult collects every violation found while walking a schema, so
+// callers (and the retry-feedback builder) can report all of them at once
+// instead of stopping at the first.
+type ValidationResult struct {
+	Errors []ValidationError
+
+	// depth and depthExceeded track walkSchemaSeen's current nesting level
+	// against MaxNestedValidationDepth, so a pathological deeply-nested
+	// value or schema fails with a MaxNestedDepthError instead of
+	// recursing until the goroutine stack overflows.
+	depth         int
+	depthExceeded bool
+}
+
+// MaxNestedValidationDepth bounds how many levels deep walkSchemaSeen will
+// recurse through nested object/array values (and schema branches -
+// allOf/anyOf/oneOf/not, $ref, patternProperties/additionalProperties
+// sub-schemas) before giving up and reporting a *MaxNestedDepthError instead
+// of risking a stack overflow on a pathological, deeply-nested LLM response.
+// 32 comfortably covers realistic schemas; raise it if a caller legitimately
+// validates documents nested deeper than that.
+var MaxNestedValidationDepth = 32
+
+// Valid reports whether the document had no violations.
+func (r *ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *ValidationResult) Error() string {
+	if r.Valid() {
+		return ""
+	}
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (r *ValidationResult) add(path, keyword, expected, got, message string) {
+	r.Errors = append(r.Errors, ValidationError{
+		Path:     path,
+		Keyword:  keyword,
+		Expected: expected,
+		Got:      got,
+		Message:  message,
+	})
+}
+
+// validateAgainstSchema validates data against a JSON schema, returning a
+// *ValidationResult (as an error) describing every violation found, or a
+// *MaxNestedDepthError if the document nests deeper than
+// MaxNestedValidationDepth.
+func validateAgainstSchema(data map[string]interface{}, schema *JSONSchema) error {
+	result := &ValidationResult{}
+	walkSchema("", data, schema, schema, result)
+	if result.depthExceeded {
+		return NewMaxNestedDepthError(MaxNestedValidationDepth)
+	}
+	if result.Valid() {
+		return nil
+	}
+	return result
+}
+
+// validateValue validates a single value against a schema, returning a
+// *ValidationResult (as an error) describing every violation found, or a
+// *MaxNestedDepthError if the document nests deeper than
+// MaxNestedValidationDepth.
+func validateValue(value interface{}, schema *JSONSchema) error {
+	result := &ValidationResult{}
+	walkSchema("", value, schema, schema, result)
+	if result.depthExceeded {
+		return NewMaxNestedDepthError(MaxNestedValidationDepth)
+	}
+	if result.Valid() {
+		return nil
+	}
+	return result
+}
+
+// walkSchema recursively validates value against schema, appending every
+// violation it finds to result under a JSON-pointer path rooted at path.
+// root is the outermost schema passed to validateAgainstSchema/validateValue,
+// carried through the recursion so $ref can resolve against its Definitions.
+func walkSchema(path string, value interface{}, schema *JSONSchema, root *JSONSchema, result *ValidationResult) {
+	walkSchemaSeen(path, value, schema, root, result, map[string]bool{})
+}
+
+// walkSchemaSeen is walkSchema's real implementation, threading seen - the
+// set of $ref names already resolved along the current root-to-here path -
+// so a cyclic Definitions entry (e.g. a self-referential tree schema) is
+// reported as a validation error instead of recursing forever. seen is
+// cloned (not mutated in place) before a $ref resolution so sibling branches
+// that happen to reference the same definition don't falsely trip the cycle
+// check.
+func walkSchemaSeen(path string, value interface{}, schema *JSONSchema, root *JSONSchema, result *ValidationResult, seen map[string]bool) {
+	if schema == nil {
+		return
+	}
+
+	if result.depthExceeded {
+		return
+	}
+	result.depth++
+	defer func() { result.depth-- }()
+	if result.depth > MaxNestedValidationDepth {
+		result.depthExceeded = true
+		return
+	}
+
+	if schema.Ref != "" {
+		if seen[schema.Ref] {
+			result.add(pointerOrRoot(path), "$ref", schema.Ref, "cycle", fmt.Sprintf("$ref %q forms a cycle", schema.Ref))
+			return
+		}
+		resolved := resolveRef(schema.Ref, root)
+		if resolved == nil {
+			result.add(pointerOrRoot(path), "$ref", schema.Ref, "unresolved", fmt.Sprintf("could not resolve $ref %q", schema.Ref))
+			return
+		}
+		next := make(map[string]bool, len(seen)+1)
+		for k := range seen {
+			next[k] = true
+		}
+		next[schema.Ref] = true
+		seen = next
+		schema = resolved
+	}
+
+	runKeywordHandlers(path, value, schema, result)
+
+	if value == nil {
+		if schema.Nullable {
+			return
+		}
+		if schema.Type != "" && schema.Type != "null" {
+			result.add(pointerOrRoot(path), "type", schema.Type, "null", fmt.Sprintf("expected %s, got null", schema.Type))
+			return
+		}
+	}
+
+	switch schema.Type {
+	case "string":
+		walkString(path, value, schema, result)
+	case "number", "integer":
+		walkNumber(path, value, schema, result)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			result.add(pointerOrRoot(path), "type", "boolean", fmt.Sprintf("%T", value), fmt.Sprintf("expected boolean, got %T", value))
+		}
+	case "array":
+		walkArray(path, value, schema, root, result, seen)
+	case "object":
+		walkObject(path, value, schema, root, result, seen)
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		result.add(pointerOrRoot(path), "enum", strings.Join(schema.Enum, ", "), fmt.Sprintf("%v", value), fmt.Sprintf("value %v is not one of the allowed values: %s", value, strings.Join(schema.Enum, ", ")))
+	}
+
+	if schema.Const != nil && !reflect.DeepEqual(schema.Const, value) {
+		result.add(pointerOrRoot(path), "const", fmt.Sprintf("%v", schema.Const), fmt.Sprintf("%v", value), fmt.Sprintf("value %v does not equal const %v", value, schema.Const))
+	}
+
+	for _, sub := range schema.AllOf {
+		walkSchemaSeen(path, value, sub, root, result, seen)
+	}
+
+	if len(schema.AnyOf) > 0 && !anyBranchMatches(path, value, schema.AnyOf, root, seen, result.depth) {
+		result.add(pointerOrRoot(path), "anyOf", "at least one branch to match", fmt.Sprintf("%v", value), "value did not match any of the anyOf schemas")
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, sub := range schema.OneOf {
+			branch := &ValidationResult{depth: result.depth}
+			walkSchemaSeen(path, value, sub, root, branch, seen)
+			if branch.depthExceeded {
+				result.depthExceeded = true
+				return
+			}
+			if branch.Valid() {
+				matches++
+			}
+		}
+		if matches != 1 {
+			result.add(pointerOrRoot(path), "oneOf", "exactly one branch to match", fmt.Sprintf("%d matched", matches), fmt.Sprintf("value matched %d of the oneOf branches, want exactly 1", matches))
+		}
+	}
+
+	if schema.Not != nil {
+		branch := &ValidationResult{depth: result.depth}
+		walkSchemaSeen(path, value, schema.Not, root, branch, seen)
+		if branch.depthExceeded {
+			result.depthExceeded = true
+			return
+		}
+		if branch.Valid() {
+			result.add(pointerOrRoot(path), "not", "schema to fail", "schema matched", "value must not match the \"not\" schema")
+		}
+	}
+}
+
+// anyBranchMatches reports whether value validates against at least one of
+// branches. depth seeds each branch's ValidationResult so MaxNestedValidationDepth
+// is enforced across the branch boundary, not reset to 0 per branch.
+func anyBranchMatches(path string, value interface{}, branches []*JSONSchema, root *JSONSchema, seen map[string]bool, depth int) bool {
+	for _, sub := range branches {
+		branch := &ValidationResult{depth: depth}
+		walkSchemaSeen(path, value, sub, root, branch, seen)
+		if branch.Valid() {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRef looks up a "#/definitions/Name" or "#/$defs/Name" pointer
+// against root's Definitions map, or - for a "registry:Name" ref - against
+// DefaultSchemaRegistry, so a sub-schema pre-registered once can be
+// referenced from any schema's $ref without duplicating it into that
+// schema's own Definitions.
+func resolveRef(ref string, root *JSONSchema) *JSONSchema {
+	if name, ok := strings.CutPrefix(ref, "registry:"); ok {
+		schema, _ := DefaultSchemaRegistry.Get(name)
+		return schema
+	}
+
+	if root == nil {
+		return nil
+	}
+	name := ref
+	for _, prefix := range []string{"#/definitions/", "#/$defs/"} {
+		if strings.HasPrefix(ref, prefix) {
+			name = strings.TrimPrefix(ref, prefix)
+			break
+		}
+	}
+	return root.Definitions[name]
+}
+
+// SchemaRegistry lets callers pre-register named sub-schemas once (e.g. at
+// package init) and reference them by name from any schema's $ref as
+// "registry:Name", instead of duplicating the same sub-schema inline into
+// every schema's own Definitions. Safe for concurrent use.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*JSONSchema
+}
+
+// NewSchemaRegistry returns an empty SchemaRegistry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]*JSONSchema)}
+}
+
+// Register adds (or overwrites) name's schema in the registry.
+func (reg *SchemaRegistry) Register(name string, schema *JSONSchema) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.schemas[name] = schema
+}
+
+// Get returns name's registered schema, and whether one was found.
+func (reg *SchemaRegistry) Get(name string) (*JSONSchema, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	schema, ok := reg.schemas[name]
+	return schema, ok
+}
+
+// DefaultSchemaRegistry is the process-wide SchemaRegistry resolveRef
+// consults for "registry:Name" refs. RegisterFormatChecker and
+// RegisterDefaultFormat follow the same process-wide-registry-plus-
+// per-instance-override shape for format checkers.
+var DefaultSchemaRegistry = NewSchemaRegistry()
+
+func walkString(path string, value interface{}, schema *JSONSchema, result *ValidationResult) {
+	s, ok := value.(string)
+	if !ok {
+		result.add(pointerOrRoot(path), "type", "string", fmt.Sprintf("%T", value), fmt.Sprintf("expected string, got %T", value))
+		return
+	}
+
+	if schema.MinLength != nil && len(s) < *schema.MinLength {
+		result.add(pointerOrRoot(path), "minLength", fmt.Sprintf("%d", *schema.MinLength), fmt.Sprintf("%d", len(s)), fmt.Sprintf("string length %d is less than minLength %d", len(s), *schema.MinLength))
+	}
+	if schema.MaxLength != nil && len(s) > *schema.MaxLength {
+		result.add(pointerOrRoot(path), "maxLength", fmt.Sprintf("%d", *schema.MaxLength), fmt.Sprintf("%d", len(s)), fmt.Sprintf("string length %d is greater than maxLength %d", len(s), *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err == nil && !re.MatchString(s) {
+			result.add(pointerOrRoot(path), "pattern", schema.Pattern, s, fmt.Sprintf("value %q does not match pattern %q", s, schema.Pattern))
+		}
+	}
+	if schema.Format != "" {
+		if checker, ok := lookupFormatChecker(schema.Format); ok && !checker(s) {
+			result.add(pointerOrRoot(path), "format", schema.Format, s, fmt.Sprintf("value %q does not match format %q", s, schema.Format))
+		}
+	}
+}
+
+func walkNumber(path string, value interface{}, schema *JSONSchema, result *ValidationResult) {
+	num, ok := toFloat64(value)
+	if !ok {
+		result.add(pointerOrRoot(path), "type", schema.Type, fmt.Sprintf("%T", value), fmt.Sprintf("expected %s, got %T", schema.Type, value))
+		return
+	}
+
+	if schema.Type == "integer" && num != float64(int64(num)) {
+		result.add(pointerOrRoot(path), "type", "integer", fmt.Sprintf("%v", num), fmt.Sprintf("expected integer, got non-integer number %v", num))
+	}
+	if schema.Minimum != nil && num < *schema.Minimum {
+		result.add(pointerOrRoot(path), "minimum", fmt.Sprintf("%v", *schema.Minimum), fmt.Sprintf("%v", num), fmt.Sprintf("value %v is less than minimum %v", num, *schema.Minimum))
+	}
+	if schema.Maximum != nil && num > *schema.Maximum {
+		result.add(pointerOrRoot(path), "maximum", fmt.Sprintf("%v", *schema.Maximum), fmt.Sprintf("%v", num), fmt.Sprintf("value %v is greater than maximum %v", num, *schema.Maximum))
+	}
+	if schema.ExclusiveMinimum != nil && num <= *schema.ExclusiveMinimum {
+		result.add(pointerOrRoot(path), "exclusiveMinimum", fmt.Sprintf("%v", *schema.ExclusiveMinimum), fmt.Sprintf("%v", num), fmt.Sprintf("value %v is not greater than exclusiveMinimum %v", num, *schema.ExclusiveMinimum))
+	}
+	if schema.ExclusiveMaximum != nil && num >= *schema.ExclusiveMaximum {
+		result.add(pointerOrRoot(path), "exclusiveMaximum", fmt.Sprintf("%v", *schema.ExclusiveMaximum), fmt.Sprintf("%v", num), fmt.Sprintf("value %v is not less than exclusiveMaximum %v", num, *schema.ExclusiveMaximum))
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf != 0 && !isMultipleOf(num, *schema.MultipleOf) {
+		result.add(pointerOrRoot(path), "multipleOf", fmt.Sprintf("%v", *schema.MultipleOf), fmt.Sprintf("%v", num), fmt.Sprintf("value %v is not a multiple of %v", num, *schema.MultipleOf))
+	}
+}
+
+// ValidatePartial runs a relaxed variant of validateAgainstSchema suited to
+// an in-progress document that hasn't finished streaming: every field
+// actually present in data is still checked against its full set of
+// constraints, but "required" is not enforced anywhere in the tree, since a
+// field legitimately may not have arrived yet. Callers consuming
+// StructuredCompletionStream's partial snapshots should call this after each
+// flush and run the ordinary validateAgainstSchema once streaming reaches
+// EOF, to catch fields that never showed up at all.
+func ValidatePartial(data map[string]interface{}, schema *JSONSchema) error {
+	relaxed := withoutRequired(schema)
+	result := &ValidationResult{}
+	walkSchema("", data, relaxed, relaxed, result)
+	if result.depthExceeded {
+		return NewMaxNestedDepthError(MaxNestedValidationDepth)
+	}
+	if result.Valid() {
+		return nil
+	}
+	return result
+}
+
+// withoutRequired deep-clones schema with every "required" keyword in the
+// tree cleared, short-circuiting on schemas already visited (via the pointer
+// identity map) so a cyclic $ref chain clones in finite time.
+func withoutRequired(schema *JSONSchema) *JSONSchema {
+	return cloneWithoutRequired(schema, map[*JSONSchema]*JSONSchema{})
+}
+
+func cloneWithoutRequired(schema *JSONSchema, cloned map[*JSONSchema]*JSONSchema) *JSONSchema {
+	if schema == nil {
+		return nil
+	}
+	if existing, ok := cloned[schema]; ok {
+		return existing
+	}
+
+	clone := new(JSONSchema)
+	*clone = *schema
+	clone.Required = nil
+	cloned[schema] = clone
+
+	if schema.Properties != nil {
+		clone.Properties = make(map[string]*JSONSchema, len(schema.Properties))
+		for k, v := range schema.Properties {
+			clone.Properties[k] = cloneWithoutRequired(v, cloned)
+		}
+	}
+	if schema.PatternProperties != nil {
+		clone.PatternProperties = make(map[string]*JSONSchema, len(schema.PatternProperties))
+		for k, v := range schema.PatternProperties {
+			clone.PatternProperties[k] = cloneWithoutRequired(v, cloned)
+		}
+	}
+	if schema.Definitions != nil {
+		clone.Definitions = make(map[string]*JSONSchema, len(schema.Definitions))
+		for k, v := range schema.Definitions {
+			clone.Definitions[k] = cloneWithoutRequired(v, cloned)
+		}
+	}
+	clone.Items = cloneWithoutRequired(schema.Items, cloned)
+	clone.PrefixItems = cloneSchemasWithoutRequired(schema.PrefixItems, cloned)
+	if sub, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+		clone.AdditionalProperties = cloneWithoutRequired(sub, cloned)
+	}
+	clone.AllOf = cloneSchemasWithoutRequired(schema.AllOf, cloned)
+	clone.AnyOf = cloneSchemasWithoutRequired(schema.AnyOf, cloned)
+	clone.OneOf = cloneSchemasWithoutRequired(schema.OneOf, cloned)
+	clone.Not = cloneWithoutRequired(schema.Not, cloned)
+	return clone
+}
+
+func cloneSchemasWithoutRequired(schemas []*JSONSchema, cloned map[*JSONSchema]*JSONSchema) []*JSONSchema {
+	if schemas == nil {
+		return nil
+	}
+	out := make([]*JSONSchema, len(schemas))
+	for i, s := range schemas {
+		out[i] = cloneWithoutRequired(s, cloned)
+	}
+	return out
+}
+
+// isMultipleOf reports whether num / divisor is an integer, computed over
+// math/big.Rat - parsed from each value's decimal string form rather than
+// SetFloat64, which would capture the IEEE-754 binary value's own rounding
+// error - so e.g. 0.3 isn't incorrectly flagged as not a multiple of 0.1.
+func isMultipleOf(num, divisor float64) bool {
+	numRat, ok := new(big.Rat).SetString(strconv.FormatFloat(num, 'f', -1, 64))
+	if !ok {
+		return true
+	}
+	divisorRat, ok := new(big.Rat).SetString(strconv.FormatFloat(divisor, 'f', -1, 64))
+	if !ok || divisorRat.Sign() == 0 {
+		return true
+	}
+	quotient := new(big.Rat).Quo(numRat, divisorRat)
+	return quotient.IsInt()
+}
+
+func walkArray(path string, value interface{}, schema *JSONSchema, root *JSONSchema, result *ValidationResult, seen map[string]bool) {
+	arr, ok := value.([]interface{})
+	if !ok {
+		result.add(pointerOrRoot(path), "type", "array", fmt.Sprintf("%T", value), fmt.Sprintf("expected array, got %T", value))
+		return
+	}
+
+	if schema.MinItems != nil && len(arr) < *schema.MinItems {
+		result.add(pointerOrRoot(path), "minItems", fmt.Sprintf("%d", *schema.MinItems), fmt.Sprintf("%d", len(arr)), fmt.Sprintf("array length %d is less than minItems %d", len(arr), *schema.MinItems))
+	}
+	if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+		result.add(pointerOrRoot(path), "maxItems", fmt.Sprintf("%d", *schema.MaxItems), fmt.Sprintf("%d", len(arr)), fmt.Sprintf("array length %d is greater than maxItems %d", len(arr), *schema.MaxItems))
+	}
+	if schema.UniqueItems && hasDuplicates(arr) {
+		result.add(pointerOrRoot(path), "uniqueItems", "unique values", "duplicate values", "array items must be unique")
+	}
+	for i, item := range arr {
+		switch {
+		case i < len(schema.PrefixItems):
+			walkSchemaSeen(indexPath(path, i), item, schema.PrefixItems[i], root, result, seen)
+		case schema.Items != nil:
+			walkSchemaSeen(indexPath(path, i), item, schema.Items, root, result, seen)
+		}
+	}
+}
+
+func walkObject(path string, value interface{}, schema *JSONSchema, root *JSONSchema, result *ValidationResult, seen map[string]bool) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		result.add(pointerOrRoot(path), "type", "object", fmt.Sprintf("%T", value), fmt.Sprintf("expected object, got %T", value))
+		return
+	}
+
+	for _, required := range schema.Required {
+		if _, exists := obj[required]; !exists {
+			result.add(childPath(path, required), "required", required, "missing", fmt.Sprintf("missing required field: %s", required))
+		}
+	}
+
+	if schema.MinProperties != nil && len(obj) < *schema.MinProperties {
+		result.add(pointerOrRoot(path), "minProperties", fmt.Sprintf("%d", *schema.MinProperties), fmt.Sprintf("%d", len(obj)), fmt.Sprintf("object has %d properties, want at least %d", len(obj), *schema.MinProperties))
+	}
+	if schema.MaxProperties != nil && len(obj) > *schema.MaxProperties {
+		result.add(pointerOrRoot(path), "maxProperties", fmt.Sprintf("%d", *schema.MaxProperties), fmt.Sprintf("%d", len(obj)), fmt.Sprintf("object has %d properties, want at most %d", len(obj), *schema.MaxProperties))
+	}
+
+	for trigger, dependents := range schema.DependentRequired {
+		if _, present := obj[trigger]; !present {
+			continue
+		}
+		for _, dependent := range dependents {
+			if _, exists := obj[dependent]; !exists {
+				result.add(pointerOrRoot(path), "dependentRequired", dependent, "missing", fmt.Sprintf("property %q requires %q, which is missing", trigger, dependent))
+			}
+		}
+	}
+
+	for key, fieldSchema := range schema.Properties {
+		fieldValue, exists := obj[key]
+		if !exists {
+			continue
+		}
+		walkSchemaSeen(childPath(path, key), fieldValue, fieldSchema, root, result, seen)
+	}
+
+	patternSchemas := compilePatternProperties(schema.PatternProperties)
+
+	for key, propValue := range obj {
+		if _, known := schema.Properties[key]; known {
+			continue
+		}
+
+		matchedPattern := false
+		for _, ps := range patternSchemas {
+			if ps.re.MatchString(key) {
+				matchedPattern = true
+				walkSchemaSeen(childPath(path, key), propValue, ps.schema, root, result, seen)
+			}
+		}
+		if matchedPattern {
+			continue
+		}
+
+		switch additional := schema.AdditionalProperties.(type) {
+		case bool:
+			if !additional && len(schema.Properties) > 0 {
+				result.add(pointerOrRoot(path), "additionalProperties", "no additional properties", key, fmt.Sprintf("additional property %q is not allowed", key))
+			}
+		case nil:
+			if len(schema.Properties) > 0 {
+				// AdditionalProperties wasn't explicitly set: still surface
+				// the unrecognized key (as "unknownProperty" rather than
+				// "additionalProperties") so buildValidationFeedback can
+				// offer a "did you mean" hint against the schema's known
+				// property names.
+				result.add(pointerOrRoot(path), "unknownProperty", "no additional properties", key, fmt.Sprintf("unknown property %q is not part of the schema", key))
+			}
+		default:
+			if sub := additionalPropertiesSchema(additional); sub != nil {
+				walkSchemaSeen(childPath(path, key), propValue, sub, root, result, seen)
+			}
+		}
+	}
+}
+
+// patternPropertySchema pairs a patternProperties key's compiled regexp with
+// its schema.
+type patternPropertySchema struct {
+	re     *regexp.Regexp
+	schema *JSONSchema
+}
+
+// compilePatternProperties compiles every patternProperties key, silently
+// skipping any that don't compile (mirroring walkString's Pattern handling).
+func compilePatternProperties(patternProperties map[string]*JSONSchema) []patternPropertySchema {
+	if len(patternProperties) == 0 {
+		return nil
+	}
+	compiled := make([]patternPropertySchema, 0, len(patternProperties))
+	for pattern, sub := range patternProperties {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		compiled = append(compiled, patternPropertySchema{re: re, schema: sub})
+	}
+	return compiled
+}
+
+// additionalPropertiesSchema converts the interface{} stored in
+// JSONSchema.AdditionalProperties (either a *JSONSchema set directly by Go
+// code, or a map[string]interface{} decoded from JSON) into a *JSONSchema.
+func additionalPropertiesSchema(value interface{}) *JSONSchema {
+	switch v := value.(type) {
+	case *JSONSchema:
+		return v
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var schema JSONSchema
+		if err := json.Unmarshal(encoded, &schema); err != nil {
+			return nil
+		}
+		return &schema
+	default:
+		return nil
+	}
+}
+
+// toFloat64 extracts a numeric value from the JSON-decoded types that can
+// appear in a parsed document (float64 from encoding/json, plus the other
+// Go numeric kinds callers may construct schemas' example data with).
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func hasDuplicates(arr []interface{}) bool {
+	seen := make(map[string]bool, len(arr))
+	for _, item := range arr {
+		key := fmt.Sprintf("%v", item)
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+	}
+	return false
+}
+
+func enumContains(enum []string, value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return true // enum here is only defined over strings; don't fail non-string values
+	}
+	for _, v := range enum {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// pointerOrRoot returns path as a JSON pointer, or "/" for the document root.
+func pointerOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// childPath appends an object key to a JSON pointer, escaping "~" and "/"
+// per RFC 6901.
+func childPath(path, key string) string {
+	return path + "/" + jsonPointerEscape(key)
+}
+
+// parentPointer strips the last token off a JSON pointer produced by
+// childPath/indexPath, returning the pointer of its containing object/array.
+// Returns "" (the document root) if path has no "/".
+func parentPointer(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// indexPath appends an array index to a JSON pointer.
+func indexPath(path string, index int) string {
+	return fmt.Sprintf("%s/%d", path, index)
+}
+
+func jsonPointerEscape(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return token
+}
+
+func jsonPointerUnescape(token string) string {
+	token = strings.ReplaceAll(token, "~1", "/")
+	token = strings.ReplaceAll(token, "~0", "~")
+	return token
+}
+
+// schemaAtPath walks a JSON pointer (as produced by childPath/indexPath)
+// from root down to the schema describing the value at that location,
+// resolving $ref along the way. It's used by buildValidationFeedback to find
+// the sibling property names at a "required"/"unknownProperty" error's path,
+// for computing "did you mean" suggestions. Returns nil if the path doesn't
+// resolve (e.g. it crosses a schema-less map or an unresolvable $ref).
+func schemaAtPath(root *JSONSchema, path string) *JSONSchema {
+	schema := root
+	if path == "" || path == "/" {
+		return schema
+	}
+
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if schema == nil {
+			return nil
+		}
+		if schema.Ref != "" {
+			schema = resolveRef(schema.Ref, root)
+			if schema == nil {
+				return nil
+			}
+		}
+		if _, err := strconv.Atoi(segment); err == nil {
+			schema = schema.Items
+			continue
+		}
+		next, ok := schema.Properties[jsonPointerUnescape(segment)]
+		if !ok {
+			return nil
+		}
+		schema = next
+	}
+	if schema != nil && schema.Ref != "" {
+		schema = resolveRef(schema.Ref, root)
+	}
+	return schema
+}
@@ -0,0 +1,618 @@
+package rlm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWalkSchema_StringConstraints(t *testing.T) {
+	minLen, maxLen := 2, 5
+	schema := &JSONSchema{Type: "string", MinLength: &minLen, MaxLength: &maxLen, Pattern: "^[a-z]+$"}
+
+	tests := []struct {
+		value   interface{}
+		wantErr bool
+	}{
+		{"abc", false},
+		{"a", true},      // below minLength
+		{"abcdef", true}, // above maxLength
+		{"ABC", true},    // fails pattern
+		{42, true},       // wrong type
+	}
+
+	for _, tt := range tests {
+		err := validateValue(tt.value, schema)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateValue(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWalkSchema_NumberConstraints(t *testing.T) {
+	min, max, multipleOf := 0.0, 100.0, 5.0
+	schema := &JSONSchema{Type: "number", Minimum: &min, Maximum: &max, MultipleOf: &multipleOf}
+
+	tests := []struct {
+		value   interface{}
+		wantErr bool
+	}{
+		{float64(50), false},
+		{float64(-1), true},  // below minimum
+		{float64(101), true}, // above maximum
+		{float64(12), true},  // not a multiple of 5
+	}
+
+	for _, tt := range tests {
+		err := validateValue(tt.value, schema)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateValue(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWalkSchema_ExclusiveBounds(t *testing.T) {
+	exclMin, exclMax := 0.0, 100.0
+	schema := &JSONSchema{Type: "number", ExclusiveMinimum: &exclMin, ExclusiveMaximum: &exclMax}
+
+	tests := []struct {
+		value   interface{}
+		wantErr bool
+	}{
+		{float64(50), false},
+		{float64(0), true},   // equal to exclusiveMinimum
+		{float64(100), true}, // equal to exclusiveMaximum
+		{float64(-1), true},
+		{float64(101), true},
+	}
+
+	for _, tt := range tests {
+		err := validateValue(tt.value, schema)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateValue(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWalkSchema_Const(t *testing.T) {
+	schema := &JSONSchema{Const: "payment"}
+
+	if err := validateValue("payment", schema); err != nil {
+		t.Errorf("expected value matching const to pass: %v", err)
+	}
+	if err := validateValue("refund", schema); err == nil {
+		t.Error("expected value not matching const to fail")
+	}
+}
+
+func TestWalkSchema_Enum(t *testing.T) {
+	schema := &JSONSchema{Type: "string", Enum: []string{"red", "green", "blue"}}
+
+	if err := validateValue("green", schema); err != nil {
+		t.Errorf("expected 'green' to satisfy enum: %v", err)
+	}
+	if err := validateValue("purple", schema); err == nil {
+		t.Error("expected 'purple' to violate enum")
+	}
+}
+
+func TestWalkSchema_ArrayConstraints(t *testing.T) {
+	minItems, maxItems := 1, 3
+	schema := &JSONSchema{Type: "array", MinItems: &minItems, MaxItems: &maxItems, UniqueItems: true, Items: &JSONSchema{Type: "string"}}
+
+	if err := validateValue([]interface{}{"a", "b"}, schema); err != nil {
+		t.Errorf("expected valid array to pass: %v", err)
+	}
+	if err := validateValue([]interface{}{}, schema); err == nil {
+		t.Error("expected empty array to violate minItems")
+	}
+	if err := validateValue([]interface{}{"a", "b", "c", "d"}, schema); err == nil {
+		t.Error("expected 4-item array to violate maxItems")
+	}
+	if err := validateValue([]interface{}{"a", "a"}, schema); err == nil {
+		t.Error("expected duplicate items to violate uniqueItems")
+	}
+}
+
+func TestWalkSchema_AdditionalPropertiesFalse(t *testing.T) {
+	additionalFalse := false
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{"name": {Type: "string"}},
+		AdditionalProperties: additionalFalse,
+	}
+
+	if err := validateValue(map[string]interface{}{"name": "Alice"}, schema); err != nil {
+		t.Errorf("expected known property to pass: %v", err)
+	}
+	if err := validateValue(map[string]interface{}{"name": "Alice", "extra": 1}, schema); err == nil {
+		t.Error("expected unknown property to violate additionalProperties: false")
+	}
+}
+
+func TestWalkSchema_UnknownPropertyWithoutExplicitAdditionalProperties(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"sentiment_score": {Type: "number"}},
+	}
+
+	if err := validateValue(map[string]interface{}{"sentiment_score": float64(1)}, schema); err != nil {
+		t.Errorf("expected known property to pass: %v", err)
+	}
+
+	err := validateValue(map[string]interface{}{"sentimentScore": float64(1)}, schema)
+	if err == nil {
+		t.Fatal("expected an unrecognized property to be surfaced even without AdditionalProperties: false")
+	}
+	result, ok := err.(*ValidationResult)
+	if !ok || len(result.Errors) == 0 || result.Errors[0].Keyword != "unknownProperty" {
+		t.Errorf("expected an \"unknownProperty\" violation, got %v", err)
+	}
+}
+
+func TestWalkSchema_AdditionalPropertiesTrueAllowsUnknownKeys(t *testing.T) {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{"name": {Type: "string"}},
+		AdditionalProperties: true,
+	}
+
+	if err := validateValue(map[string]interface{}{"name": "Alice", "extra": 1}, schema); err != nil {
+		t.Errorf("expected AdditionalProperties: true to allow unknown keys: %v", err)
+	}
+}
+
+func TestWalkSchema_AnyOf(t *testing.T) {
+	schema := &JSONSchema{AnyOf: []*JSONSchema{{Type: "string"}, {Type: "number"}}}
+
+	if err := validateValue("hello", schema); err != nil {
+		t.Errorf("expected string branch to match: %v", err)
+	}
+	if err := validateValue(float64(42), schema); err != nil {
+		t.Errorf("expected number branch to match: %v", err)
+	}
+	if err := validateValue(true, schema); err == nil {
+		t.Error("expected boolean to satisfy neither anyOf branch")
+	}
+}
+
+func TestWalkSchema_OneOf(t *testing.T) {
+	schema := &JSONSchema{OneOf: []*JSONSchema{
+		{Type: "object", Properties: map[string]*JSONSchema{"kind": {Type: "string", Enum: []string{"payment"}}, "amount": {Type: "number"}}, Required: []string{"kind", "amount"}},
+		{Type: "object", Properties: map[string]*JSONSchema{"kind": {Type: "string", Enum: []string{"refund"}}, "reason": {Type: "string"}}, Required: []string{"kind", "reason"}},
+	}}
+
+	payment := map[string]interface{}{"kind": "payment", "amount": float64(10)}
+	if err := validateValue(payment, schema); err != nil {
+		t.Errorf("expected payment branch to match exactly one oneOf branch: %v", err)
+	}
+
+	neither := map[string]interface{}{"kind": "other"}
+	if err := validateValue(neither, schema); err == nil {
+		t.Error("expected a shape matching no branch to fail")
+	}
+}
+
+func TestWalkSchema_Not(t *testing.T) {
+	schema := &JSONSchema{Not: &JSONSchema{Type: "string"}}
+
+	if err := validateValue(float64(42), schema); err != nil {
+		t.Errorf("expected non-string to satisfy not: %v", err)
+	}
+	if err := validateValue("blocked", schema); err == nil {
+		t.Error("expected string to violate not")
+	}
+}
+
+func TestWalkSchema_RefResolvesAgainstRootDefinitions(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"address": {Ref: "#/definitions/Address"}},
+		Required:   []string{"address"},
+		Definitions: map[string]*JSONSchema{
+			"Address": {Type: "object", Properties: map[string]*JSONSchema{"zip": {Type: "string"}}, Required: []string{"zip"}},
+		},
+	}
+
+	valid := map[string]interface{}{"address": map[string]interface{}{"zip": "12345"}}
+	if err := validateAgainstSchema(valid, schema); err != nil {
+		t.Errorf("expected $ref'd schema to validate: %v", err)
+	}
+
+	invalid := map[string]interface{}{"address": map[string]interface{}{}}
+	if err := validateAgainstSchema(invalid, schema); err == nil {
+		t.Error("expected missing 'zip' to fail through the resolved $ref")
+	}
+}
+
+func TestWalkSchema_CollectsMultipleErrors(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "number"}},
+		Required:   []string{"name", "age"},
+	}
+
+	err := validateAgainstSchema(map[string]interface{}{}, schema)
+	result, ok := err.(*ValidationResult)
+	if !ok {
+		t.Fatalf("expected *ValidationResult, got %T", err)
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("expected 2 violations (both missing fields), got %d: %v", len(result.Errors), result.Errors)
+	}
+}
+
+func TestWalkSchema_ErrorPathsUseJSONPointer(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"items": {Type: "array", Items: &JSONSchema{
+				Type:       "object",
+				Properties: map[string]*JSONSchema{"zip": {Type: "string"}},
+				Required:   []string{"zip"},
+			}},
+		},
+	}
+
+	data := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"zip": "12345"},
+			map[string]interface{}{},
+		},
+	}
+
+	err := validateAgainstSchema(data, schema)
+	result, ok := err.(*ValidationResult)
+	if !ok {
+		t.Fatalf("expected *ValidationResult, got %T", err)
+	}
+	// A missing required field is reported at its own pointer
+	// (/items/1/zip), not its containing object's (/items/1).
+	if len(result.Errors) != 1 || result.Errors[0].Path != "/items/1/zip" {
+		t.Errorf("expected a single error at /items/1/zip, got %v", result.Errors)
+	}
+}
+
+func TestWalkSchema_Format(t *testing.T) {
+	schema := &JSONSchema{Type: "string", Format: "email"}
+
+	if err := validateValue("user@example.com", schema); err != nil {
+		t.Errorf("expected valid email to pass: %v", err)
+	}
+	if err := validateValue("not-an-email", schema); err == nil {
+		t.Error("expected invalid email to fail")
+	}
+}
+
+func TestWalkSchema_DurationFormat(t *testing.T) {
+	schema := &JSONSchema{Type: "string", Format: "duration"}
+
+	for _, valid := range []string{"P3Y6M4DT12H30M5S", "P2W", "PT1H", "P1D"} {
+		if err := validateValue(valid, schema); err != nil {
+			t.Errorf("expected %q to satisfy duration format: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"P", "PT", "1Y2M", "not-a-duration"} {
+		if err := validateValue(invalid, schema); err == nil {
+			t.Errorf("expected %q to violate duration format", invalid)
+		}
+	}
+}
+
+func TestWalkSchema_IPv4Format(t *testing.T) {
+	schema := &JSONSchema{Type: "string", Format: "ipv4"}
+
+	for _, valid := range []string{"127.0.0.1", "0.0.0.0", "255.255.255.255"} {
+		if err := validateValue(valid, schema); err != nil {
+			t.Errorf("expected %q to satisfy ipv4 format: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"not-an-ip", "256.1.1.1", "::1"} {
+		if err := validateValue(invalid, schema); err == nil {
+			t.Errorf("expected %q to violate ipv4 format", invalid)
+		}
+	}
+}
+
+func TestWalkSchema_IPv6Format(t *testing.T) {
+	schema := &JSONSchema{Type: "string", Format: "ipv6"}
+
+	for _, valid := range []string{"::1", "2001:db8::ff00:42:8329", "fe80::1"} {
+		if err := validateValue(valid, schema); err != nil {
+			t.Errorf("expected %q to satisfy ipv6 format: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"not-an-ip", "127.0.0.1"} {
+		if err := validateValue(invalid, schema); err == nil {
+			t.Errorf("expected %q to violate ipv6 format", invalid)
+		}
+	}
+}
+
+func TestWalkSchema_HostnameFormat(t *testing.T) {
+	schema := &JSONSchema{Type: "string", Format: "hostname"}
+
+	for _, valid := range []string{"example.com", "sub.example.co.uk", "localhost", "a-b.c"} {
+		if err := validateValue(valid, schema); err != nil {
+			t.Errorf("expected %q to satisfy hostname format: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "-leading-hyphen.com", "trailing-.com", "has_underscore.com"} {
+		if err := validateValue(invalid, schema); err == nil {
+			t.Errorf("expected %q to violate hostname format", invalid)
+		}
+	}
+}
+
+func TestRegisterFormatChecker(t *testing.T) {
+	RegisterFormatChecker("even-digits", func(value interface{}) bool {
+		s, ok := value.(string)
+		return ok && len(s)%2 == 0
+	})
+
+	schema := &JSONSchema{Type: "string", Format: "even-digits"}
+	if err := validateValue("1234", schema); err != nil {
+		t.Errorf("expected 'even-digits' checker to pass on even length: %v", err)
+	}
+	if err := validateValue("123", schema); err == nil {
+		t.Error("expected 'even-digits' checker to fail on odd length")
+	}
+}
+
+func TestWalkSchema_MultipleOfAvoidsFloatRoundingFalsePositives(t *testing.T) {
+	multipleOf := 0.1
+	schema := &JSONSchema{Type: "number", MultipleOf: &multipleOf}
+
+	// 0.3 / 0.1 == 2.9999999999999996 in binary floating point, which a naive
+	// float comparison would incorrectly reject.
+	if err := validateValue(0.3, schema); err != nil {
+		t.Errorf("expected 0.3 to be a multiple of 0.1: %v", err)
+	}
+	if err := validateValue(0.35, schema); err == nil {
+		t.Error("expected 0.35 to violate multipleOf 0.1")
+	}
+}
+
+func TestWalkSchema_MinMaxProperties(t *testing.T) {
+	minProps, maxProps := 1, 2
+	schema := &JSONSchema{Type: "object", MinProperties: &minProps, MaxProperties: &maxProps}
+
+	if err := validateValue(map[string]interface{}{"a": 1}, schema); err != nil {
+		t.Errorf("expected 1 property to satisfy minProperties/maxProperties: %v", err)
+	}
+	if err := validateValue(map[string]interface{}{}, schema); err == nil {
+		t.Error("expected an empty object to violate minProperties")
+	}
+	if err := validateValue(map[string]interface{}{"a": 1, "b": 2, "c": 3}, schema); err == nil {
+		t.Error("expected 3 properties to violate maxProperties")
+	}
+}
+
+func TestWalkSchema_PatternProperties(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		PatternProperties: map[string]*JSONSchema{
+			"^S_": {Type: "string"},
+			"^I_": {Type: "number"},
+		},
+	}
+
+	valid := map[string]interface{}{"S_name": "Alice", "I_age": float64(30)}
+	if err := validateValue(valid, schema); err != nil {
+		t.Errorf("expected values matching their pattern's type to pass: %v", err)
+	}
+
+	invalid := map[string]interface{}{"S_name": 42}
+	if err := validateValue(invalid, schema); err == nil {
+		t.Error("expected a patternProperties type mismatch to fail")
+	}
+}
+
+func TestWalkSchema_AdditionalPropertiesAsSchema(t *testing.T) {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{"name": {Type: "string"}},
+		AdditionalProperties: &JSONSchema{Type: "number"},
+	}
+
+	if err := validateValue(map[string]interface{}{"name": "Alice", "score": float64(5)}, schema); err != nil {
+		t.Errorf("expected an extra property matching the additionalProperties schema to pass: %v", err)
+	}
+	if err := validateValue(map[string]interface{}{"name": "Alice", "score": "not a number"}, schema); err == nil {
+		t.Error("expected an extra property violating the additionalProperties schema to fail")
+	}
+}
+
+func TestWalkSchema_RefCycleDetected(t *testing.T) {
+	schema := &JSONSchema{
+		Ref: "#/definitions/Node",
+		Definitions: map[string]*JSONSchema{
+			"Node": {
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"child": {Ref: "#/definitions/Node"},
+				},
+			},
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- validateValue(map[string]interface{}{"child": map[string]interface{}{"child": map[string]interface{}{}}}, schema)
+	}()
+
+	select {
+	case <-done:
+		// A cyclic $ref chain that bottoms out in concrete data (as this one
+		// does) should terminate rather than hang or stack-overflow.
+	case <-time.After(2 * time.Second):
+		t.Fatal("validateValue() did not terminate on a cyclic $ref schema")
+	}
+}
+
+func TestValidatePartial_SkipsMissingRequiredFields(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "number"}},
+		Required:   []string{"name", "age"},
+	}
+
+	if err := ValidatePartial(map[string]interface{}{"name": "Alice"}, schema); err != nil {
+		t.Errorf("ValidatePartial() = %v, want nil even though \"age\" hasn't streamed in yet", err)
+	}
+}
+
+func TestValidatePartial_StillChecksPresentFields(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"age": {Type: "number"}},
+		Required:   []string{"age"},
+	}
+
+	err := ValidatePartial(map[string]interface{}{"age": "not a number"}, schema)
+	if err == nil {
+		t.Fatal("ValidatePartial() = nil, want an error for a present field with the wrong type")
+	}
+}
+
+func TestValidatePartial_DoesNotMutateTheOriginalSchema(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+
+	_ = ValidatePartial(map[string]interface{}{}, schema)
+	if len(schema.Required) != 1 || schema.Required[0] != "name" {
+		t.Errorf("schema.Required = %v, want unchanged [name]", schema.Required)
+	}
+
+	// The ordinary (non-partial) validator must still enforce "required".
+	if err := validateAgainstSchema(map[string]interface{}{}, schema); err == nil {
+		t.Error("validateAgainstSchema() = nil, want an error for a missing required field after ValidatePartial ran")
+	}
+}
+
+func TestBuildValidationFeedback_StructuredResult(t *testing.T) {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"name": {Type: "string"}, "age": {Type: "number"}},
+		Required:   []string{"name", "age"},
+	}
+
+	err := validateAgainstSchema(map[string]interface{}{"name": "Alice"}, schema)
+	feedback := buildValidationFeedback(err, schema, `{"name": "Alice"}`)
+
+	if !strings.Contains(feedback, "/age") {
+		t.Errorf("expected feedback to reference the /age path, got: %s", feedback)
+	}
+	if !strings.Contains(feedback, "required") {
+		t.Errorf("expected feedback to mention the required keyword, got: %s", feedback)
+	}
+}
+
+func TestWalkSchema_PrefixItemsValidatesTuplePositionally(t *testing.T) {
+	schema := &JSONSchema{
+		Type:        "array",
+		PrefixItems: []*JSONSchema{{Type: "string"}, {Type: "number"}},
+		Items:       &JSONSchema{Type: "boolean"},
+	}
+
+	if err := validateValue([]interface{}{"Alice", float64(30), true, false}, schema); err != nil {
+		t.Errorf("expected a conformant tuple plus trailing booleans to pass: %v", err)
+	}
+	if err := validateValue([]interface{}{float64(30), "Alice"}, schema); err == nil {
+		t.Error("expected a tuple with swapped positional types to fail")
+	}
+	if err := validateValue([]interface{}{"Alice", float64(30), "not a bool"}, schema); err == nil {
+		t.Error("expected a trailing element violating Items to fail")
+	}
+}
+
+func TestWalkSchema_DependentRequired(t *testing.T) {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"creditCard":     {Type: "string"},
+			"billingAddress": {Type: "string"},
+		},
+		DependentRequired: map[string][]string{"creditCard": {"billingAddress"}},
+	}
+
+	if err := validateValue(map[string]interface{}{}, schema); err != nil {
+		t.Errorf("expected no dependentRequired violation when the trigger property is absent: %v", err)
+	}
+	if err := validateValue(map[string]interface{}{"creditCard": "4111"}, schema); err == nil {
+		t.Error("expected a dependentRequired violation when billingAddress is missing")
+	}
+	if err := validateValue(map[string]interface{}{"creditCard": "4111", "billingAddress": "221B Baker St"}, schema); err != nil {
+		t.Errorf("expected no violation once the dependent property is present: %v", err)
+	}
+}
+
+func TestSchemaRegistry_RegisterAndResolveRef(t *testing.T) {
+	registry := NewSchemaRegistry()
+	registry.Register("Address", &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{"zip": {Type: "string"}},
+		Required:   []string{"zip"},
+	})
+
+	if _, ok := registry.Get("Missing"); ok {
+		t.Error("Get() found a schema that was never registered")
+	}
+
+	old := DefaultSchemaRegistry
+	DefaultSchemaRegistry = registry
+	defer func() { DefaultSchemaRegistry = old }()
+
+	schema := &JSONSchema{Ref: "registry:Address"}
+	if err := validateValue(map[string]interface{}{"zip": "02101"}, schema); err != nil {
+		t.Errorf("expected a registry-resolved $ref to validate: %v", err)
+	}
+	if err := validateValue(map[string]interface{}{}, schema); err == nil {
+		t.Error("expected a registry-resolved $ref to still enforce its own required fields")
+	}
+}
+
+// deeplyNestedObject builds a schema/value pair nested depth levels deep
+// under the key "a", e.g. depth=3 produces {"a": {"a": {"a": "leaf"}}}
+// against a matching {type: object, properties: {a: {...}}} schema.
+func deeplyNestedObject(depth int) (*JSONSchema, map[string]interface{}) {
+	schema := &JSONSchema{Type: "string"}
+	var value interface{} = "leaf"
+	for i := 0; i < depth; i++ {
+		schema = &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{"a": schema}}
+		value = map[string]interface{}{"a": value}
+	}
+	return schema, value.(map[string]interface{})
+}
+
+func TestWalkSchema_MaxNestedDepthExceeded(t *testing.T) {
+	old := MaxNestedValidationDepth
+	MaxNestedValidationDepth = 32
+	defer func() { MaxNestedValidationDepth = old }()
+
+	schema, data := deeplyNestedObject(10000)
+
+	err := validateAgainstSchema(data, schema)
+	if err == nil {
+		t.Fatal("expected an error for a 10000-level-deep document")
+	}
+	var depthErr *MaxNestedDepthError
+	if !errors.As(err, &depthErr) {
+		t.Fatalf("validateAgainstSchema() error = %T, want *MaxNestedDepthError", err)
+	}
+	if depthErr.MaxDepth != 32 {
+		t.Errorf("depthErr.MaxDepth = %d, want 32", depthErr.MaxDepth)
+	}
+}
+
+func TestWalkSchema_WithinMaxNestedDepthStillValidates(t *testing.T) {
+	schema, data := deeplyNestedObject(5)
+
+	if err := validateAgainstSchema(data, schema); err != nil {
+		t.Errorf("expected a shallow document within MaxNestedValidationDepth to validate: %v", err)
+	}
+}